@@ -0,0 +1,92 @@
+package datatable
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ApplyBatches calls fn with successive batches of at most size rows in the
+// table's current order, such as when writing the table to a database one page
+// at a time. Iteration stops at the first error returned by fn, which is
+// returned.
+func (dt *DataTable) ApplyBatches(size int, fn func(rg RowGroup) error) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid batch size: %d", size)
+	}
+	for start := 0; start < dt.Len(); start += size {
+		if err := fn(dt.batch(start, size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyBatchesParallel is like ApplyBatches but calls fn from up to workers
+// goroutines at once, so batches may be processed in any order. fn must not
+// modify the table. Once fn returns an error no further batches are started
+// and, after the batches in progress have finished, the error from the
+// earliest failing batch is returned.
+func (dt *DataTable) ApplyBatchesParallel(size, workers int, fn func(rg RowGroup) error) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid batch size: %d", size)
+	}
+	if workers <= 0 {
+		return fmt.Errorf("invalid number of workers: %d", workers)
+	}
+
+	nbatches := (dt.Len() + size - 1) / size
+	errs := make([]error, nbatches)
+	batches := make(chan int)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				if err := fn(dt.batch(b*size, size)); err != nil {
+					errs[b] = err
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for b := 0; b < nbatches; b++ {
+		mu.Lock()
+		stop := failed
+		mu.Unlock()
+		if stop {
+			break
+		}
+		batches <- b
+	}
+	close(batches)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batch returns a row group containing at most size rows starting at start.
+func (dt *DataTable) batch(start, size int) RowGroup {
+	end := start + size
+	if end > dt.Len() {
+		end = dt.Len()
+	}
+	indices := make([]int, end-start)
+	for i := range indices {
+		indices[i] = start + i
+	}
+	return &StaticRowGroup{dt: dt, indices: indices}
+}
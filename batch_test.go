@@ -0,0 +1,70 @@
+package datatable
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func batchSizes(dt *DataTable, parallel bool, size int, fail int) ([]int, error) {
+	var (
+		mu    sync.Mutex
+		sizes []int
+	)
+	fn := func(rg RowGroup) error {
+		n := 0
+		for rg.Next() {
+			if v, _ := rg.FloatValue("c0"); int(v) == fail {
+				return errors.New("failed")
+			}
+			n++
+		}
+		mu.Lock()
+		sizes = append(sizes, n)
+		mu.Unlock()
+		return nil
+	}
+	var err error
+	if parallel {
+		err = dt.ApplyBatchesParallel(size, 3, fn)
+	} else {
+		err = dt.ApplyBatches(size, fn)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes, err
+}
+
+func TestApplyBatches(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{0, 1, 2, 3, 4, 5, 6})
+
+	for _, parallel := range []bool{false, true} {
+		sizes, err := batchSizes(dt, parallel, 3, -1)
+		if err != nil {
+			t.Fatalf("parallel=%v: unexpected error: %v", parallel, err)
+		}
+		if expected := []int{3, 3, 1}; !reflect.DeepEqual(sizes, expected) {
+			t.Errorf("parallel=%v: got %v, wanted %v", parallel, sizes, expected)
+		}
+
+		if _, err := batchSizes(dt, parallel, 3, 4); err == nil {
+			t.Errorf("parallel=%v: got no error from failing batch", parallel)
+		}
+	}
+
+	if err := dt.ApplyBatches(0, func(rg RowGroup) error { return nil }); err == nil {
+		t.Errorf("got no error for zero batch size")
+	}
+}
+
+func TestApplyBatchesStopsOnError(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{0, 1, 2, 3, 4, 5, 6})
+
+	sizes, _ := batchSizes(dt, false, 2, 2)
+	if expected := []int{2}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("got %v, wanted %v", sizes, expected)
+	}
+}
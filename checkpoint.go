@@ -0,0 +1,203 @@
+package datatable
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A CheckpointedLoader appends the rows of many CSV files to a single output
+// CSV file, recording its progress in a checkpoint file so that a load
+// interrupted by a crash can be resumed without reading any input row twice.
+// The output can be read with ReadCSV once the load is complete.
+type CheckpointedLoader struct {
+	// Output is the path of the CSV file the rows are appended to. Its
+	// columns are those of the first input file.
+	Output string
+
+	// Checkpoint is the path of the file recording progress. The default is
+	// the output path followed by .checkpoint. Remove it, and the output,
+	// to load the files again from the start.
+	Checkpoint string
+
+	// BatchRows is the number of rows appended between checkpoints. The
+	// default is 10000.
+	BatchRows int
+
+	// checkpointed is called after each checkpoint is saved, if set, and a
+	// non-nil error stops the load. Tests use it to simulate a crash.
+	checkpointed func() error
+}
+
+// loadCheckpoint is the progress of a load as saved in the checkpoint file.
+type loadCheckpoint struct {
+	Columns    []string         // the columns of the output
+	OutputSize int64            // the size of the output at the checkpoint
+	Offsets    map[string]int64 // the byte offset reached in partly read inputs
+	Done       map[string]bool  // the inputs that have been read completely
+}
+
+// Load appends the rows of the CSV files at paths that have not already been
+// loaded to the output. Each file must have a header record whose columns
+// are all columns of the output, though they may be in any order and
+// columns missing from a file are written as empty fields. Files are read in
+// order and must not change between an interrupted load and its resumption.
+// Any output written after the last checkpoint of an interrupted load is
+// discarded when it is resumed. Input files are named in the checkpoint by
+// their paths as given.
+func (l *CheckpointedLoader) Load(paths []string) error {
+	cpath := l.Checkpoint
+	if cpath == "" {
+		cpath = l.Output + ".checkpoint"
+	}
+	batch := l.BatchRows
+	if batch <= 0 {
+		batch = 10000
+	}
+
+	cp := loadCheckpoint{Offsets: map[string]int64{}, Done: map[string]bool{}}
+	if data, err := os.ReadFile(cpath); err == nil {
+		if err := json.Unmarshal(data, &cp); err != nil {
+			return fmt.Errorf("reading checkpoint: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	out, err := os.OpenFile(l.Output, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := out.Truncate(cp.OutputSize); err != nil {
+		return err
+	}
+	if _, err := out.Seek(cp.OutputSize, io.SeekStart); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(out)
+	save := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if err := out.Sync(); err != nil {
+			return err
+		}
+		size, err := out.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		cp.OutputSize = size
+		if err := writeCheckpoint(cpath, cp); err != nil {
+			return err
+		}
+		if l.checkpointed != nil {
+			return l.checkpointed()
+		}
+		return nil
+	}
+
+	for _, path := range paths {
+		if cp.Done[path] {
+			continue
+		}
+		if err := l.loadFile(path, &cp, cw, batch, save); err != nil {
+			return fmt.Errorf("loading %s: %w", path, err)
+		}
+		delete(cp.Offsets, path)
+		cp.Done[path] = true
+		if err := save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadFile appends the rows of the CSV file at path from the offset recorded
+// in cp to cw, calling save every batch rows.
+func (l *CheckpointedLoader) loadFile(path string, cp *loadCheckpoint, cw *csv.Writer, batch int, save func() error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	if cp.Columns == nil {
+		cp.Columns = append([]string{}, header...)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	index := make(map[string]int, len(cp.Columns))
+	for i, name := range cp.Columns {
+		index[name] = i
+	}
+	positions := make([]int, len(header))
+	for i, name := range header {
+		pos, exists := index[name]
+		if !exists {
+			return fmt.Errorf("unknown column: %s", name)
+		}
+		positions[i] = pos
+	}
+
+	base := int64(0)
+	if offset, exists := cp.Offsets[path]; exists {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		cr = csv.NewReader(f)
+		base = offset
+	}
+	cr.FieldsPerRecord = len(header)
+
+	row := make([]string, len(cp.Columns))
+	for n := 1; ; n++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i := range row {
+			row[i] = ""
+		}
+		for i, v := range rec {
+			row[positions[i]] = v
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		if n%batch == 0 {
+			cp.Offsets[path] = base + cr.InputOffset()
+			if err := save(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeCheckpoint replaces the checkpoint file at path with cp, writing it to
+// a temporary file first so that a crash cannot leave it partly written.
+func writeCheckpoint(path string, cp loadCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
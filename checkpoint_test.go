@@ -0,0 +1,63 @@
+package datatable
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointedLoader(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.csv")
+	b := filepath.Join(dir, "b.csv")
+	os.WriteFile(a, []byte("k,v\nr1,1\nr2,2\nr3,3\nr4,4\nr5,5\n"), 0o644)
+	os.WriteFile(b, []byte("v,k\n6,r6\n7,r7\n"), 0o644)
+	out := filepath.Join(dir, "out.csv")
+
+	crash := errors.New("crash")
+	saves := 0
+	l := &CheckpointedLoader{Output: out, BatchRows: 2}
+	l.checkpointed = func() error {
+		saves++
+		if saves == 2 {
+			return crash
+		}
+		return nil
+	}
+	if err := l.Load([]string{a, b}); !errors.Is(err, crash) {
+		t.Fatalf("got %v, wanted %v", err, crash)
+	}
+
+	// simulate rows written after the last checkpoint before the crash
+	f, err := os.OpenFile(out, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.WriteString("r5,5\n")
+	f.Close()
+
+	l.checkpointed = nil
+	if err := l.Load([]string{a, b}); err != nil {
+		t.Fatalf("unexpected error resuming: %v", err)
+	}
+	// loading again does nothing since every file is done
+	if err := l.Load([]string{a, b}); err != nil {
+		t.Fatalf("unexpected error reloading: %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "k,v\nr1,1\nr2,2\nr3,3\nr4,4\nr5,5\nr6,6\nr7,7\n"
+	if string(got) != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	c := filepath.Join(dir, "c.csv")
+	os.WriteFile(c, []byte("k,extra\nr8,x\n"), 0o644)
+	if err := l.Load([]string{c}); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
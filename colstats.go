@@ -0,0 +1,141 @@
+package datatable
+
+import (
+	"math"
+)
+
+// zoneSize is the number of consecutive rows summarised by each entry of a
+// column's zone map.
+const zoneSize = 1024
+
+// colStats holds statistics of a numeric column that are cached until the
+// column is next modified.
+type colStats struct {
+	zone
+
+	// zones is the zone map of the column, holding the range of values in
+	// each block of zoneSize rows.
+	zones []zone
+}
+
+// zone holds the range of the values in a block of rows.
+type zone struct {
+	min, max float64
+	ok       bool // false if there are no non-NaN values
+}
+
+func (z *zone) add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	if !z.ok || v < z.min {
+		z.min = v
+	}
+	if !z.ok || v > z.max {
+		z.max = v
+	}
+	z.ok = true
+}
+
+// CanMatch reports whether any row of the table could match m. It returns
+// false only when m provably cannot match any row, using the cached minimum
+// and maximum values of numeric columns. For example GreaterThan("x", 10)
+// cannot match if the largest value of x is 5. Matches and CountWhere use
+// the same statistics, kept for each block of rows, to avoid scanning rows
+// that cannot match. Matchers other than those returned by
+// NumericColumnMatcher, IsZero, GreaterThan, LessThan and CloselyEqual are
+// always assumed to be able to match.
+func (dt *DataTable) CanMatch(m Matcher) bool {
+	rm, ok := m.(rangeMatcher)
+	if !ok {
+		return true
+	}
+	c, exists := dt.colorder[rm.name]
+	if !exists || !dt.isFloatCol(c) {
+		return false
+	}
+	if rm.within == nil {
+		return true
+	}
+	st := dt.colStats(c)
+	if !st.ok {
+		// Only NaN values, which never satisfy a range
+		return dt.Len() == 0
+	}
+	return rm.within(st.min, st.max)
+}
+
+// colStats returns the statistics of numeric column c, calculating them if
+// they are not cached.
+func (dt *DataTable) colStats(c int) colStats {
+	name := dt.colnames[c]
+	if st, exists := dt.stats[name]; exists {
+		return st
+	}
+	var st colStats
+	vals := dt.cols[c].f
+	for start := 0; start < len(vals); start += zoneSize {
+		var z zone
+		for _, v := range vals[start:min(start+zoneSize, len(vals))] {
+			z.add(v)
+		}
+		if z.ok {
+			st.add(z.min)
+			st.add(z.max)
+		}
+		st.zones = append(st.zones, z)
+	}
+	if dt.stats == nil {
+		dt.stats = map[string]colStats{}
+	}
+	dt.stats[name] = st
+	return st
+}
+
+// candidateRanges returns the ranges of rows, as start and end positions,
+// that could contain rows matching m. Using the zone map of the column tested
+// by m, blocks of rows whose range of values cannot satisfy m are skipped,
+// which avoids scanning most of a table ordered by that column, such as a
+// time-ordered table that is appended to.
+func (dt *DataTable) candidateRanges(m Matcher) [][2]int {
+	all := [][2]int{{0, dt.Len()}}
+	rm, ok := m.(rangeMatcher)
+	if !ok || rm.within == nil {
+		return all
+	}
+	c, exists := dt.colorder[rm.name]
+	if !exists || !dt.isFloatCol(c) {
+		return all
+	}
+
+	var ranges [][2]int
+	for i, z := range dt.colStats(c).zones {
+		if !z.ok || !rm.within(z.min, z.max) {
+			continue
+		}
+		start, end := i*zoneSize, min((i+1)*zoneSize, dt.Len())
+		if n := len(ranges); n > 0 && ranges[n-1][1] == start {
+			ranges[n-1][1] = end
+			continue
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// rangeMatcher is a Matcher that tests the value of a single numeric column.
+type rangeMatcher struct {
+	name string
+	fn   func(float64) bool
+
+	// within reports whether a value between min and max inclusive could
+	// satisfy fn. It is nil if that cannot be determined.
+	within func(min, max float64) bool
+}
+
+func (m rangeMatcher) Match(row RowRef) bool {
+	if v, exists := row.FloatValue(m.name); exists {
+		return m.fn(v)
+	}
+	return false
+}
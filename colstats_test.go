@@ -0,0 +1,131 @@
+package datatable
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestCanMatch(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("x", []float64{1, 5, math.NaN(), 3})
+	dt.AddColumn("n", []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN()})
+	dt.AddStringColumn("s", []string{"a", "b", "c", "d"})
+
+	testCases := []struct {
+		name string
+		m    Matcher
+		want bool
+	}{
+		{"greater than max", GreaterThan("x", 5), false},
+		{"greater than below max", GreaterThan("x", 4), true},
+		{"less than min", LessThan("x", 1), false},
+		{"less than above min", LessThan("x", 1.5), true},
+		{"closely equal outside", CloselyEqual("x", 7, 1.5), false},
+		{"closely equal inside", CloselyEqual("x", 7, 2), true},
+		{"zero outside", IsZero("x"), false},
+		{"only nan values", GreaterThan("n", 0), false},
+		{"nan matcher", IsNan("n"), true},
+		{"string column", GreaterThan("s", 0), false},
+		{"unknown column", GreaterThan("z", 0), false},
+		{"negation", Not(GreaterThan("x", 5)), true},
+		{"string matcher", IsEqualString("s", "z"), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := dt.CanMatch(tc.m); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+			if !tc.want && dt.CountWhere(tc.m) != 0 {
+				t.Errorf("CanMatch false but rows match")
+			}
+		})
+	}
+}
+
+func TestCanMatchAfterModification(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("x", []float64{1, 5, 3})
+
+	if dt.CanMatch(GreaterThan("x", 10)) {
+		t.Fatalf("expected no match before modification")
+	}
+
+	dt.SetFloatValue("x", 1, 20)
+	if got := dt.Matches(GreaterThan("x", 10)); len(got) != 1 || got[0] != 1 {
+		t.Errorf("got matches %v after SetFloatValue, wanted [1]", got)
+	}
+
+	dt.AppendRow([]interface{}{30.0})
+	if got := dt.CountWhere(GreaterThan("x", 25)); got != 1 {
+		t.Errorf("got count %d after AppendRow, wanted 1", got)
+	}
+
+	dt.AddColumn("x", []float64{0, 0, 0, 0})
+	if dt.CanMatch(GreaterThan("x", 10)) {
+		t.Errorf("expected no match after replacing column")
+	}
+}
+
+func TestZoneMaps(t *testing.T) {
+	n := 5*zoneSize + 10
+	ts := make([]float64, n)
+	for i := range ts {
+		ts[i] = float64(i)
+	}
+	ts[2*zoneSize+5] = math.NaN()
+
+	dt := &DataTable{}
+	dt.AddColumn("ts", ts)
+
+	ranges := dt.candidateRanges(GreaterThan("ts", float64(3*zoneSize+1)))
+	if len(ranges) != 1 || ranges[0] != [2]int{3 * zoneSize, n} {
+		t.Errorf("got ranges %v, wanted [[%d %d]]", ranges, 3*zoneSize, n)
+	}
+
+	ranges = dt.candidateRanges(CloselyEqual("ts", float64(zoneSize), 1))
+	if len(ranges) != 1 || ranges[0] != [2]int{0, 2 * zoneSize} {
+		t.Errorf("got ranges %v, wanted [[0 %d]]", ranges, 2*zoneSize)
+	}
+
+	if got := dt.CountWhere(LessThan("ts", 10)); got != 10 {
+		t.Errorf("got count %d, wanted 10", got)
+	}
+	if got := dt.Matches(GreaterThan("ts", float64(n-3))); len(got) != 2 || got[0] != n-2 {
+		t.Errorf("got matches %v, wanted [%d %d]", got, n-2, n-1)
+	}
+
+	// Reordering rows invalidates the zone map
+	dt.SetKeys("ts")
+	dt.Unique()
+	if got := dt.CountWhere(GreaterThan("ts", float64(n-3))); got != 2 {
+		t.Errorf("got count %d after sorting, wanted 2", got)
+	}
+}
+
+func TestZoneMapsAfterSwap(t *testing.T) {
+	n := 3000
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	dt := &DataTable{}
+	dt.AddColumn("x", xs)
+	if got := dt.CountWhere(GreaterThan("x", 2990)); got != 9 {
+		t.Fatalf("got count %d, wanted 9", got)
+	}
+
+	dt.Swap(0, n-1)
+	if got := dt.CountWhere(GreaterThan("x", 2990)); got != 9 {
+		t.Errorf("got count %d after Swap, wanted 9", got)
+	}
+
+	sort.Sort(sort.Reverse(dt))
+	if got := dt.CountWhere(GreaterThan("x", 2990)); got != 9 {
+		t.Errorf("got count %d after sort.Sort, wanted 9", got)
+	}
+	if got := dt.Matches(GreaterThan("x", 2997)); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("got matches %v after sort.Sort, wanted [0 1]", got)
+	}
+}
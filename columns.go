@@ -0,0 +1,298 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// A ColType is the type of the values held by a column.
+type ColType int
+
+const (
+	// ColNumeric is a column of float64 values.
+	ColNumeric ColType = iota
+
+	// ColString is a column of text values.
+	ColString
+
+	// ColDecimal is a numeric column that also holds exact decimal values,
+	// added by AddDecimalColumn.
+	ColDecimal
+
+	// ColInt is a numeric column that also holds exact integer values, added
+	// by AddIntColumn.
+	ColInt
+
+	// ColTime is a numeric column of seconds since the Unix epoch that also
+	// holds exact times, added by AddTimeColumn.
+	ColTime
+)
+
+func (t ColType) String() string {
+	switch t {
+	case ColNumeric:
+		return "numeric"
+	case ColString:
+		return "string"
+	case ColDecimal:
+		return "decimal"
+	case ColInt:
+		return "int"
+	case ColTime:
+		return "time"
+	}
+	return fmt.Sprintf("ColType(%d)", int(t))
+}
+
+// HasColumn reports whether the table has a column with the given name.
+func (dt *DataTable) HasColumn(name string) bool {
+	_, exists := dt.colorder[name]
+	return exists
+}
+
+// ColumnType returns the type of the named column.
+func (dt *DataTable) ColumnType(name string) (ColType, error) {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return 0, fmt.Errorf("unknown column: %s", name)
+	}
+	return dt.colType(c), nil
+}
+
+// NumericNames returns the names of the numeric columns, including decimal
+// and integer columns, in the order they were added to the table.
+func (dt *DataTable) NumericNames() []string {
+	var names []string
+	for c, name := range dt.colnames {
+		if dt.isFloatCol(c) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// StringNames returns the names of the string columns in the order they were
+// added to the table.
+func (dt *DataTable) StringNames() []string {
+	var names []string
+	for c, name := range dt.colnames {
+		if !dt.isFloatCol(c) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (dt *DataTable) colType(c int) ColType {
+	switch {
+	case dt.cols[c].layouts != nil:
+		return ColTime
+	case dt.cols[c].integer:
+		return ColInt
+	case dt.cols[c].d != nil:
+		return ColDecimal
+	case dt.cols[c].f != nil:
+		return ColNumeric
+	}
+	return ColString
+}
+
+// AddSequenceColumn adds a numeric column whose values start at start and
+// increase by step in each successive row, in the table's current order.
+func (dt *DataTable) AddSequenceColumn(name string, start, step float64) error {
+	vals := make([]float64, dt.Len())
+	for i := range vals {
+		vals[i] = start + float64(i)*step
+	}
+	return dt.AddColumn(name, vals)
+}
+
+// AddRowNumberColumn adds a numeric column holding the position of each row
+// in the table's current order, starting at 1.
+func (dt *DataTable) AddRowNumberColumn(name string) error {
+	return dt.AddSequenceColumn(name, 1, 1)
+}
+
+// A ColumnVisitor is called by EachColumn with the values of each column.
+type ColumnVisitor interface {
+	// VisitFloat is called with the values of a numeric column. Decimal,
+	// integer and time columns are visited with their values as floats.
+	VisitFloat(name string, vals []float64)
+
+	// VisitString is called with the values of a string column.
+	VisitString(name string, vals []string)
+}
+
+// EachColumn calls the appropriate method of v with the name and values of
+// each column in the order the columns were added to the table. The values
+// are the table's own storage, in the table's current order, and must not be
+// modified or retained by v.
+func (dt *DataTable) EachColumn(v ColumnVisitor) {
+	for c, name := range dt.colnames {
+		if dt.isFloatCol(c) {
+			v.VisitFloat(name, dt.cols[c].f)
+		} else {
+			v.VisitString(name, dt.cols[c].s)
+		}
+	}
+}
+
+// MapColumns replaces each value of the named numeric columns with the result
+// of calling fn with it, such as to log-transform or convert the units of
+// several columns at once. It is much faster than calculating a new column
+// for each since fn is called directly on the stored values. Null values are
+// left unchanged. No column is changed if any name is unknown or names a
+// string column. If a key column is changed the table is sorted again.
+func (dt *DataTable) MapColumns(names []string, fn func(v float64) float64) error {
+	cols := make([]int, len(names))
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return fmt.Errorf("unknown column: %s", name)
+		}
+		if !dt.isFloatCol(c) {
+			return fmt.Errorf("%w: column %s", ErrMismatchedColumnTypes, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate column: %s", name)
+		}
+		seen[name] = true
+		cols[i] = c
+	}
+
+	resort := false
+	for _, c := range cols {
+		cv := &dt.cols[c]
+		if cv.d == nil && cv.null == nil {
+			for i, v := range cv.f {
+				cv.f[i] = fn(v)
+			}
+		} else {
+			for i, v := range cv.f {
+				if !cv.isNull(i) {
+					cv.setFloat(i, fn(v))
+				}
+			}
+		}
+		dt.markDirty(c)
+		resort = resort || dt.isKeyCol(c)
+	}
+	if resort {
+		sort.Stable(dt)
+		dt.markAllDirty()
+	}
+	dt.mutated()
+	return nil
+}
+
+// Round rounds each value of the named numeric column to the given number of
+// decimal places, rounding halves away from zero. A negative number of
+// decimals rounds to a power of ten, so -2 rounds to the nearest hundred. NaN
+// and infinite values are unchanged.
+func (dt *DataTable) Round(name string, decimals int) error {
+	p := math.Pow(10, float64(decimals))
+	return dt.MapColumns([]string{name}, func(v float64) float64 {
+		if math.IsInf(v*p, 0) {
+			// infinite or too large to have digits at this precision
+			return v
+		}
+		return math.Round(v*p) / p
+	})
+}
+
+// Abs replaces each value of the named numeric column with its absolute value.
+// NaN values are unchanged.
+func (dt *DataTable) Abs(name string) error {
+	return dt.MapColumns([]string{name}, math.Abs)
+}
+
+// Log replaces each value of the named numeric column with its natural
+// logarithm. Zero becomes -Inf and negative values and NaN become NaN.
+func (dt *DataTable) Log(name string) error {
+	return dt.MapColumns([]string{name}, math.Log)
+}
+
+// Exp replaces each value of the named numeric column with e raised to its
+// power. NaN values are unchanged.
+func (dt *DataTable) Exp(name string) error {
+	return dt.MapColumns([]string{name}, math.Exp)
+}
+
+// Where adds a column named newCol whose value in each row is taken from the
+// thenCol column if the row matches m and from the elseCol column otherwise,
+// such as choosing between a discounted and a regular price. The two columns
+// must both be numeric or both be strings, and null values are copied as
+// nulls. The new column takes the type of the columns, including the scale
+// of decimal columns and the layouts of the thenCol time column, unless they
+// are numeric columns of different types or scales, when it is a plain
+// numeric column. If a column named newCol already exists it is replaced.
+func (dt *DataTable) Where(newCol string, m Matcher, thenCol, elseCol string) error {
+	tc, exists := dt.colorder[thenCol]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", thenCol)
+	}
+	ec, exists := dt.colorder[elseCol]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", elseCol)
+	}
+	if dt.isFloatCol(tc) != dt.isFloatCol(ec) {
+		return fmt.Errorf("%w: columns %s and %s", ErrMismatchedColumnTypes, thenCol, elseCol)
+	}
+
+	var cv colvals
+	switch then := dt.cols[tc]; {
+	case !dt.isFloatCol(tc):
+		cv.s = make([]string, dt.Len())
+	case dt.colType(tc) == dt.colType(ec) && then.scale == dt.cols[ec].scale && then.d != nil:
+		cv = colvals{f: make([]float64, dt.Len()), d: make([]int64, dt.Len()), scale: then.scale, integer: then.integer, layouts: then.layouts}
+	default:
+		cv.f = make([]float64, dt.Len())
+	}
+	rr := RowRef{dt: dt}
+	for rr.index = 0; rr.index < dt.Len(); rr.index++ {
+		src := dt.cols[ec]
+		if m.Match(rr) {
+			src = dt.cols[tc]
+		}
+		cv.setValue(rr.index, src, rr.index)
+	}
+	dt.addColumn(newCol, cv)
+	dt.mutated()
+	return nil
+}
+
+// WhereFloat adds a numeric column named newCol holding thenV in each row that
+// matches m and elseV in the other rows. If a column named newCol already
+// exists it is replaced.
+func (dt *DataTable) WhereFloat(newCol string, m Matcher, thenV, elseV float64) {
+	col := make([]float64, dt.Len())
+	rr := RowRef{dt: dt}
+	for rr.index = range col {
+		if m.Match(rr) {
+			col[rr.index] = thenV
+		} else {
+			col[rr.index] = elseV
+		}
+	}
+	dt.addColumn(newCol, colvals{f: col})
+	dt.mutated()
+}
+
+// WhereString adds a string column named newCol holding thenV in each row that
+// matches m and elseV in the other rows. If a column named newCol already
+// exists it is replaced.
+func (dt *DataTable) WhereString(newCol string, m Matcher, thenV, elseV string) {
+	col := make([]string, dt.Len())
+	rr := RowRef{dt: dt}
+	for rr.index = range col {
+		if m.Match(rr) {
+			col[rr.index] = thenV
+		} else {
+			col[rr.index] = elseV
+		}
+	}
+	dt.addColumn(newCol, colvals{s: col})
+	dt.mutated()
+}
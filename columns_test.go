@@ -0,0 +1,274 @@
+package datatable
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddSequenceColumn(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("s", []string{"c", "a", "b"})
+	dt.SetKeys("s")
+
+	if err := dt.AddRowNumberColumn("n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.AddSequenceColumn("seq", 10, -2.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"s", "n", "seq"},
+		{"a", 1.0, 10.0},
+		{"b", 2.0, 7.5},
+		{"c", 3.0, 5.0},
+	}
+	if !equivalentRows(dt.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", dt.RawRows(true), expected)
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("n", []float64{1})
+	dt.AddStringColumn("s", []string{"a"})
+	dt.AddDecimalColumn("d", []int64{125}, 2)
+
+	if !dt.HasColumn("s") || dt.HasColumn("x") {
+		t.Errorf("HasColumn gave wrong result")
+	}
+	for name, want := range map[string]ColType{"n": ColNumeric, "s": ColString, "d": ColDecimal} {
+		got, err := dt.ColumnType(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("got type %v for %s, wanted %v", got, name, want)
+		}
+	}
+	if _, err := dt.ColumnType("x"); err == nil {
+		t.Errorf("expected error for unknown column")
+	}
+	if got := dt.NumericNames(); !reflect.DeepEqual(got, []string{"n", "d"}) {
+		t.Errorf("got numeric names %v, wanted [n d]", got)
+	}
+	if got := dt.StringNames(); !reflect.DeepEqual(got, []string{"s"}) {
+		t.Errorf("got string names %v, wanted [s]", got)
+	}
+}
+
+type recordingVisitor struct {
+	visits []string
+}
+
+func (r *recordingVisitor) VisitFloat(name string, vals []float64) {
+	r.visits = append(r.visits, fmt.Sprintf("float %s %v", name, vals))
+}
+
+func (r *recordingVisitor) VisitString(name string, vals []string) {
+	r.visits = append(r.visits, fmt.Sprintf("string %s %v", name, vals))
+}
+
+func TestEachColumn(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("s", []string{"b", "a"})
+	dt.AddColumn("n", []float64{1, 2})
+	dt.SetKeys("s")
+
+	v := &recordingVisitor{}
+	dt.EachColumn(v)
+	want := []string{"string s [a b]", "float n [2 1]"}
+	if !reflect.DeepEqual(v.visits, want) {
+		t.Errorf("got visits %v, wanted %v", v.visits, want)
+	}
+}
+
+func TestMapColumns(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"a", "b", "c"})
+	dt.AddColumn("x", []float64{1, 2, 3})
+	dt.AddDecimalColumn("y", []int64{150, 250, 350}, 2)
+	dt.SetNull("y", 1)
+	dt.SetKeys("x")
+
+	negate := func(v float64) float64 { return -2 * v }
+	if err := dt.MapColumns([]string{"x", "y"}, negate); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x := dt.cols[dt.colorder["x"]].f
+	if expected := []float64{-6, -4, -2}; !reflect.DeepEqual(x, expected) {
+		t.Errorf("got %v, wanted %v", x, expected)
+	}
+	if units, _, _ := dt.DecimalValues("y"); !reflect.DeepEqual(units, []int64{-700, 0, -300}) {
+		t.Errorf("got %v, wanted [-700 0 -300]", units)
+	}
+	if got := dt.Matches(IsNull("y")); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("got null rows %v, wanted [1]", got)
+	}
+
+	if err := dt.MapColumns([]string{"x", "k"}, negate); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if x[0] != -6 {
+		t.Errorf("column changed despite error")
+	}
+}
+
+func TestColumnMath(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	testCases := []struct {
+		name     string
+		op       func(dt *DataTable) error
+		in       []float64
+		expected []float64
+	}{
+		{
+			name:     "round",
+			op:       func(dt *DataTable) error { return dt.Round("x", 1) },
+			in:       []float64{1.25, -1.25, 2.04, nan, inf, 1e308},
+			expected: []float64{1.3, -1.3, 2, nan, inf, 1e308},
+		},
+		{
+			name:     "round to hundreds",
+			op:       func(dt *DataTable) error { return dt.Round("x", -2) },
+			in:       []float64{149, 150, -250},
+			expected: []float64{100, 200, -300},
+		},
+		{
+			name:     "abs",
+			op:       func(dt *DataTable) error { return dt.Abs("x") },
+			in:       []float64{-1, 2, nan, math.Inf(-1)},
+			expected: []float64{1, 2, nan, inf},
+		},
+		{
+			name:     "log",
+			op:       func(dt *DataTable) error { return dt.Log("x") },
+			in:       []float64{1, math.E, 0, -1, nan},
+			expected: []float64{0, 1, math.Inf(-1), nan, nan},
+		},
+		{
+			name:     "exp",
+			op:       func(dt *DataTable) error { return dt.Exp("x") },
+			in:       []float64{0, 1, nan, math.Inf(-1)},
+			expected: []float64{1, math.E, nan, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		dt := &DataTable{}
+		dt.AddColumn("x", tc.in)
+		if err := tc.op(dt); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		got := dt.cols[0].f
+		for i := range tc.expected {
+			if !equivalentFloats(got[i], tc.expected[i]) {
+				t.Errorf("%s: got %v, wanted %v", tc.name, got, tc.expected)
+				break
+			}
+		}
+	}
+
+	dt := &DataTable{}
+	dt.AddStringColumn("s", []string{"a"})
+	if err := dt.Abs("s"); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("qty", []float64{1, 10, 5})
+	dt.AddColumn("price", []float64{2, 2, 3})
+	dt.AddColumn("bulk", []float64{1.5, 1.5, 2.5})
+	dt.AddStringColumn("a", []string{"x", "y", "z"})
+	dt.SetNull("bulk", 2)
+
+	bulk := GreaterThan("qty", 4)
+	if err := dt.Where("unit", bulk, "bulk", "price"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unit := dt.cols[dt.colorder["unit"]].f
+	if !equivalentFloatSlices(unit, []float64{2, 1.5, math.NaN()}) {
+		t.Errorf("got %v, wanted [2 1.5 NaN]", unit)
+	}
+	if got := dt.Matches(IsNull("unit")); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("got null rows %v, wanted [2]", got)
+	}
+
+	dt.WhereFloat("flag", bulk, 1, 0)
+	if flag := dt.cols[dt.colorder["flag"]].f; !reflect.DeepEqual(flag, []float64{0, 1, 1}) {
+		t.Errorf("got %v, wanted [0 1 1]", flag)
+	}
+	dt.WhereString("size", bulk, "large", "small")
+	if size := dt.cols[dt.colorder["size"]].s; !reflect.DeepEqual(size, []string{"small", "large", "large"}) {
+		t.Errorf("got %v, wanted [small large large]", size)
+	}
+
+	if err := dt.Where("bad", bulk, "a", "price"); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if err := dt.Where("bad", bulk, "price", "missing"); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
+func TestWhereColumnTypes(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("k", []float64{1, 2, 3})
+	dt.AddIntColumn("i1", []int64{1<<53 + 1, 2, 3})
+	dt.AddIntColumn("i2", []int64{4, 1<<60 + 1, 6})
+	dt.AddDecimalColumn("d1", []int64{105, 110, 115}, 2)
+	dt.AddDecimalColumn("d2", []int64{205, 210, 215}, 2)
+	dt.AddDecimalColumn("d3", []int64{5, 6, 7}, 1)
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 1, time.UTC) }
+	dt.AddTimeColumn("t1", []time.Time{day(1), day(2), day(3)})
+	dt.AddTimeColumn("t2", []time.Time{day(4), day(5), day(6)})
+	dt.SetTimeLayouts("t1", "2006-01-02")
+
+	mid := CloselyEqual("k", 2, 0)
+	if err := dt.Where("i", mid, "i2", "i1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := dt.IntValues("i"); err != nil || !reflect.DeepEqual(got, []int64{1<<53 + 1, 1<<60 + 1, 3}) {
+		t.Errorf("got %v, %v, wanted exact integers", got, err)
+	}
+
+	if err := dt.Where("d", mid, "d2", "d1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if units, scale, err := dt.DecimalValues("d"); err != nil || scale != 2 || !reflect.DeepEqual(units, []int64{105, 210, 115}) {
+		t.Errorf("got %v scale %d, %v, wanted [105 210 115] scale 2", units, scale, err)
+	}
+
+	if err := dt.Where("t", mid, "t2", "t1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if typ, _ := dt.ColumnType("t"); typ != ColTime {
+		t.Errorf("got column type %v, wanted %v", typ, ColTime)
+	}
+	want := []time.Time{day(1), day(5), day(3)}
+	for row := range want {
+		rr, _ := dt.RowRef(row)
+		if got, ok := rr.TimeValue("t"); !ok || !got.Equal(want[row]) {
+			t.Errorf("row %d: got %v, wanted %v", row, got, want[row])
+		}
+	}
+	if layouts := dt.cols[dt.colorder["t"]].layouts; !reflect.DeepEqual(layouts, []string{DefaultTimeLayout}) {
+		t.Errorf("got layouts %v, wanted those of t2", layouts)
+	}
+
+	// columns of different scales give a plain numeric column
+	if err := dt.Where("mixed", mid, "d3", "d1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if typ, _ := dt.ColumnType("mixed"); typ != ColNumeric {
+		t.Errorf("got column type %v, wanted %v", typ, ColNumeric)
+	}
+	if mixed := dt.cols[dt.colorder["mixed"]].f; !equivalentFloatSlices(mixed, []float64{1.05, 0.6, 1.15}) {
+		t.Errorf("got %v, wanted [1.05 0.6 1.15]", mixed)
+	}
+}
@@ -0,0 +1,132 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+)
+
+// A Normalization selects how Crosstab scales the values of its cells.
+type Normalization int
+
+const (
+	NormalizeNone    Normalization = iota // leave cells as aggregated
+	NormalizeRows                         // percentage of the row total
+	NormalizeColumns                      // percentage of the column total
+	NormalizeTotal                        // percentage of the grand total
+)
+
+// CrosstabOptions controls the shape of the table produced by Crosstab.
+type CrosstabOptions struct {
+	// Normalize scales each cell to a percentage of the total of its row,
+	// of its column or of the whole table. Totals are the sums of the
+	// cells, ignoring NaN cells. The default leaves cells unscaled.
+	Normalize Normalization
+}
+
+// Crosstab returns a new table summarising the table by the values of two
+// columns, such as sales by region and product. The result has a row for each
+// distinct value of rowCol, holding that value in a column named rowCol which
+// is set as the key, and a numeric column for each distinct value of colCol,
+// named by the value as it would be written to CSV and ordered by first
+// appearance in the table. Each cell holds the result of a for the rows of the
+// table with the corresponding pair of values, or for no rows if the pair does
+// not occur. An error is returned if a column of the result would have the
+// same name as rowCol.
+func (dt *DataTable) Crosstab(rowCol, colCol string, a Aggregator, opts CrosstabOptions) (*DataTable, error) {
+	rc, exists := dt.colorder[rowCol]
+	if !exists {
+		return nil, fmt.Errorf("unknown column: %s", rowCol)
+	}
+	cc, exists := dt.colorder[colCol]
+	if !exists {
+		return nil, fmt.Errorf("unknown column: %s", colCol)
+	}
+	if opts.Normalize < NormalizeNone || opts.Normalize > NormalizeTotal {
+		return nil, fmt.Errorf("invalid normalization: %d", opts.Normalize)
+	}
+
+	var firsts []int     // the first row holding each value of rowCol
+	var headers []string // the names of the value columns
+	rowIndex := map[string]int{}
+	colIndex := map[string]int{}
+	cells := map[[2]int][]int{}
+	for n := 0; n < dt.Len(); n++ {
+		k := dt.rowKey([]int{rc}, n)
+		r, exists := rowIndex[k]
+		if !exists {
+			r = len(firsts)
+			rowIndex[k] = r
+			firsts = append(firsts, n)
+		}
+		var header string
+		if dt.isFloatCol(cc) {
+			header = dt.formatFloat(cc, n, dt.cols[cc].f[n])
+		} else {
+			header = dt.cols[cc].s[n]
+		}
+		c, exists := colIndex[header]
+		if !exists {
+			c = len(headers)
+			colIndex[header] = c
+			headers = append(headers, header)
+		}
+		cells[[2]int{r, c}] = append(cells[[2]int{r, c}], n)
+	}
+
+	vals := make([][]float64, len(headers))
+	for c := range vals {
+		vals[c] = make([]float64, len(firsts))
+		for r := range firsts {
+			vals[c][r] = a.Aggregate(&StaticRowGroup{dt: dt, indices: cells[[2]int{r, c}]})
+		}
+	}
+	normalize(vals, opts.Normalize)
+
+	ret := &DataTable{}
+	ret.addColumn(rowCol, dt.cols[rc].pick(firsts))
+	for c, header := range headers {
+		if _, exists := ret.colorder[header]; exists {
+			return nil, fmt.Errorf("duplicate column: %s", header)
+		}
+		ret.addColumn(header, colvals{f: vals[c]})
+	}
+	if err := ret.SetKeys(rowCol); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// normalize scales the cells of a table of values, held by column, to
+// percentages of the totals selected by norm.
+func normalize(vals [][]float64, norm Normalization) {
+	if norm == NormalizeNone || len(vals) == 0 {
+		return
+	}
+	sum := func(v float64, total *float64) {
+		if !math.IsNaN(v) {
+			*total += v
+		}
+	}
+	rowTotals := make([]float64, len(vals[0]))
+	colTotals := make([]float64, len(vals))
+	grand := 0.0
+	for c, col := range vals {
+		for r, v := range col {
+			sum(v, &rowTotals[r])
+			sum(v, &colTotals[c])
+			sum(v, &grand)
+		}
+	}
+	for c, col := range vals {
+		for r := range col {
+			total := grand
+			switch norm {
+			case NormalizeRows:
+				total = rowTotals[r]
+			case NormalizeColumns:
+				total = colTotals[c]
+			}
+			col[r] = 100 * col[r] / total
+		}
+	}
+}
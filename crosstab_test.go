@@ -0,0 +1,84 @@
+package datatable
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCrosstab(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"north", "south", "north", "south", "north"})
+	dt.AddColumn("year", []float64{2020, 2020, 2021, 2021, 2021})
+	dt.AddColumn("sales", []float64{10, 20, 30, 10, 10})
+
+	testCases := []struct {
+		norm Normalization
+		want map[string][]float64
+	}{
+		{
+			norm: NormalizeNone,
+			want: map[string][]float64{"2020": {10, 20}, "2021": {40, 10}},
+		},
+		{
+			norm: NormalizeRows,
+			want: map[string][]float64{"2020": {20, 200.0 / 3}, "2021": {80, 100.0 / 3}},
+		},
+		{
+			norm: NormalizeColumns,
+			want: map[string][]float64{"2020": {100.0 / 3, 200.0 / 3}, "2021": {80, 20}},
+		},
+		{
+			norm: NormalizeTotal,
+			want: map[string][]float64{"2020": {12.5, 25}, "2021": {50, 12.5}},
+		},
+	}
+
+	for _, tc := range testCases {
+		ct, err := dt.Crosstab("region", "year", Sum("sales"), CrosstabOptions{Normalize: tc.norm})
+		if err != nil {
+			t.Fatalf("normalization %d: unexpected error: %v", tc.norm, err)
+		}
+		if got := ct.Names(); !reflect.DeepEqual(got, []string{"region", "2020", "2021"}) {
+			t.Errorf("normalization %d: got columns %v", tc.norm, got)
+		}
+		if got := ct.cols[ct.colorder["region"]].s; !reflect.DeepEqual(got, []string{"north", "south"}) {
+			t.Errorf("normalization %d: got rows %v", tc.norm, got)
+		}
+		for name, want := range tc.want {
+			if got := ct.cols[ct.colorder[name]].f; !equivalentFloatSlices(got, want) {
+				t.Errorf("normalization %d: column %s: got %v, wanted %v", tc.norm, name, got, want)
+			}
+		}
+	}
+}
+
+func TestCrosstabMissingCells(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("a", []string{"x", "y", "x"})
+	dt.AddStringColumn("b", []string{"p", "q", "q"})
+
+	ct, err := dt.Crosstab("a", "b", Count(), CrosstabOptions{Normalize: NormalizeRows})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ct.cols[ct.colorder["p"]].f; !equivalentFloatSlices(got, []float64{50, 0}) {
+		t.Errorf("got %v, wanted [50 0]", got)
+	}
+
+	ct, err = dt.Crosstab("a", "b", Mean("missing"), CrosstabOptions{Normalize: NormalizeTotal})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ct.cols[ct.colorder["q"]].f; !math.IsNaN(got[0]) {
+		t.Errorf("got %v, wanted NaN", got)
+	}
+
+	if _, err := dt.Crosstab("a", "missing", Count(), CrosstabOptions{}); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+	dt.AddStringColumn("c", []string{"a", "b", "b"})
+	if _, err := dt.Crosstab("a", "c", Count(), CrosstabOptions{}); err == nil {
+		t.Errorf("got no error for duplicate column")
+	}
+}
@@ -0,0 +1,216 @@
+package datatable
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+)
+
+// CSVOptions controls how ReadCSV parses its input. The zero value reads comma
+// separated data in an automatically detected encoding.
+type CSVOptions struct {
+	// Encoding is the character encoding of the input. The default,
+	// EncodingAuto, detects the encoding and strips any byte order mark.
+	Encoding Encoding
+
+	// DetectedEncoding, if not nil, is set to the encoding the input was
+	// read in, which is Encoding unless that is EncodingAuto.
+	DetectedEncoding *Encoding
+
+	// Comma is the field delimiter. The default is ','.
+	Comma rune
+
+	// Locale describes how numbers and dates are written in the input.
+	Locale Locale
+
+	// ColumnLocales overrides Locale for the named columns.
+	ColumnLocales map[string]Locale
+
+	// ParseDates causes columns whose values are all dates to be read as
+	// numeric columns holding seconds since the Unix epoch.
+	ParseDates bool
+
+	// TimeLayouts lists, for each named column, the layouts used to read it
+	// as a time column, as described by SetTimeLayouts. An error is
+	// returned if a non-empty value cannot be parsed with any of them.
+	TimeLayouts map[string][]string
+
+	// NullValues lists the field values, such as "NA" or the empty string,
+	// that are read as null values. By default no values are null.
+	NullValues []string
+
+	// Columns lists the names of the columns to read, in the order they
+	// should appear in the table. Values of other columns are discarded
+	// without being parsed. A name may not be listed twice. The default
+	// reads every column.
+	Columns []string
+
+	// Skip is the number of records following the header to discard
+	// before reading rows.
+	Skip int
+
+	// Limit is the maximum number of rows to read. Reading stops once the
+	// limit is reached, without examining the rest of the input. The
+	// default, zero, reads every row.
+	Limit int
+
+	// RowFilter, if not nil, is called with the fields of each record that
+	// is not skipped, in the order of the header, and the record is only
+	// read into the table if it returns true. Records that are filtered
+	// out do not count towards Limit. The record may be reused after
+	// RowFilter returns and must not be retained.
+	RowFilter func(record []string) bool
+}
+
+// ReadCSV reads a data table from CSV data in r. The first record must
+// contain the column names. A column is numeric if every non-empty value in it
+// can be parsed as a number according to the column's locale, in which case
+// empty values are read as NaN. If ParseDates is set, a column whose non-empty
+// values are all dates is also numeric. Otherwise the column holds text. The
+// encoding that was detected for the input is reported through
+// DetectedEncoding.
+func ReadCSV(r io.Reader, opts CSVOptions) (*DataTable, error) {
+	dr, enc, err := DecodeReader(r, opts.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("decoding csv: %v", err)
+	}
+	if opts.DetectedEncoding != nil {
+		*opts.DetectedEncoding = enc
+	}
+
+	cr := csv.NewReader(dr)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return &DataTable{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %v", err)
+	}
+
+	seen := make(map[string]bool, len(header))
+	for _, name := range header {
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column name in csv header: %s", name)
+		}
+		seen[name] = true
+	}
+
+	// fields holds the position in each record of the columns to read
+	names := header
+	fields := make([]int, len(header))
+	for i := range fields {
+		fields[i] = i
+	}
+	if opts.Columns != nil {
+		pos := make(map[string]int, len(header))
+		for i, name := range header {
+			pos[name] = i
+		}
+		names, fields = opts.Columns, make([]int, len(opts.Columns))
+		chosen := make(map[string]bool, len(opts.Columns))
+		for i, name := range opts.Columns {
+			p, exists := pos[name]
+			if !exists {
+				return nil, fmt.Errorf("unknown column: %s", name)
+			}
+			if chosen[name] {
+				return nil, fmt.Errorf("duplicate column: %s", name)
+			}
+			chosen[name] = true
+			fields[i] = p
+		}
+	}
+
+	isNull := make(map[string]bool, len(opts.NullValues))
+	for _, s := range opts.NullValues {
+		isNull[s] = true
+	}
+
+	cr.ReuseRecord = true
+	cols := make([][]string, len(names))
+	nulls := make([][]int, len(names)) // positions of null values in each column
+	for skipped, read := 0, 0; opts.Limit <= 0 || read < opts.Limit; {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv row: %v", err)
+		}
+		if skipped < opts.Skip {
+			skipped++
+			continue
+		}
+		if opts.RowFilter != nil && !opts.RowFilter(record) {
+			continue
+		}
+		read++
+		for i, p := range fields {
+			if isNull[record[p]] {
+				nulls[i] = append(nulls[i], len(cols[i]))
+				cols[i] = append(cols[i], "")
+				continue
+			}
+			cols[i] = append(cols[i], record[p])
+		}
+	}
+
+	dt := &DataTable{}
+	for i, name := range names {
+		l := opts.Locale
+		if cl, exists := opts.ColumnLocales[name]; exists {
+			l = cl
+		}
+		if layouts, exists := opts.TimeLayouts[name]; exists {
+			if err := dt.addParsedTimeColumn(name, cols[i], layouts); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if values, ok := parseFloats(cols[i], l.ParseFloat); ok {
+			dt.AddColumn(name, values)
+			continue
+		}
+		if opts.ParseDates {
+			if values, ok := parseFloats(cols[i], l.parseDateSeconds); ok {
+				dt.AddColumn(name, values)
+				continue
+			}
+		}
+		if cols[i] == nil {
+			cols[i] = []string{}
+		}
+		dt.AddStringColumn(name, cols[i])
+	}
+	for c := range nulls {
+		for _, n := range nulls[c] {
+			dt.cols[c].setNull(n, true)
+		}
+	}
+	return dt, nil
+}
+
+// parseFloats attempts to parse every value using parse, reading empty values
+// as NaN. It reports false if any value cannot be parsed or all are empty.
+func parseFloats(values []string, parse func(string) (float64, error)) ([]float64, bool) {
+	ret := make([]float64, len(values))
+	parsed := false
+	for i, s := range values {
+		if s == "" {
+			ret[i] = math.NaN()
+			continue
+		}
+		v, err := parse(s)
+		if err != nil {
+			return nil, false
+		}
+		ret[i] = v
+		parsed = true
+	}
+	return ret, parsed
+}
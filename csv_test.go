@@ -0,0 +1,211 @@
+package datatable
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := "name,score,notes\na,1.5,\nb,,x\nc,3,y\n"
+	dt, err := ReadCSV(strings.NewReader(input), CSVOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"name", "score", "notes"},
+		{"a", 1.5, ""},
+		{"b", math.NaN(), "x"},
+		{"c", 3.0, "y"},
+	}
+	if rows := dt.RawRows(true); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
+
+func TestReadCSVColumns(t *testing.T) {
+	input := "name,score,notes\na,1.5,\nb,,x\nc,3,y\n"
+	dt, err := ReadCSV(strings.NewReader(input), CSVOptions{Columns: []string{"notes", "name"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"notes", "name"},
+		{"", "a"},
+		{"x", "b"},
+		{"y", "c"},
+	}
+	if rows := dt.RawRows(true); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	if _, err := ReadCSV(strings.NewReader(input), CSVOptions{Columns: []string{"missing"}}); err == nil {
+		t.Errorf("expected error for unknown column")
+	}
+	if _, err := ReadCSV(strings.NewReader(input), CSVOptions{Columns: []string{"notes", "notes"}, NullValues: []string{""}}); err == nil {
+		t.Errorf("expected error for duplicate column")
+	}
+}
+
+func TestReadCSVSkipLimitFilter(t *testing.T) {
+	input := "name,score\na,1\nb,2\nc,3\nd,4\ne,5\nf,6\n"
+
+	testCases := []struct {
+		name     string
+		opts     CSVOptions
+		expected []string
+	}{
+		{"skip", CSVOptions{Skip: 4}, []string{"e", "f"}},
+		{"limit", CSVOptions{Limit: 2}, []string{"a", "b"}},
+		{"skip and limit", CSVOptions{Skip: 1, Limit: 2}, []string{"b", "c"}},
+		{"skip beyond end", CSVOptions{Skip: 10}, []string{}},
+		{
+			"filter",
+			CSVOptions{RowFilter: func(r []string) bool { return r[1] != "2" && r[1] != "5" }, Limit: 3},
+			[]string{"a", "c", "d"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dt, err := ReadCSV(strings.NewReader(input), tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectedRows := [][]interface{}{{"name"}}
+			for _, name := range tc.expected {
+				expectedRows = append(expectedRows, []interface{}{name})
+			}
+			sel, _ := dt.Select([]string{"name"})
+			if rows := sel.RawRows(true); !equivalentRows(rows, expectedRows) {
+				t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+			}
+		})
+	}
+}
+
+func TestReadCSVRoundTrip(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c1", []float64{1, 1.25, math.NaN()})
+	dt.AddStringColumn("c2", []string{"a", "b,c", "d"})
+
+	buf := new(bytes.Buffer)
+	if err := dt.CSV(buf); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+	dt2, err := ReadCSV(buf, CSVOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if !equivalentRows(dt.RawRows(true), dt2.RawRows(true)) {
+		t.Errorf("got %+v, wanted %+v", dt2.RawRows(true), dt.RawRows(true))
+	}
+}
+
+func encodeUTF16(s string, bigEndian bool) []byte {
+	var buf []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			buf = append(buf, byte(u>>8), byte(u))
+		} else {
+			buf = append(buf, byte(u), byte(u>>8))
+		}
+	}
+	return buf
+}
+
+func TestDecodeReader(t *testing.T) {
+	const text = "name,city\nzoë,Zürich\n"
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		enc      Encoding
+		expected Encoding
+		text     string
+	}{
+		{
+			name:     "utf8",
+			input:    []byte(text),
+			expected: EncodingUTF8,
+			text:     text,
+		},
+		{
+			name:     "utf8 bom",
+			input:    append([]byte{0xEF, 0xBB, 0xBF}, text...),
+			expected: EncodingUTF8,
+			text:     text,
+		},
+		{
+			name:     "utf16le bom",
+			input:    append([]byte{0xFF, 0xFE}, encodeUTF16(text, false)...),
+			expected: EncodingUTF16LE,
+			text:     text,
+		},
+		{
+			name:     "utf16be bom",
+			input:    append([]byte{0xFE, 0xFF}, encodeUTF16(text, true)...),
+			expected: EncodingUTF16BE,
+			text:     text,
+		},
+		{
+			name:     "utf16le no bom",
+			input:    encodeUTF16(text, false),
+			expected: EncodingUTF16LE,
+			text:     text,
+		},
+		{
+			name:     "windows-1252",
+			input:    []byte("name,price\nzo\xeb,\x8010\n"),
+			expected: EncodingWindows1252,
+			text:     "name,price\nzoë,€10\n",
+		},
+		{
+			name:     "latin1",
+			input:    []byte("name,price\nzo\xeb,\x8010\n"),
+			enc:      EncodingLatin1,
+			expected: EncodingLatin1,
+			text:     "name,price\nzoë,\u008010\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, enc, err := DecodeReader(bytes.NewReader(tc.input), tc.enc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if enc != tc.expected {
+				t.Errorf("got encoding %v, wanted %v", enc, tc.expected)
+			}
+			got, _ := io.ReadAll(r)
+			if string(got) != tc.text {
+				t.Errorf("got %q, wanted %q", got, tc.text)
+			}
+		})
+	}
+}
+
+func TestReadCSVUTF16(t *testing.T) {
+	input := append([]byte{0xFF, 0xFE}, encodeUTF16("name,n\nzoë,1\n", false)...)
+	var enc Encoding
+	dt, err := ReadCSV(bytes.NewReader(input), CSVOptions{DetectedEncoding: &enc})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedRows := [][]interface{}{
+		{"name", "n"},
+		{"zoë", 1.0},
+	}
+	if rows := dt.RawRows(true); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+	if enc != EncodingUTF16LE {
+		t.Errorf("got detected encoding %v, wanted %v", enc, EncodingUTF16LE)
+	}
+}
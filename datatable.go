@@ -9,18 +9,43 @@ import (
 	"io"
 	"math"
 	"sort"
-	"strconv"
+	"strings"
+	"time"
 )
 
 var (
 	ErrInvalidColumnLength   = errors.New("invalid column length")
 	ErrMismatchedColumnTypes = errors.New("mismatched column types")
 	ErrWrongNumberOfColumns  = errors.New("wrong number of columns in data")
+	ErrDuplicateKey          = errors.New("duplicate key")
+	ErrRowOutOfRange         = errors.New("row index out of range")
+	ErrUnsorted              = errors.New("table is not sorted")
 )
 
 type colvals struct {
 	f []float64
 	s []string
+
+	// d holds the exact values of a decimal column in units of 10^-scale
+	// and is nil for other columns. f holds the same values as floats.
+	d     []int64
+	scale int
+
+	// integer is set for an integer column, which is held as a decimal
+	// column with a scale of zero.
+	integer bool
+
+	// layouts is set for a time column, which is held as a decimal column
+	// of seconds since the Unix epoch with a scale of 9. The first layout
+	// is used to format its values.
+	layouts []string
+
+	// null marks the positions holding null values, which also hold NaN,
+	// zero units or the empty string. Positions beyond its end are not null
+	// so it is nil for a column without nulls.
+	null []bool
+
+	cmp Comparator // ordering of a string column when used as a key, nil for byte order
 }
 
 func (cv colvals) Len() int {
@@ -30,6 +55,208 @@ func (cv colvals) Len() int {
 	return len(cv.s)
 }
 
+// pick returns a new column of the same kind holding copies of the values at
+// the given indices.
+func (cv colvals) pick(indices []int) colvals {
+	if cv.f == nil {
+		ret := colvals{s: make([]string, len(indices)), cmp: cv.cmp}
+		for i, idx := range indices {
+			ret.s[i] = cv.s[idx]
+		}
+		ret.pickNulls(cv, indices)
+		return ret
+	}
+	ret := colvals{f: make([]float64, len(indices))}
+	for i, idx := range indices {
+		ret.f[i] = cv.f[idx]
+	}
+	ret.pickNulls(cv, indices)
+	if cv.d != nil {
+		ret.d, ret.scale, ret.integer, ret.layouts = make([]int64, len(indices)), cv.scale, cv.integer, cv.layouts
+		for i, idx := range indices {
+			ret.d[i] = cv.d[idx]
+		}
+	}
+	return ret
+}
+
+// pickPad is like pick but an index of -1 gives a null value.
+func (cv colvals) pickPad(indices []int) colvals {
+	ret := colvals{scale: cv.scale, integer: cv.integer, layouts: cv.layouts, cmp: cv.cmp}
+	ret.pickNulls(cv, indices)
+	if cv.f == nil {
+		ret.s = make([]string, len(indices))
+		for i, idx := range indices {
+			if idx >= 0 {
+				ret.s[i] = cv.s[idx]
+			}
+		}
+		return ret
+	}
+	ret.f = make([]float64, len(indices))
+	if cv.d != nil {
+		ret.d = make([]int64, len(indices))
+	}
+	for i, idx := range indices {
+		if idx < 0 {
+			ret.f[i] = math.NaN()
+			continue
+		}
+		ret.f[i] = cv.f[idx]
+		if cv.d != nil {
+			ret.d[i] = cv.d[idx]
+		}
+	}
+	return ret
+}
+
+// clone returns a copy of the column.
+func (cv colvals) clone() colvals {
+	ret := colvals{scale: cv.scale, integer: cv.integer, layouts: cv.layouts, cmp: cv.cmp}
+	if cv.f != nil {
+		ret.f = append(make([]float64, 0, len(cv.f)), cv.f...)
+	} else {
+		ret.s = append(make([]string, 0, len(cv.s)), cv.s...)
+	}
+	if cv.d != nil {
+		ret.d = append(make([]int64, 0, len(cv.d)), cv.d...)
+	}
+	if cv.null != nil {
+		ret.null = append([]bool(nil), cv.null...)
+	}
+	return ret
+}
+
+func (cv *colvals) swap(i, j int) {
+	if cv.null != nil {
+		if ni, nj := cv.isNull(i), cv.isNull(j); ni != nj {
+			cv.setNull(i, nj)
+			cv.setNull(j, ni)
+		}
+	}
+	if cv.f == nil {
+		cv.s[i], cv.s[j] = cv.s[j], cv.s[i]
+		return
+	}
+	cv.f[i], cv.f[j] = cv.f[j], cv.f[i]
+	if cv.d != nil {
+		cv.d[i], cv.d[j] = cv.d[j], cv.d[i]
+	}
+}
+
+// removeAll deletes the values at the given positions, which must be in
+// ascending order.
+func (cv *colvals) removeAll(indices []int) {
+	if cv.null != nil {
+		cv.null = compact(cv.null, indices)
+	}
+	if cv.f == nil {
+		cv.s = compact(cv.s, indices)
+		return
+	}
+	cv.f = compact(cv.f, indices)
+	if cv.d != nil {
+		cv.d = compact(cv.d, indices)
+	}
+}
+
+// compact removes the elements of vals at the given positions, which must be
+// in ascending order, preserving the order of the remaining elements.
+func compact[T any](vals []T, indices []int) []T {
+	w, next := 0, 0
+	for i := range vals {
+		if next < len(indices) && indices[next] == i {
+			next++
+			continue
+		}
+		vals[w] = vals[i]
+		w++
+	}
+	return vals[:w]
+}
+
+// appendValue appends the value at position n of src, which must be of the
+// same kind.
+func (cv *colvals) appendValue(src colvals, n int) {
+	switch {
+	case cv.f == nil:
+		cv.s = append(cv.s, src.s[n])
+	case cv.d != nil && src.d != nil && cv.scale == src.scale:
+		cv.f = append(cv.f, src.f[n])
+		cv.d = append(cv.d, src.d[n])
+	default:
+		cv.appendFloat(src.f[n])
+	}
+	if src.isNull(n) {
+		cv.setNull(cv.Len()-1, true)
+	}
+}
+
+// setValue sets position i to the value at position n of src, which must be
+// of the same kind.
+func (cv *colvals) setValue(i int, src colvals, n int) {
+	switch {
+	case cv.f == nil:
+		cv.s[i] = src.s[n]
+		cv.setNull(i, false)
+	case cv.d != nil && src.d != nil && cv.scale == src.scale:
+		cv.f[i], cv.d[i] = src.f[n], src.d[n]
+		cv.setNull(i, false)
+	default:
+		cv.setFloat(i, src.f[n])
+	}
+	if src.isNull(n) {
+		cv.setNull(i, true)
+	}
+}
+
+// appendFloats appends the values of a numeric column, converting them to
+// exact units if the column is a decimal column.
+func (cv *colvals) appendFloats(src colvals) {
+	start := cv.Len()
+	switch {
+	case cv.d == nil:
+		cv.f = append(cv.f, src.f...)
+	case src.d != nil && cv.scale == src.scale:
+		cv.f = append(cv.f, src.f...)
+		cv.d = append(cv.d, src.d...)
+	default:
+		for _, v := range src.f {
+			cv.appendFloat(v)
+		}
+	}
+	cv.appendNulls(src, start)
+}
+
+// appendStrings appends the values of a string column.
+func (cv *colvals) appendStrings(src colvals) {
+	start := cv.Len()
+	cv.s = append(cv.s, src.s...)
+	cv.appendNulls(src, start)
+}
+
+// appendFloat appends v to a numeric column, rounding it to the column's
+// scale if it is a decimal column.
+func (cv *colvals) appendFloat(v float64) {
+	cv.f = append(cv.f, 0)
+	if cv.d != nil {
+		cv.d = append(cv.d, 0)
+	}
+	cv.setFloat(len(cv.f)-1, v)
+}
+
+// setFloat sets position i of a numeric column to v, rounding it to the
+// column's scale if it is a decimal column. Values that the units of a
+// decimal column cannot hold are set to NaN.
+func (cv *colvals) setFloat(i int, v float64) {
+	cv.setNull(i, false)
+	if cv.d == nil {
+		cv.f[i] = v
+		return
+	}
+	cv.d[i], cv.f[i] = toUnits(v, cv.scale)
+}
+
 // DataTable is a column-centric table of data. Columns can be either numeric (float64)
 // or text (string). A DataTable is not safe for concurrent use.
 type DataTable struct {
@@ -37,6 +264,30 @@ type DataTable struct {
 	colnames []string
 	colorder map[string]int
 	keys     []int
+	ids      []int64 // optional hidden row identifiers, nil unless enabled
+	nextID   int64
+	undo     *undoLog // log of reversible operations, nil unless enabled
+
+	locale     Locale            // locale used by ParseRow
+	collocales map[string]Locale // per-column locales used by ParseRow, keyed by column name
+
+	strict bool // whether out of range row access panics
+
+	invariants      []invariant // properties checked by CheckInvariants
+	checkInvariants bool        // whether invariants are checked after every modification
+	checking        bool        // whether invariants are currently being checked
+
+	derived map[string]derivation // calculations for columns created by CalcDerived, keyed by column name
+	dirty   map[string]bool       // names of columns modified since the last ResetDirty
+	stats   map[string]colStats   // cached statistics of numeric columns, keyed by column name
+
+	primaryKey []string           // names of the columns set by SetPrimaryKey
+	lineage    map[string]Lineage // how calculated columns were created, keyed by column name
+	version    uint64             // incremented by every modification
+
+	floatFormat     *FloatFormat           // format for numeric values in text exports, nil for %v
+	colFloatFormats map[string]FloatFormat // per-column formats, keyed by column name
+	colunits        map[string]string      // per-column units of measurement, keyed by column name
 }
 
 // AddColumn adds a column of float64 data. The length of the column
@@ -47,6 +298,7 @@ func (dt *DataTable) AddColumn(name string, values []float64) error {
 		return ErrInvalidColumnLength
 	}
 	dt.addColumn(name, colvals{f: values})
+	dt.mutated()
 	return nil
 }
 
@@ -58,6 +310,7 @@ func (dt *DataTable) AddStringColumn(name string, values []string) error {
 		return ErrInvalidColumnLength
 	}
 	dt.addColumn(name, colvals{s: values})
+	dt.mutated()
 	return nil
 }
 
@@ -66,17 +319,26 @@ func (dt *DataTable) addColumn(name string, cv colvals) {
 		dt.cols = []colvals{cv}
 		dt.colorder = map[string]int{name: 0}
 		dt.colnames = []string{name}
+		if dt.ids != nil {
+			dt.ids = dt.ids[:0]
+			dt.syncIDs()
+		}
+		dt.markDirty(0)
 		return
 	}
 
 	if c, exists := dt.colorder[name]; exists {
 		dt.cols[c] = cv
+		delete(dt.derived, name)
+		delete(dt.lineage, name)
+		dt.markDirty(c)
 		return
 	}
 
 	dt.cols = append(dt.cols, cv)
 	dt.colorder[name] = len(dt.cols) - 1
 	dt.colnames = append(dt.colnames, name)
+	dt.markDirty(len(dt.cols) - 1)
 }
 
 // RemoveColumn removes a column of any type from the data table.
@@ -89,6 +351,7 @@ func (dt *DataTable) RemoveColumn(name string) error {
 	if !exists {
 		return fmt.Errorf("unknown column: %s", name)
 	}
+	dt.recordRemoveColumn(c)
 
 	// Shift all column positions
 	for i := c + 1; i < len(dt.cols); i++ {
@@ -100,6 +363,19 @@ func (dt *DataTable) RemoveColumn(name string) error {
 	dt.colnames = dt.colnames[:len(dt.colnames)-1]
 
 	delete(dt.colorder, name)
+	delete(dt.collocales, name)
+	delete(dt.derived, name)
+	delete(dt.lineage, name)
+	delete(dt.dirty, name)
+	delete(dt.stats, name)
+	delete(dt.colFloatFormats, name)
+	delete(dt.colunits, name)
+	for _, k := range dt.primaryKey {
+		if k == name {
+			dt.primaryKey = nil
+			break
+		}
+	}
 
 	// Fix up the keys
 	w := 0 // index to copy value into
@@ -117,6 +393,7 @@ func (dt *DataTable) RemoveColumn(name string) error {
 		}
 	}
 	dt.keys = dt.keys[:w]
+	dt.mutated()
 	return nil
 }
 
@@ -133,19 +410,50 @@ func (dt *DataTable) N() int {
 	return len(dt.cols)
 }
 
+// CheckRow returns an error wrapping ErrRowOutOfRange if n is not the
+// index of a row in the table.
+func (dt *DataTable) CheckRow(n int) error {
+	if n < 0 || n >= dt.Len() {
+		return fmt.Errorf("%w: %d (table has %d rows)", ErrRowOutOfRange, n, dt.Len())
+	}
+	return nil
+}
+
+// SetStrictBounds controls what happens when Row, RowRef or RowMap are
+// called with a row number that exceeds the bounds of the table. By default
+// they report false but in strict mode they panic with an error wrapping
+// ErrRowOutOfRange, which helps to locate indexing bugs.
+func (dt *DataTable) SetStrictBounds(strict bool) {
+	dt.strict = strict
+}
+
+// checkRowAccess reports whether n is a valid row index, panicking if
+// it is not and the table is in strict mode.
+func (dt *DataTable) checkRowAccess(n int) bool {
+	if err := dt.CheckRow(n); err != nil {
+		if dt.strict {
+			panic(err)
+		}
+		return false
+	}
+	return true
+}
+
 // Row returns a single row of data as a slice or an empty slice and false if the
 // row number exceed the bounds of the table. The returned slice contains
 // one value per column in the order the columns were added to
 // the table.
 func (dt *DataTable) Row(n int) ([]interface{}, bool) {
-	if n < 0 || n > dt.Len()-1 {
+	if !dt.checkRowAccess(n) {
 		return []interface{}{}, false
 	}
 	return dt.row(n), true
 }
 
+// RowRef returns a reference to a single row of data or a reference to no
+// row and false if the row number exceeds the bounds of the table.
 func (dt *DataTable) RowRef(n int) (RowRef, bool) {
-	if n < 0 || n > dt.Len()-1 {
+	if !dt.checkRowAccess(n) {
 		return RowRef{-1, dt}, false
 	}
 	return RowRef{n, dt}, true
@@ -155,7 +463,7 @@ func (dt *DataTable) RowRef(n int) (RowRef, bool) {
 // row number exceed the bounds of the table. The keys in the returned map
 // correspond to the names of the columns.
 func (dt *DataTable) RowMap(n int) (RowMap, bool) {
-	if n < 0 || n > dt.Len()-1 {
+	if !dt.checkRowAccess(n) {
 		return RowMap{}, false
 	}
 	data := make(RowMap, dt.N())
@@ -221,12 +529,14 @@ func (dt *DataTable) RawRows(headers bool) [][]interface{} {
 // another row.
 func (dt *DataTable) Swap(i, j int) {
 	for c := range dt.cols {
-		if dt.cols[c].f != nil {
-			dt.cols[c].f[i], dt.cols[c].f[j] = dt.cols[c].f[j], dt.cols[c].f[i]
-		} else {
-			dt.cols[c].s[i], dt.cols[c].s[j] = dt.cols[c].s[j], dt.cols[c].s[i]
-		}
+		dt.cols[c].swap(i, j)
 	}
+	if dt.ids != nil {
+		dt.ids[i], dt.ids[j] = dt.ids[j], dt.ids[i]
+	}
+	// the zone maps describe the rows by position
+	dt.stats = nil
+	dt.version++
 }
 
 // Less compares two rows and returns whether the row with
@@ -238,6 +548,12 @@ func (dt *DataTable) Less(i, j int) bool {
 	if len(dt.keys) == 0 {
 		for c := range dt.cols {
 			if dt.cols[c].f != nil {
+				if r, ok := dt.cols[c].compareExact(i, j); ok {
+					if r == 0 {
+						continue
+					}
+					return r < 0
+				}
 				if dt.cols[c].f[i] == dt.cols[c].f[j] {
 					continue
 				}
@@ -252,21 +568,44 @@ func (dt *DataTable) Less(i, j int) bool {
 		}
 		return false
 	}
-	for _, c := range dt.keys {
+	return dt.compareRows(dt.keys, i, j) < 0
+}
+
+// compareRows compares rows i and j by the values of the columns in cols,
+// using the comparators of string columns if set. It returns a negative number
+// if row i sorts first, zero if the rows are equal and a positive number
+// otherwise, including when NaN values make the rows incomparable.
+func (dt *DataTable) compareRows(cols []int, i, j int) int {
+	for _, c := range cols {
 		if dt.cols[c].f != nil {
-			if dt.cols[c].f[i] == dt.cols[c].f[j] {
+			if r, ok := dt.cols[c].compareExact(i, j); ok {
+				if r != 0 {
+					return r
+				}
 				continue
 			}
-			return dt.cols[c].f[i] < dt.cols[c].f[j]
+			a, b := dt.cols[c].f[i], dt.cols[c].f[j]
+			switch {
+			case a == b:
+				continue
+			case a < b:
+				return -1
+			}
+			return 1
 		}
 
-		if dt.cols[c].s[i] == dt.cols[c].s[j] {
+		a, b := dt.cols[c].s[i], dt.cols[c].s[j]
+		if cmp := dt.cols[c].cmp; cmp != nil {
+			if r := cmp(a, b); r != 0 {
+				return r
+			}
 			continue
 		}
-		return dt.cols[c].s[i] < dt.cols[c].s[j]
-
+		if r := strings.Compare(a, b); r != 0 {
+			return r
+		}
 	}
-	return false
+	return 0
 }
 
 // Equal compares two rows and returns whether they contain the same values.
@@ -277,7 +616,11 @@ func (dt *DataTable) Equal(i, j int) bool {
 	if len(dt.keys) == 0 {
 		for c := range dt.cols {
 			if dt.cols[c].f != nil {
-				if dt.cols[c].f[i] != dt.cols[c].f[j] {
+				if r, ok := dt.cols[c].compareExact(i, j); ok {
+					if r != 0 {
+						return false
+					}
+				} else if dt.cols[c].f[i] != dt.cols[c].f[j] {
 					return false
 				}
 			} else {
@@ -288,20 +631,42 @@ func (dt *DataTable) Equal(i, j int) bool {
 		}
 		return true
 	}
-	for _, c := range dt.keys {
-		if dt.cols[c].f != nil {
-			if dt.cols[c].f[i] != dt.cols[c].f[j] {
-				return false
-			}
-		} else {
-			if dt.cols[c].s[i] != dt.cols[c].s[j] {
-				return false
-			}
+	return dt.compareRows(dt.keys, i, j) == 0
+}
+
+// IsSortedBy reports whether the rows of the table are in ascending order of
+// the named columns, compared in turn, using any comparators set for string
+// columns. It reports false if any column does not exist.
+func (dt *DataTable) IsSortedBy(names ...string) bool {
+	cols := make([]int, len(names))
+	for i, name := range names {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return false
+		}
+		cols[i] = c
+	}
+	for i := 1; i < dt.Len(); i++ {
+		if dt.compareRows(cols, i, i-1) < 0 {
+			return false
 		}
 	}
 	return true
 }
 
+// VerifyKeys checks that the rows of the table are in the order given by its
+// keys, which may not be the case if values in key columns have been changed
+// directly. An error wrapping ErrUnsorted that identifies the first row out
+// of order is returned if they are not.
+func (dt *DataTable) VerifyKeys() error {
+	for i := 1; i < dt.Len(); i++ {
+		if dt.Less(i, i-1) {
+			return fmt.Errorf("%w: row %d sorts before row %d by keys %v", ErrUnsorted, i, i-1, dt.KeyNames())
+		}
+	}
+	return nil
+}
+
 // SetKeys assigns a set of column names to be used as keys
 // when sorting or aggregating. Setting keys sorts the table
 // immediately by the specified keys.
@@ -320,6 +685,8 @@ keyloop:
 
 	dt.keys = keycols
 	sort.Stable(dt)
+	dt.markAllDirty()
+	dt.mutated()
 	return nil
 }
 
@@ -336,9 +703,91 @@ func (dt *DataTable) KeyNames() []string {
 	return names
 }
 
+// A Comparator compares two values of a string column, returning a negative
+// number if a sorts before b, a positive number if a sorts after b and zero
+// if they are equivalent.
+type Comparator func(a, b string) int
+
+// SetKeyComparator sets the comparator used to order and group the values of
+// the named string column when it is one of the table's keys, allowing
+// domain-specific orderings such as NaturalCompare. Values the comparator
+// considers equivalent are grouped together by Aggregate and Apply. A nil
+// comparator restores the default byte-wise ordering. The table is sorted
+// again if the column is a key. The comparator is discarded if the column is
+// replaced.
+func (dt *DataTable) SetKeyComparator(name string, cmp Comparator) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	dt.cols[c].cmp = cmp
+	if dt.isKeyCol(c) {
+		sort.Stable(dt)
+		dt.markAllDirty()
+		dt.mutated()
+	}
+	return nil
+}
+
+// NaturalCompare is a Comparator that orders strings so that runs of digits
+// are compared by their numeric value, so "item2" sorts before "item10".
+// Strings that differ only in leading zeros are ordered by byte value.
+func NaturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			na := strings.TrimLeft(a[si:i], "0")
+			nb := strings.TrimLeft(b[sj:j], "0")
+			if len(na) != len(nb) {
+				if len(na) < len(nb) {
+					return -1
+				}
+				return 1
+			}
+			if r := strings.Compare(na, nb); r != 0 {
+				return r
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	if r := (len(a) - i) - (len(b) - j); r != 0 {
+		if r < 0 {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// SetFloatValue sets the value of the named numeric column in a single row.
+// An error wrapping ErrRowOutOfRange is returned if the row number exceeds
+// the bounds of the table. Decimal, integer and time columns hold infinite
+// values and values too large for their units as NaN.
 func (dt *DataTable) SetFloatValue(name string, row int, v float64) error {
-	if row > dt.Len() {
-		return fmt.Errorf("row index out of bounds")
+	if err := dt.CheckRow(row); err != nil {
+		return err
 	}
 	c, exists := dt.colorder[name]
 	if !exists {
@@ -348,10 +797,72 @@ func (dt *DataTable) SetFloatValue(name string, row int, v float64) error {
 	if !dt.isFloatCol(c) {
 		return ErrMismatchedColumnTypes
 	}
-	dt.cols[c].f[row] = v
+	dt.cols[c].setFloat(row, v)
+	dt.markDirty(c)
+	dt.mutated()
 	return nil
 }
 
+// SetFloatValues sets the value of the named numeric column in each row
+// listed in rows to the corresponding value in vals. All row indices are
+// validated before any value is changed and an error wrapping
+// ErrRowOutOfRange is returned if any exceeds the bounds of the table.
+func (dt *DataTable) SetFloatValues(name string, rows []int, vals []float64) error {
+	c, err := dt.setterColumn(name, rows, len(vals))
+	if err != nil {
+		return err
+	}
+	if !dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	for i, row := range rows {
+		dt.cols[c].setFloat(row, vals[i])
+	}
+	dt.markDirty(c)
+	dt.mutated()
+	return nil
+}
+
+// SetStringValues sets the value of the named string column in each row
+// listed in rows to the corresponding value in vals. All row indices are
+// validated before any value is changed and an error wrapping
+// ErrRowOutOfRange is returned if any exceeds the bounds of the table.
+func (dt *DataTable) SetStringValues(name string, rows []int, vals []string) error {
+	c, err := dt.setterColumn(name, rows, len(vals))
+	if err != nil {
+		return err
+	}
+	if dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	col := dt.cols[c].s
+	for i, row := range rows {
+		col[row] = vals[i]
+		dt.cols[c].setNull(row, false)
+	}
+	dt.markDirty(c)
+	dt.mutated()
+	return nil
+}
+
+// setterColumn looks up the named column and validates the rows that are
+// about to be set in it.
+func (dt *DataTable) setterColumn(name string, rows []int, nvals int) (int, error) {
+	if len(rows) != nvals {
+		return 0, fmt.Errorf("%d rows given for %d values", len(rows), nvals)
+	}
+	c, exists := dt.colorder[name]
+	if !exists {
+		return 0, fmt.Errorf("unknown column: %s", name)
+	}
+	for _, row := range rows {
+		if err := dt.CheckRow(row); err != nil {
+			return 0, err
+		}
+	}
+	return c, nil
+}
+
 // Calc appends a new numeric column to the table whose values will be
 // populated by executing the calculator c against each row of data.
 // Rows are evaluated in the table's current sort order as
@@ -379,6 +890,7 @@ func (dt *DataTable) CalcIndex(colName string, c Calculator, indices []int) {
 	col := fillNaN(dt.Len())
 	dt.CalcIndexFill(col, c, indices)
 	dt.AddColumn(colName, col)
+	dt.recordLineage(colName, "Calc", c)
 }
 
 func (dt *DataTable) CalcIndexFill(col []float64, c Calculator, indices []int) {
@@ -391,6 +903,41 @@ func (dt *DataTable) CalcIndexFill(col []float64, c Calculator, indices []int) {
 	}
 }
 
+// CalcString appends a new string column to the table whose values will be
+// populated by executing the string calculator c against each row of data.
+// Rows are evaluated in the table's current sort order as
+// specified by its keys.
+func (dt *DataTable) CalcString(colName string, c StringCalculator) {
+	dt.CalcStringIndex(colName, c, fillSeq(dt.Len()))
+}
+
+// CalcStringWhere appends a new string column to the table whose values will be
+// populated by executing the string calculator c against each row of data
+// that matches m.
+// Rows are evaluated in the table's current sort order as
+// specified by its keys. Rows not matched by m will be assigned
+// an empty string in the new column.
+func (dt *DataTable) CalcStringWhere(colName string, c StringCalculator, m Matcher) {
+	dt.CalcStringIndex(colName, c, dt.Matches(m))
+}
+
+// CalcStringIndex appends a new string column to the table whose values will be
+// populated by executing the string calculator c against each row of data
+// whose index is contained in indices. Rows are evaluated in the order
+// they appear in indices. Rows not present in indices will be assigned
+// an empty string in the new column.
+func (dt *DataTable) CalcStringIndex(colName string, c StringCalculator, indices []int) {
+	col := make([]string, dt.Len())
+	if dt.N() != 0 {
+		rr := RowRef{dt: dt}
+		for _, rr.index = range indices {
+			col[rr.index] = c.CalculateString(rr)
+		}
+	}
+	dt.AddStringColumn(colName, col)
+	dt.recordLineage(colName, "CalcString", c)
+}
+
 // Aggregate appends a new numeric column to the table whose values will be
 // populated by executing the aggregator a against each group
 // of rows that share the same key column values. Each row in a group
@@ -422,6 +969,7 @@ func (dt *DataTable) AggregateIndex(colName string, a Aggregator, indices []int)
 	col := fillNaN(dt.Len())
 	dt.AggregateIndexFill(col, a, indices)
 	dt.AddColumn(colName, col)
+	dt.recordLineage(colName, "Aggregate", a)
 }
 
 // AggregateIndexFill populates col with values found by executing the
@@ -437,38 +985,113 @@ func (dt *DataTable) AggregateIndexFill(col []float64, a Aggregator, indices []i
 	// reset for each group.
 	rg := &StaticRowGroup{dt: dt}
 
-	// Loop through indices identifying groups of rows that share the same key
-	// then apply the aggregate function to those rows and use the result as
-	// the new column value for each row in the group.
-	groupRow := -1
-	groupIndex := -1
-	for i, row := range indices {
-		if groupIndex == -1 {
-			groupIndex = i
-			groupRow = row
-			continue
-		}
-
-		if dt.Equal(groupRow, row) {
-			continue
-		}
-
+	// Apply the aggregate function to each group of rows that share the same key
+	// and use the result as the new column value for each row in the group.
+	dt.eachGroup(indices, func(group []int) {
 		rg.Reset()
-		rg.indices = indices[groupIndex:i]
+		rg.indices = group
 		val := a.Aggregate(rg)
-		for j := groupIndex; j < i; j++ {
-			col[indices[j]] = val
+		for _, row := range group {
+			col[row] = val
 		}
-		groupIndex = i
-		groupRow = row
+	})
+}
+
+// AggregateSplit appends one new numeric column per entry in partitions, each
+// named by joining colPrefix with the partition name. The values are populated by
+// executing the aggregator a against the rows of each key group that match the
+// partition's matcher. Every row in a group is assigned the value for each
+// partition, so a group with no matching rows for a partition receives the
+// result of aggregating an empty group. All partitions are evaluated in a
+// single pass over the groups. Columns are added in order of partition name.
+func (dt *DataTable) AggregateSplit(colPrefix string, a Aggregator, partitions map[string]Matcher) {
+	names := make([]string, 0, len(partitions))
+	for name := range partitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cols := make([][]float64, len(names))
+	for i := range cols {
+		cols[i] = fillNaN(dt.Len())
+	}
+
+	if dt.Len() != 0 && dt.N() != 0 {
+		rg := &StaticRowGroup{dt: dt}
+		dt.eachGroup(fillSeq(dt.Len()), func(group []int) {
+			rg.indices = group
+			for i, name := range names {
+				val := a.Aggregate(rg.Where(partitions[name]))
+				for _, row := range group {
+					cols[i][row] = val
+				}
+			}
+		})
+	}
+
+	for i, name := range names {
+		dt.AddColumn(colPrefix+name, cols[i])
+	}
+}
+
+// AggregateMany appends a new numeric column to the table for each entry in
+// names, populated like Aggregate by executing the corresponding aggregator in
+// aggs against each group of rows that share the same key column values. The
+// groups are found once and every aggregator is evaluated for a group before
+// moving on to the next, which is faster than calling Aggregate for each
+// column. An error is returned if names and aggs have different lengths.
+func (dt *DataTable) AggregateMany(names []string, aggs []Aggregator) error {
+	if len(names) != len(aggs) {
+		return fmt.Errorf("got %d column names for %d aggregators", len(names), len(aggs))
+	}
+	cols := make([][]float64, len(aggs))
+	for i := range cols {
+		cols[i] = fillNaN(dt.Len())
+	}
+
+	if dt.Len() != 0 && dt.N() != 0 {
+		rg := &StaticRowGroup{dt: dt}
+		dt.eachGroup(fillSeq(dt.Len()), func(group []int) {
+			for i, a := range aggs {
+				rg.Reset()
+				rg.indices = group
+				val := a.Aggregate(rg)
+				for _, row := range group {
+					cols[i][row] = val
+				}
+			}
+		})
 	}
 
-	rg.Reset()
-	rg.indices = indices[groupIndex:]
-	val := a.Aggregate(rg)
-	for j := groupIndex; j < len(indices); j++ {
-		col[indices[j]] = val
+	for i, name := range names {
+		dt.AddColumn(name, cols[i])
+		dt.recordLineage(name, "Aggregate", aggs[i])
 	}
+	return nil
+}
+
+// eachGroup calls fn with each run of consecutive indices that refer to rows
+// sharing the same key column values.
+func (dt *DataTable) eachGroup(indices []int, fn func(group []int)) {
+	dt.eachRun(indices, func(start, prev, cur int) bool { return !dt.Equal(start, cur) }, fn)
+}
+
+// eachRun calls fn with each run of consecutive indices, starting a new run
+// whenever brk reports true. brk is passed the first row of the current run,
+// the previous row and the row being considered.
+func (dt *DataTable) eachRun(indices []int, brk func(start, prev, cur int) bool, fn func(group []int)) {
+	if len(indices) == 0 {
+		return
+	}
+	groupIndex := 0
+	for i := 1; i < len(indices); i++ {
+		if !brk(indices[groupIndex], indices[i-1], indices[i]) {
+			continue
+		}
+		fn(indices[groupIndex:i])
+		groupIndex = i
+	}
+	fn(indices[groupIndex:])
 }
 
 // Apply executes the grouper function g against each group
@@ -529,32 +1152,173 @@ func (dt *DataTable) ApplyIndex(g Grouper, indices []int) {
 	// reset for each group.
 	rg := &StaticRowGroup{dt: dt}
 
-	// Loop through indices identifying groups of rows that share the same key
-	// then apply the aggregate function to those rows and use the result as
-	// the new column value for each row in the group.
-	groupRow := -1
-	groupIndex := -1
-	for i, row := range indices {
-		if groupIndex == -1 {
-			groupIndex = i
-			groupRow = row
-			continue
+	// Apply the grouper to each group of rows that share the same key.
+	dt.eachGroup(indices, func(group []int) {
+		rg.Reset()
+		rg.indices = group
+		g.Group(rg)
+	})
+}
+
+// ApplySorted executes the grouper function g against each group of rows that
+// share the same key column values, delivering the rows of each group in
+// ascending order of the named columns rather than the table's order. Groups
+// are evaluated in the table's current sort order and the table itself is not
+// reordered.
+func (dt *DataTable) ApplySorted(g Grouper, by ...string) error {
+	cols := make([]int, len(by))
+	for i, name := range by {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return fmt.Errorf("unknown column: %s", name)
 		}
+		cols[i] = c
+	}
+	if dt.Len() == 0 || dt.N() == 0 || g == nil {
+		return nil
+	}
 
-		if dt.Equal(groupRow, row) {
-			continue
+	rg := &StaticRowGroup{dt: dt}
+	dt.eachGroup(fillSeq(dt.Len()), func(group []int) {
+		sort.SliceStable(group, func(a, b int) bool {
+			return dt.compareRows(cols, group[a], group[b]) < 0
+		})
+		rg.Reset()
+		rg.indices = group
+		g.Group(rg)
+	})
+	return nil
+}
+
+// MapGroups calls fn with each group of rows that share the same key column
+// values and concatenates the tables it returns into a new table, allowing
+// each group to produce any number of result rows. fn may return nil to
+// produce no rows. The result has the union of the columns of the returned
+// tables in the order they are first seen, padded with NaN or the empty
+// string, and has no keys set. Groups are evaluated in the table's current
+// sort order. An error is returned if fn returns an error or if a column is
+// numeric in some results and text in others.
+func (dt *DataTable) MapGroups(fn func(rg RowGroup) (*DataTable, error)) (*DataTable, error) {
+	var results []*DataTable
+	var err error
+	rg := &StaticRowGroup{dt: dt}
+	dt.eachGroup(fillSeq(dt.Len()), func(group []int) {
+		if err != nil {
+			return
+		}
+		rg.Reset()
+		rg.indices = group
+		var res *DataTable
+		if res, err = fn(rg); err == nil && res != nil {
+			results = append(results, res)
 		}
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	ret, _, err := AppendAll(SchemaError, results...)
+	return ret, err
+}
+
+// ApplyRuns executes the grouper function g against each run of consecutive
+// rows that share the same value in the named column. Runs are found in the
+// table's current row order, so rows with equal values that are separated by
+// other rows form separate groups. Unlike Apply, the keys of the table are not
+// used.
+func (dt *DataTable) ApplyRuns(name string, g Grouper) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.Len() == 0 || g == nil {
+		return nil
+	}
+
+	rg := &StaticRowGroup{dt: dt}
+	dt.eachRun(fillSeq(dt.Len()), func(start, prev, cur int) bool {
+		if dt.isFloatCol(c) {
+			return dt.cols[c].f[prev] != dt.cols[c].f[cur]
+		}
+		return dt.cols[c].s[prev] != dt.cols[c].s[cur]
+	}, func(group []int) {
 		rg.Reset()
-		rg.indices = indices[groupIndex:i]
+		rg.indices = group
 		g.Group(rg)
-		groupIndex = i
-		groupRow = row
+	})
+	return nil
+}
+
+// A BreakFunc reports whether a new group should be started between two
+// consecutive rows.
+type BreakFunc func(prev, cur RowRef) bool
+
+// ApplyBreak executes the grouper function g against each run of consecutive
+// rows, starting a new run whenever brk reports true for a pair of adjacent
+// rows. This allows groups to be formed by conditions other than equality of
+// the keys, such as starting a new session when the time between events
+// exceeds a limit. Rows are evaluated in the table's current sort order as
+// specified by its keys.
+func (dt *DataTable) ApplyBreak(g Grouper, brk BreakFunc) {
+	if dt.Len() == 0 || dt.N() == 0 || g == nil || brk == nil {
+		return
 	}
 
-	rg.Reset()
-	rg.indices = indices[groupIndex:]
-	g.Group(rg)
+	rg := &StaticRowGroup{dt: dt}
+	prev, cur := RowRef{dt: dt}, RowRef{dt: dt}
+	dt.eachRun(fillSeq(dt.Len()), func(start, p, c int) bool {
+		prev.index, cur.index = p, c
+		return brk(prev, cur)
+	}, func(group []int) {
+		rg.Reset()
+		rg.indices = group
+		g.Group(rg)
+	})
+}
+
+// GapExceeds returns a BreakFunc that starts a new group when the value of the
+// named numeric column increases by more than gap from one row to the next.
+// With a column of timestamps in seconds this separates events into sessions.
+func GapExceeds(name string, gap float64) BreakFunc {
+	return func(prev, cur RowRef) bool {
+		p, ok := prev.FloatValue(name)
+		if !ok {
+			return false
+		}
+		c, ok := cur.FloatValue(name)
+		if !ok {
+			return false
+		}
+		return c-p > gap
+	}
+}
+
+// Changed returns a BreakFunc that starts a new group when the value of any of
+// the named columns differs from one row to the next.
+func Changed(names ...string) BreakFunc {
+	return func(prev, cur RowRef) bool {
+		for _, name := range names {
+			p, _ := prev.Value(name)
+			c, _ := cur.Value(name)
+			if p != c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AnyBreak returns a BreakFunc that starts a new group when any of brks
+// reports true.
+func AnyBreak(brks ...BreakFunc) BreakFunc {
+	return func(prev, cur RowRef) bool {
+		for _, brk := range brks {
+			if brk(prev, cur) {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 // Reduce returns the value obtained by executing the
@@ -563,6 +1327,44 @@ func (dt *DataTable) Reduce(a Aggregator) float64 {
 	return a.Aggregate(dt.Rows())
 }
 
+// ReduceWhere returns the value obtained by executing the
+// aggregator a against the rows in the datatable that match m.
+func (dt *DataTable) ReduceWhere(a Aggregator, m Matcher) float64 {
+	return a.Aggregate(&StaticRowGroup{dt: dt, indices: dt.Matches(m)})
+}
+
+// ReduceMulti returns the values obtained by executing each of the
+// aggregators against every row in the datatable, keyed by the same
+// names as aggs. The partial aggregators, such as PartialSum and
+// PartialMean, are all evaluated in a single scan of the table, while
+// each other aggregator makes its own scan.
+func (dt *DataTable) ReduceMulti(aggs map[string]Aggregator) map[string]float64 {
+	indices := fillSeq(dt.Len())
+	ret := make(map[string]float64, len(aggs))
+	var names []string
+	var states []*moments
+	for name, a := range aggs {
+		if m, ok := a.(*moments); ok {
+			names = append(names, name)
+			states = append(states, &moments{name: m.name, kind: m.kind})
+			continue
+		}
+		ret[name] = a.Aggregate(&StaticRowGroup{dt: dt, indices: indices})
+	}
+	if len(states) > 0 {
+		rg := &StaticRowGroup{dt: dt, indices: indices}
+		for rg.Next() {
+			for _, m := range states {
+				m.accumulateRow(rg)
+			}
+		}
+		for i, m := range states {
+			ret[names[i]] = m.Result()
+		}
+	}
+	return ret
+}
+
 func (dt *DataTable) Rows() RowGroup {
 	return &StaticRowGroup{
 		dt:      dt,
@@ -579,16 +1381,21 @@ func (dt *DataTable) RowsWhere(m Matcher) RowGroup {
 }
 
 func (dt *DataTable) Matches(m Matcher) []int {
-	if dt.Len() == 0 || dt.N() == 0 {
+	if cm, ok := m.(*cachedMatcher); ok {
+		return cm.matches(dt)
+	}
+	if dt.Len() == 0 || dt.N() == 0 || !dt.CanMatch(m) {
 		return []int{}
 	}
 
 	rows := make([]int, 0, dt.Len())
 
 	rr := RowRef{dt: dt}
-	for rr.index = 0; rr.index < dt.Len(); rr.index++ {
-		if m.Match(rr) {
-			rows = append(rows, rr.index)
+	for _, r := range dt.candidateRanges(m) {
+		for rr.index = r[0]; rr.index < r[1]; rr.index++ {
+			if m.Match(rr) {
+				rows = append(rows, rr.index)
+			}
 		}
 	}
 	return rows
@@ -598,20 +1405,88 @@ func (dt *DataTable) Matches(m Matcher) []int {
 // Rows are evaluated in the table's current sort order as
 // specified by its keys.
 func (dt *DataTable) CountWhere(m Matcher) int {
-	if dt.Len() == 0 || dt.N() == 0 {
+	if cm, ok := m.(*cachedMatcher); ok {
+		return len(cm.matches(dt))
+	}
+	if dt.Len() == 0 || dt.N() == 0 || !dt.CanMatch(m) {
 		return 0
 	}
 
 	count := 0
 	rr := RowRef{dt: dt}
-	for rr.index = 0; rr.index < dt.Len(); rr.index++ {
-		if m.Match(rr) {
-			count++
+	for _, r := range dt.candidateRanges(m) {
+		for rr.index = r[0]; rr.index < r[1]; rr.index++ {
+			if m.Match(rr) {
+				count++
+			}
 		}
 	}
 	return count
 }
 
+// SumWhere returns the sum of the values of the named numeric column in the
+// rows that match m. Rows whose value is NaN are ignored. NaN is returned if
+// the column does not exist or is not numeric.
+func (dt *DataTable) SumWhere(name string, m Matcher) float64 {
+	sum, _ := dt.sumWhere(name, m)
+	return sum
+}
+
+// MeanWhere returns the mean of the values of the named numeric column in the
+// rows that match m. Rows whose value is NaN are ignored. NaN is returned if
+// no rows have a value or the column does not exist or is not numeric.
+func (dt *DataTable) MeanWhere(name string, m Matcher) float64 {
+	sum, n := dt.sumWhere(name, m)
+	return sum / float64(n)
+}
+
+// MinWhere returns the smallest value of the named numeric column in the rows
+// that match m. Rows whose value is NaN are ignored. NaN is returned if no
+// rows have a value or the column does not exist or is not numeric.
+func (dt *DataTable) MinWhere(name string, m Matcher) float64 {
+	return dt.extremeWhere(name, m, func(a, b float64) bool { return a < b })
+}
+
+// MaxWhere returns the largest value of the named numeric column in the rows
+// that match m. Rows whose value is NaN are ignored. NaN is returned if no
+// rows have a value or the column does not exist or is not numeric.
+func (dt *DataTable) MaxWhere(name string, m Matcher) float64 {
+	return dt.extremeWhere(name, m, func(a, b float64) bool { return a > b })
+}
+
+// sumWhere returns the sum and number of the non-NaN values of the named
+// numeric column in the rows that match m.
+func (dt *DataTable) sumWhere(name string, m Matcher) (float64, int) {
+	c, exists := dt.colorder[name]
+	if !exists || !dt.isFloatCol(c) {
+		return math.NaN(), 0
+	}
+	sum, n := 0.0, 0
+	for _, i := range dt.Matches(m) {
+		if v := dt.cols[c].f[i]; !math.IsNaN(v) {
+			sum += v
+			n++
+		}
+	}
+	return sum, n
+}
+
+// extremeWhere returns the non-NaN value of the named numeric column in the
+// rows that match m that is preferred over all others by better.
+func (dt *DataTable) extremeWhere(name string, m Matcher, better func(a, b float64) bool) float64 {
+	c, exists := dt.colorder[name]
+	if !exists || !dt.isFloatCol(c) {
+		return math.NaN()
+	}
+	ret := math.NaN()
+	for _, i := range dt.Matches(m) {
+		if v := dt.cols[c].f[i]; !math.IsNaN(v) && (math.IsNaN(ret) || better(v, ret)) {
+			ret = v
+		}
+	}
+	return ret
+}
+
 // RemoveRows removes any rows that match m without altering their order.
 func (dt *DataTable) RemoveRows(m Matcher) {
 	if dt.Len() == 0 || dt.N() == 0 {
@@ -623,40 +1498,76 @@ func (dt *DataTable) RemoveRows(m Matcher) {
 		// Nothing to do
 		return
 	}
+	dt.removeRows(matches)
+}
 
-	for i := len(matches) - 1; i >= 0; i-- {
-
-		p := matches[i]
-		for c := range dt.cols {
-			if dt.cols[c].f != nil {
-				dt.cols[c].f = append(dt.cols[c].f[0:p], dt.cols[c].f[p+1:]...)
-			} else {
-				dt.cols[c].s = append(dt.cols[c].s[0:p], dt.cols[c].s[p+1:]...)
-			}
-		}
+// removeRows removes the rows at the given positions, which must be in
+// ascending order, in a single pass over each column.
+func (dt *DataTable) removeRows(indices []int) {
+	dt.recordRemoveRows(indices)
+	for c := range dt.cols {
+		dt.cols[c].removeAll(indices)
+	}
+	if dt.ids != nil {
+		dt.ids = compact(dt.ids, indices)
 	}
+	dt.markAllDirty()
+	dt.mutated()
 }
 
 // ParseRow attempts to append a row of data by parsing values
 // as either float64 or string depending on the existing type
 // of the relevant column. Values are processed in the order
-// that columns were added to the table.
+// that columns were added to the table. Numbers are parsed
+// according to the locale set for the column or table. Dates
+// are not read into numeric columns; add a time column for them.
+// Values for decimal and integer columns are parsed exactly
+// and values for time columns are parsed using the column's layouts. An empty
+// value in an integer or time column is read as NaN.
 func (dt *DataTable) ParseRow(values ...string) error {
 	if len(values) != dt.N() {
 		return ErrWrongNumberOfColumns
 	}
 
 	for i := 0; i < len(values); i++ {
-		if dt.isFloatCol(i) {
-			v, err := strconv.ParseFloat(values[i], 64)
+		if cv := &dt.cols[i]; cv.layouts != nil {
+			u, v, err := parseTime(values[i], cv.layouts)
+			if err != nil {
+				return fmt.Errorf("%v (column %d)", err, i)
+			}
+			cv.f = append(cv.f, v)
+			cv.d = append(cv.d, u)
+		} else if cv.integer {
+			u, v, err := dt.columnLocale(i).parseInt(values[i])
+			if err != nil {
+				return fmt.Errorf("%v (column %d)", err, i)
+			}
+			cv.f = append(cv.f, v)
+			cv.d = append(cv.d, u)
+		} else if cv.d != nil {
+			u, err := dt.columnLocale(i).parseDecimal(values[i], cv.scale)
+			if err != nil {
+				return fmt.Errorf("%v (column %d)", err, i)
+			}
+			cv.f = append(cv.f, fromUnits(u, cv.scale))
+			cv.d = append(cv.d, u)
+		} else if dt.isFloatCol(i) {
+			v, err := dt.columnLocale(i).ParseFloat(values[i])
 			if err != nil {
 				return fmt.Errorf("%v (column %d)", err, i)
 			}
-			dt.cols[i].f = append(dt.cols[i].f, v) // TODO: don't add until all values have been parsed
+			dt.cols[i].appendFloat(v) // TODO: don't add until all values have been parsed
 		} else {
 			dt.cols[i].s = append(dt.cols[i].s, values[i])
 		}
 	}
+	if err := dt.checkPrimaryKey(dt.Len() - 1); err != nil {
+		dt.truncate(dt.Len() - 1)
+		return err
+	}
+	dt.syncIDs()
+	dt.markAllDirty()
+	dt.mutated()
 
 	return nil
 }
@@ -664,13 +1575,16 @@ func (dt *DataTable) ParseRow(values ...string) error {
 // Append appends the rows of dt2 to the data table. An error
 // is returned if the tables share a column name with differing
 // types (numeric vs text). Columns present in dt but not in
-// dt2 will be expanded to the correct length with either NaN or
-// the empty string. Columns present in dt2 but not dt will be
-// pre-filled with NaN or empty strings before the dt2's data is
-// appened.
+// dt2 will be expanded to the correct length with null values,
+// holding either NaN or the empty string. Columns present in dt2
+// but not dt will be pre-filled with null values before the dt2's
+// data is appened.
 // The data table remains sorted according to its keys after the
 // append.
 func (dt *DataTable) Append(dt2 *DataTable) error {
+	if err := dt.checkAppendPrimaryKey(dt2); err != nil {
+		return err
+	}
 	currentLen := dt.Len()
 	for name, c2 := range dt2.colorder {
 		c, exists := dt.colorder[name]
@@ -680,26 +1594,32 @@ func (dt *DataTable) Append(dt2 *DataTable) error {
 			// New column so fill with NaN or empty string first
 			// then append new values
 			if dt2.cols[c2].f != nil {
-				values := fillNaN(currentLen)
-				values = append(values, dt2.cols[c2].f...)
-				dt.addColumn(name, colvals{f: values})
+				cv := colvals{f: fillNaN(currentLen)}
+				cv.setNullRange(0, currentLen)
+				if dt2.cols[c2].d != nil {
+					cv.d, cv.scale = make([]int64, currentLen), dt2.cols[c2].scale
+					cv.integer, cv.layouts = dt2.cols[c2].integer, dt2.cols[c2].layouts
+				}
+				cv.appendFloats(dt2.cols[c2])
+				dt.addColumn(name, cv)
 				continue
 			} else {
-				values := make([]string, currentLen)
-				values = append(values, dt2.cols[c2].s...)
-				dt.addColumn(name, colvals{s: values})
+				cv := colvals{s: make([]string, currentLen)}
+				cv.setNullRange(0, currentLen)
+				cv.appendStrings(dt2.cols[c2])
+				dt.addColumn(name, cv)
 				continue
 			}
 		}
 
 		// Column in both dt and dt2
 		if dt.cols[c].f != nil && dt2.cols[c2].f != nil {
-			dt.cols[c].f = append(dt.cols[c].f, dt2.cols[c2].f...)
+			dt.cols[c].appendFloats(dt2.cols[c2])
 			continue
 		}
 
 		if dt.cols[c].s != nil && dt2.cols[c2].s != nil {
-			dt.cols[c].s = append(dt.cols[c].s, dt2.cols[c2].s...)
+			dt.cols[c].appendStrings(dt2.cols[c2])
 			continue
 		}
 
@@ -710,22 +1630,65 @@ func (dt *DataTable) Append(dt2 *DataTable) error {
 	// Now pad out any columns that are in dt but not dt2
 	for name, c := range dt.colorder {
 		if _, exists := dt2.colorder[name]; !exists {
+			start := dt.cols[c].Len()
 			if dt.cols[c].f != nil {
-				dt.cols[c].f = append(dt.cols[c].f, fillNaN(dt2.Len())...)
+				dt.cols[c].appendFloats(colvals{f: fillNaN(dt2.Len())})
 			} else {
 				dt.cols[c].s = append(dt.cols[c].s, make([]string, dt2.Len())...)
 			}
+			dt.cols[c].setNullRange(start, dt.cols[c].Len())
 		}
 	}
+	dt.syncIDs()
 
 	// Keep dt sorted
 	if len(dt.keys) > 0 {
 		sort.Stable(dt)
 	}
+	dt.markAllDirty()
+	dt.mutated()
 
 	return nil
 }
 
+// AppendDedup appends the rows of dt2 whose values in the keys columns do not
+// match those of any row already in the table, making repeated ingestion of
+// overlapping data idempotent. Only the first of several rows in dt2 sharing
+// the same key values is appended. The rows are appended as by Append and the
+// number of rows appended is returned.
+func (dt *DataTable) AppendDedup(dt2 *DataTable, keys []string) (int, error) {
+	cols, cols2, err := joinColumns(dt, dt2, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]bool, dt.Len())
+	for i := 0; i < dt.Len(); i++ {
+		seen[dt.rowKey(cols, i)] = true
+	}
+	var indices []int
+	for i := 0; i < dt2.Len(); i++ {
+		k := dt2.rowKey(cols2, i)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		indices = append(indices, i)
+	}
+	if len(indices) == 0 {
+		return 0, nil
+	}
+
+	rows, err := dt2.SelectIndex(dt2.Names(), indices)
+	if err != nil {
+		return 0, err
+	}
+	if err := dt.Append(rows); err != nil {
+		return 0, err
+	}
+	return len(indices), nil
+}
+
 // Select returns a new data table containing copies of the columns
 // specified in names. The returned data table will have no keys
 // set.
@@ -737,20 +1700,56 @@ func (dt *DataTable) Select(names []string) (*DataTable, error) {
 			return nil, fmt.Errorf("unknown column: %s", name)
 		}
 
-		if dt.cols[c].f != nil {
-			values := make([]float64, len(dt.cols[c].f))
-			copy(values, dt.cols[c].f)
-			dt2.addColumn(name, colvals{f: values})
-		} else {
-			values := make([]string, len(dt.cols[c].s))
-			copy(values, dt.cols[c].s)
-			dt2.addColumn(name, colvals{s: values})
-		}
+		dt2.addColumn(name, dt.cols[c].clone())
+	}
+
+	if dt.ids != nil {
+		dt2.ids = make([]int64, len(dt.ids))
+		copy(dt2.ids, dt.ids)
+		dt2.nextID = dt.nextID
 	}
 
 	return dt2, nil
 }
 
+// SelectKeepKeys is like Select but, if all of the table's key columns are
+// among names, the returned table has the same keys. Since the copied rows are
+// already in key order the returned table is not sorted again. Otherwise the
+// returned table has no keys set.
+func (dt *DataTable) SelectKeepKeys(names []string) (*DataTable, error) {
+	dt2, err := dt.Select(names)
+	if err != nil {
+		return nil, err
+	}
+	dt2.keys = keyColumns(dt.KeyNames(), dt2)
+	return dt2, nil
+}
+
+// CloneWithKeys is like Clone but the returned table has the same keys as dt.
+// Since the copied rows are already in key order the returned table is not
+// sorted again.
+func (dt *DataTable) CloneWithKeys() *DataTable {
+	dt2, _ := dt.SelectKeepKeys(dt.Names())
+	return dt2
+}
+
+// keyColumns returns the positions in dt of the named key columns, or nil if
+// any is missing.
+func keyColumns(keys []string, dt *DataTable) []int {
+	if len(keys) == 0 {
+		return nil
+	}
+	cols := make([]int, len(keys))
+	for i, name := range keys {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil
+		}
+		cols[i] = c
+	}
+	return cols
+}
+
 // SelectWhere returns a new data table containing copies of the columns
 // specified in names where the rows match m. The returned data table
 // will have no keys set.
@@ -770,28 +1769,50 @@ func (dt *DataTable) SelectIndex(names []string, indices []int) (*DataTable, err
 			return nil, fmt.Errorf("unknown column: %s", name)
 		}
 
-		if dt.cols[c].f != nil {
-			dt2.addColumn(name, colvals{f: make([]float64, len(indices))})
-		} else {
-			dt2.addColumn(name, colvals{s: make([]string, len(indices))})
-		}
+		dt2.addColumn(name, dt.cols[c].pick(indices))
 	}
 
-	for i, idx := range indices {
-		for _, name := range names {
-			c := dt.colorder[name]
-			c2 := dt2.colorder[name]
-			if dt.cols[c].f != nil {
-				dt2.cols[c2].f[i] = dt.cols[c].f[idx]
-			} else {
-				dt2.cols[c2].s[i] = dt.cols[c].s[idx]
-			}
+	if dt.ids != nil {
+		dt2.ids = make([]int64, len(indices))
+		for i, idx := range indices {
+			dt2.ids[i] = dt.ids[idx]
 		}
+		dt2.nextID = dt.nextID
 	}
 
 	return dt2, nil
 }
 
+// CopyColumnsFrom copies the named columns of src into the table, replacing
+// any existing columns with the same names. Each column is copied with a
+// single allocation. The columns are matched to rows by position, so src must
+// have the same number of rows as the table unless the table has no columns.
+// No columns are copied if any name is unknown or the lengths differ. If a key
+// column is replaced the table is sorted again.
+func (dt *DataTable) CopyColumnsFrom(src *DataTable, names []string) error {
+	for _, name := range names {
+		if _, exists := src.colorder[name]; !exists {
+			return fmt.Errorf("unknown column: %s", name)
+		}
+	}
+	if len(dt.cols) != 0 && src.Len() != dt.Len() {
+		return fmt.Errorf("%w: got %d rows, wanted %d", ErrInvalidColumnLength, src.Len(), dt.Len())
+	}
+
+	resort := false
+	for _, name := range names {
+		c := src.colorder[name]
+		dt.addColumn(name, src.cols[c].clone())
+		resort = resort || dt.isKeyCol(dt.colorder[name])
+	}
+	if resort {
+		sort.Stable(dt)
+		dt.markAllDirty()
+	}
+	dt.mutated()
+	return nil
+}
+
 // Unique returns a new data table containing only the
 // unique rows from dt. The returned data table will
 // contain the same number of columns in the same order
@@ -808,15 +1829,12 @@ func (dt *DataTable) Unique() *DataTable {
 	// remove any sort keys and sort in natural order
 	dt.keys = []int{}
 	sort.Stable(dt)
+	dt.markAllDirty()
 
 	for c := range dt.cols {
 		dt2.colnames = append(dt2.colnames, dt.colnames[c])
 		dt2.colorder[dt.colnames[c]] = c
-		if dt.cols[c].f != nil {
-			dt2.cols = append(dt2.cols, colvals{f: []float64{dt.cols[c].f[0]}})
-		} else {
-			dt2.cols = append(dt2.cols, colvals{s: []string{dt.cols[c].s[0]}})
-		}
+		dt2.cols = append(dt2.cols, dt.cols[c].pick([]int{0}))
 	}
 
 rowloop:
@@ -856,11 +1874,7 @@ func (dt *DataTable) CloneEmpty() *DataTable {
 	}
 
 	for c := range dt.cols {
-		if dt.cols[c].f != nil {
-			dt2.AddColumn(dt.colnames[c], []float64{})
-		} else {
-			dt2.AddStringColumn(dt.colnames[c], []string{})
-		}
+		dt2.addColumn(dt.colnames[c], dt.cols[c].pick([]int{}))
 	}
 
 	return dt2
@@ -879,26 +1893,25 @@ func (dt *DataTable) Clone() *DataTable {
 // same order
 func copyRow(dt, dt2 *DataTable, n int) {
 	for c := range dt.cols {
-		if dt.cols[c].f != nil {
-			dt2.cols[c].f = append(dt2.cols[c].f, dt.cols[c].f[n])
-		} else {
-			dt2.cols[c].s = append(dt2.cols[c].s, dt.cols[c].s[n])
-		}
+		dt2.cols[c].appendValue(dt.cols[c], n)
 	}
 }
 
-// AppendRow appends the data in row to the data table.
+// AppendRow appends the data in row to the data table. A nil value appends
+// a null value to its column.
 func (dt *DataTable) AppendRow(row []interface{}) error {
 	if len(row) != dt.N() {
 		return ErrWrongNumberOfColumns
 	}
 	for c := range dt.cols {
-		if dt.isFloatCol(c) {
+		if row[c] == nil {
+			dt.cols[c].appendNull()
+		} else if dt.isFloatCol(c) {
 			v, ok := row[c].(float64)
 			if !ok {
 				return ErrMismatchedColumnTypes
 			}
-			dt.cols[c].f = append(dt.cols[c].f, v)
+			dt.cols[c].appendFloat(v)
 		} else {
 			v, ok := row[c].(string)
 			if !ok {
@@ -907,19 +1920,74 @@ func (dt *DataTable) AppendRow(row []interface{}) error {
 			dt.cols[c].s = append(dt.cols[c].s, v)
 		}
 	}
+	if err := dt.checkPrimaryKey(dt.Len() - 1); err != nil {
+		dt.truncate(dt.Len() - 1)
+		return err
+	}
+	dt.syncIDs()
+	dt.markAllDirty()
+	dt.mutated()
 	return nil
 }
 
+// AppendRowNamed appends a row of data whose values are given in the same
+// order as names, which may list the table's columns in any order. An error
+// wrapping ErrWrongNumberOfColumns and listing the missing and unknown
+// column names is returned if names does not match the table's columns.
+func (dt *DataTable) AppendRowNamed(names []string, values []interface{}) error {
+	if len(names) != len(values) {
+		return fmt.Errorf("%d names given for %d values", len(names), len(values))
+	}
+
+	row := make([]interface{}, dt.N())
+	seen := make([]bool, dt.N())
+	var unknown []string
+	for i, name := range names {
+		c, exists := dt.colorder[name]
+		if !exists {
+			unknown = append(unknown, name)
+			continue
+		}
+		if seen[c] {
+			return fmt.Errorf("duplicate column: %s", name)
+		}
+		seen[c] = true
+		row[c] = values[i]
+	}
+
+	var missing []string
+	for c, ok := range seen {
+		if !ok {
+			missing = append(missing, dt.colnames[c])
+		}
+	}
+
+	if len(missing) > 0 || len(unknown) > 0 {
+		return fmt.Errorf("%w: missing %v, unknown %v", ErrWrongNumberOfColumns, missing, unknown)
+	}
+	return dt.AppendRow(row)
+}
+
 func (dt *DataTable) isFloatCol(c int) bool {
 	return dt.cols[c].f != nil
 }
 
-// CSV writes the datatable as CSV
+// CSV writes the datatable as CSV. Numeric values are written according to
+// the table's float formats, if any. Values of decimal columns without a
+// column format are written exactly. Null values are written as empty
+// fields.
 func (dt *DataTable) CSV(w io.Writer) error {
 	cw := csv.NewWriter(w)
-	for _, row := range dt.RawRows(true) {
+	for r, row := range dt.RawRows(true) {
 		sw := make([]string, len(row))
 		for i := range row {
+			if r > 0 && dt.cols[i].isNull(r-1) {
+				continue
+			}
+			if v, ok := row[i].(float64); ok {
+				sw[i] = dt.formatFloat(i, r-1, v)
+				continue
+			}
 			sw[i] = fmt.Sprintf("%v", row[i])
 		}
 		err := cw.Write(sw)
@@ -947,72 +2015,84 @@ func (fn AggregatorFunc) Aggregate(rg RowGroup) float64 {
 
 // Sum returns an Aggregator that sums a numeric column in a group of rows.
 func Sum(name string) Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		r := 0.0
 		for rg.Next() {
+			if isNullIn(rg, name) {
+				continue
+			}
 			v, _ := rg.FloatValue(name)
 			r += v
 		}
 		return r
-	})
+	}), callExpr("Sum", name), name)
 }
 
 // Max returns an Aggregator that finds the maximum value of a numeric column in a group of rows.
 func Max(name string) Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		max := 0.0
 		for rg.Next() {
+			if isNullIn(rg, name) {
+				continue
+			}
 			v, _ := rg.FloatValue(name)
 			if v > max {
 				max = v
 			}
 		}
 		return max
-	})
+	}), callExpr("Max", name), name)
 }
 
 // Min returns an Aggregator that finds the minimum value of a numeric column in a group of rows.
 func Min(name string) Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		min := 0.0
 		for rg.Next() {
+			if isNullIn(rg, name) {
+				continue
+			}
 			v, _ := rg.FloatValue(name)
 			if v < min {
 				min = v
 			}
 		}
 		return min
-	})
+	}), callExpr("Min", name), name)
 }
 
 // Count returns an Aggregator that finds the count of numeric values in a group of rows.
 func Count() Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		count := 0
 		for rg.Next() {
 			count++
 		}
 		return float64(count)
-	})
+	}), callExpr("Count"))
 }
 
 // Mean returns an Aggregator that finds the mean value of a numeric column in a group of rows.
 func Mean(name string) Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		sum := 0.0
 		count := 0
 		for rg.Next() {
+			if isNullIn(rg, name) {
+				continue
+			}
 			v, _ := rg.FloatValue(name)
 			sum += v
 			count++
 		}
 		return sum / float64(count)
-	})
+	}), callExpr("Mean", name), name)
 }
 
 // Variance returns an Aggregator that finds the variance of a numeric column in a group of rows.
 func Variance(name string) Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		// Based on MeanVariance from github.com/gonum/stat
 		// This uses the corrected two-pass algorithm (1.7), from "Algorithms for computing
 		// the sample variance: Analysis and recommendations" by Chan, Tony F., Gene H. Golub,
@@ -1020,6 +2100,9 @@ func Variance(name string) Aggregator {
 		sum := 0.0
 		count := 0
 		for rg.Next() {
+			if isNullIn(rg, name) {
+				continue
+			}
 			v, _ := rg.FloatValue(name)
 			sum += v
 			count++
@@ -1032,41 +2115,86 @@ func Variance(name string) Aggregator {
 		)
 		rg.Reset()
 		for rg.Next() {
+			if isNullIn(rg, name) {
+				continue
+			}
 			v, _ := rg.FloatValue(name)
 			d := v - mean
 			ss += d * d
 			compensation += d
 		}
 		return (ss - compensation*compensation/float64(count)) / float64(count-1)
-	})
+	}), callExpr("Variance", name), name)
+}
+
+// CountIf returns an Aggregator that counts the rows in a group that match m.
+func CountIf(m Matcher) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		count := 0
+		for rg.Next() {
+			row, ok := rowRefOf(rg)
+			if !ok {
+				return math.NaN()
+			}
+			if m.Match(row) {
+				count++
+			}
+		}
+		return float64(count)
+	}), callExpr("CountIf", m))
+}
+
+// SumIf returns an Aggregator that sums a numeric column over the rows in a group
+// that match m.
+func SumIf(name string, m Matcher) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		r := 0.0
+		for rg.Next() {
+			row, ok := rowRefOf(rg)
+			if !ok {
+				return math.NaN()
+			}
+			if m.Match(row) && !isNullIn(rg, name) {
+				v, _ := rg.FloatValue(name)
+				r += v
+			}
+		}
+		return r
+	}), callExpr("SumIf", name, m), name)
 }
 
 func RatioOfSums(a, b string) Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		suma, sumb := 0.0, 0.0
 		for rg.Next() {
-			va, _ := rg.FloatValue(a)
-			suma += va
-
-			vb, _ := rg.FloatValue(b)
-			sumb += vb
+			if !isNullIn(rg, a) {
+				va, _ := rg.FloatValue(a)
+				suma += va
+			}
+			if !isNullIn(rg, b) {
+				vb, _ := rg.FloatValue(b)
+				sumb += vb
+			}
 		}
 		return suma / sumb
-	})
+	}), callExpr("RatioOfSums", a, b), a, b)
 }
 
 func DifferenceOfSums(a, b string) Aggregator {
-	return AggregatorFunc(func(rg RowGroup) float64 {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
 		suma, sumb := 0.0, 0.0
 		for rg.Next() {
-			va, _ := rg.FloatValue(a)
-			suma += va
-
-			vb, _ := rg.FloatValue(b)
-			sumb += vb
+			if !isNullIn(rg, a) {
+				va, _ := rg.FloatValue(a)
+				suma += va
+			}
+			if !isNullIn(rg, b) {
+				vb, _ := rg.FloatValue(b)
+				sumb += vb
+			}
 		}
 		return suma - sumb
-	})
+	}), callExpr("DifferenceOfSums", a, b), a, b)
 }
 
 // A Matcher tests a single row of data to determine
@@ -1085,17 +2213,16 @@ func (fn MatcherFunc) Match(row RowRef) bool {
 // NumericColumnMatcher returns a Matcher that tests the value of
 // a single column in a row of data against the numeric function fn.
 func NumericColumnMatcher(name string, fn func(float64) bool) Matcher {
-	return MatcherFunc(func(row RowRef) bool {
-		if v, exists := row.FloatValue(name); exists {
-			return fn(v)
-		}
-		return false
-	})
+	return rangeMatcher{name: name, fn: fn}
 }
 
 // IsZero returns a Matcher that tests whether the named column is zero or not
 func IsZero(name string) Matcher {
-	return NumericColumnMatcher(name, func(f float64) bool { return f == 0.0 })
+	return rangeMatcher{
+		name:   name,
+		fn:     func(f float64) bool { return f == 0.0 },
+		within: func(min, max float64) bool { return min <= 0 && max >= 0 },
+	}
 }
 
 // IsNan returns a Matcher that tests whether the named column is NaN or not
@@ -1111,17 +2238,29 @@ func IsInf(name string) Matcher {
 
 // GreaterThan returns a Matcher that tests whether the named column is greater than v or not
 func GreaterThan(name string, v float64) Matcher {
-	return NumericColumnMatcher(name, func(f float64) bool { return f > v })
+	return rangeMatcher{
+		name:   name,
+		fn:     func(f float64) bool { return f > v },
+		within: func(min, max float64) bool { return max > v },
+	}
 }
 
 // LessThan returns a Matcher that tests whether the named column is less than v or not
 func LessThan(name string, v float64) Matcher {
-	return NumericColumnMatcher(name, func(f float64) bool { return f < v })
+	return rangeMatcher{
+		name:   name,
+		fn:     func(f float64) bool { return f < v },
+		within: func(min, max float64) bool { return min < v },
+	}
 }
 
 // CloselyEqual returns a Matcher that tests whether the named column is equal to v within the range +/- e
 func CloselyEqual(name string, v float64, e float64) Matcher {
-	return NumericColumnMatcher(name, func(f float64) bool { return f == v || math.Abs(f-v) <= e })
+	return rangeMatcher{
+		name:   name,
+		fn:     func(f float64) bool { return f == v || math.Abs(f-v) <= e },
+		within: func(min, max float64) bool { return max >= v-e && min <= v+e },
+	}
 }
 
 // StringColumnMatcher returns a Matcher that tests the value of
@@ -1180,7 +2319,131 @@ func Zero() Calculator {
 
 // Constant returns a Calculator that always returns the constant value v
 func Constant(v float64) Calculator {
-	return CalculatorFunc(func(row RowRef) float64 { return v })
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 { return v }), callExpr("Constant", v))
+}
+
+// Col returns a Calculator that returns the value of the named numeric column.
+// NaN is returned if the column does not exist or is not numeric.
+func Col(name string) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 {
+		if v, exists := row.FloatValue(name); exists {
+			return v
+		}
+		return math.NaN()
+	}), callExpr("Col", name), name)
+}
+
+// Lit returns a Calculator that always returns the literal value v. It is
+// equivalent to Constant but reads more naturally when composing expressions
+// such as Mul(Col("price"), Sub(Lit(1), Col("discount"))).
+func Lit(v float64) Calculator {
+	return describeCalculator(Constant(v), callExpr("Lit", v))
+}
+
+// Add returns a Calculator that adds the results of a and b.
+func Add(a, b Calculator) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 { return a.Calculate(row) + b.Calculate(row) }), callExpr("Add", a, b), calcSources(a, b)...)
+}
+
+// Sub returns a Calculator that subtracts the result of b from the result of a.
+func Sub(a, b Calculator) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 { return a.Calculate(row) - b.Calculate(row) }), callExpr("Sub", a, b), calcSources(a, b)...)
+}
+
+// Mul returns a Calculator that multiplies the results of a and b.
+func Mul(a, b Calculator) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 { return a.Calculate(row) * b.Calculate(row) }), callExpr("Mul", a, b), calcSources(a, b)...)
+}
+
+// Div returns a Calculator that divides the result of a by the result of b.
+// Division by zero follows IEEE 754 rules, yielding an infinity or NaN.
+func Div(a, b Calculator) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 { return a.Calculate(row) / b.Calculate(row) }), callExpr("Div", a, b), calcSources(a, b)...)
+}
+
+// Pow returns a Calculator that raises the result of a to the power of the result of b.
+func Pow(a, b Calculator) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 { return math.Pow(a.Calculate(row), b.Calculate(row)) }), callExpr("Pow", a, b), calcSources(a, b)...)
+}
+
+// A StringCalculator performs a calculation on a single row of data
+// producing a text value.
+type StringCalculator interface {
+	CalculateString(row RowRef) string
+}
+
+// StringCalculatorFunc adapts a function to a StringCalculator interface
+type StringCalculatorFunc func(row RowRef) string
+
+func (fn StringCalculatorFunc) CalculateString(row RowRef) string {
+	return fn(row)
+}
+
+// ConcatCols returns a StringCalculator that joins the values of the named
+// columns using sep. Numeric values are formatted in the same way as CSV output.
+// Columns that do not exist contribute an empty string.
+func ConcatCols(sep string, cols ...string) StringCalculator {
+	return StringCalculatorFunc(func(row RowRef) string {
+		parts := make([]string, len(cols))
+		for i, name := range cols {
+			if v, exists := row.Value(name); exists {
+				parts[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		return strings.Join(parts, sep)
+	})
+}
+
+// SubstrCol returns a StringCalculator that returns the characters of the named
+// string column from rune offset i up to but not including rune offset j.
+// Offsets are clamped to the length of the value.
+func SubstrCol(name string, i, j int) StringCalculator {
+	return StringCalculatorFunc(func(row RowRef) string {
+		v, _ := row.StringValue(name)
+		r := []rune(v)
+		start, end := i, j
+		if start < 0 {
+			start = 0
+		}
+		if end > len(r) {
+			end = len(r)
+		}
+		if start >= end {
+			return ""
+		}
+		return string(r[start:end])
+	})
+}
+
+// UpperCol returns a StringCalculator that returns the value of the named
+// string column converted to upper case.
+func UpperCol(name string) StringCalculator {
+	return StringCalculatorFunc(func(row RowRef) string {
+		v, _ := row.StringValue(name)
+		return strings.ToUpper(v)
+	})
+}
+
+// LowerCol returns a StringCalculator that returns the value of the named
+// string column converted to lower case.
+func LowerCol(name string) StringCalculator {
+	return StringCalculatorFunc(func(row RowRef) string {
+		v, _ := row.StringValue(name)
+		return strings.ToLower(v)
+	})
+}
+
+// FormatFloatCol returns a StringCalculator that formats the value of the named
+// numeric column using layout, which is a fmt verb such as "%.2f" or "%08.3f".
+// An empty string is returned if the column does not exist or is not numeric.
+func FormatFloatCol(name string, layout string) StringCalculator {
+	return StringCalculatorFunc(func(row RowRef) string {
+		v, exists := row.FloatValue(name)
+		if !exists {
+			return ""
+		}
+		return fmt.Sprintf(layout, v)
+	})
 }
 
 // A Grouper performs an action given a group of rows.
@@ -1211,19 +2474,58 @@ func fillSeq(n int) []int {
 	return ret
 }
 
+// fillRange returns the integers from start up to end.
+func fillRange(start, end int) []int {
+	ret := make([]int, end-start)
+	for i := range ret {
+		ret[i] = start + i
+	}
+	return ret
+}
+
 // A Valuer can get the value of a column in
 // a particular context
 type Valuer interface {
 	Value(name string) (interface{}, bool)
 	FloatValue(name string) (float64, bool)
 	StringValue(name string) (string, bool)
+	IntValue(name string) (int64, bool)
 }
 
+// A RowGroup iterates over a group of rows, such as those passed to an
+// Aggregator. The row groups of this package also have RowRef, IsNull and
+// TimeValue methods, which are not part of the interface so that other
+// implementations need not provide them. Aggregators use them when a group
+// has them: groups without IsNull have no null values, and aggregators that
+// need row references, such as CountIf, return NaN for groups without RowRef.
 type RowGroup interface {
 	Valuer
 	Reset()
 	RowIndex() int
 	Next() bool
+
+	// Materialize returns a new data table containing copies of the rows
+	// in the group, in the group's order, with no keys set. It does not
+	// affect the current position of the group's iteration.
+	Materialize() *DataTable
+}
+
+// rowRefOf returns a reference to the current row of rg, or false if rg does
+// not provide one.
+func rowRefOf(rg RowGroup) (RowRef, bool) {
+	if r, ok := rg.(interface{ RowRef() RowRef }); ok {
+		return r.RowRef(), true
+	}
+	return RowRef{}, false
+}
+
+// isNullIn reports whether the value of the named column is null in v, which
+// has no null values if it does not have an IsNull method.
+func isNullIn(v Valuer, name string) bool {
+	if n, ok := v.(interface{ IsNull(name string) bool }); ok {
+		return n.IsNull(name)
+	}
+	return false
 }
 
 type StaticRowGroup struct {
@@ -1243,6 +2545,13 @@ func (r *StaticRowGroup) RowIndex() int {
 	return r.indices[r.offset-1]
 }
 
+// RowRef returns a reference to the current row in the row group,
+// suitable for passing to a Matcher or Calculator. It is an error if
+// this is called before calling Next and the function will panic.
+func (r *StaticRowGroup) RowRef() RowRef {
+	return RowRef{index: r.indices[r.offset-1], dt: r.dt}
+}
+
 func (r *StaticRowGroup) Next() bool {
 	r.offset++
 	return r.offset <= len(r.indices)
@@ -1275,6 +2584,30 @@ func (r *StaticRowGroup) StringValue(name string) (string, bool) {
 	return "", false
 }
 
+func (r *StaticRowGroup) IntValue(name string) (int64, bool) {
+	if c, exists := r.dt.colorder[name]; exists {
+		return r.dt.intValue(c, r.indices[r.offset-1])
+	}
+	return 0, false
+}
+
+func (r *StaticRowGroup) IsNull(name string) bool {
+	c, exists := r.dt.colorder[name]
+	return exists && r.dt.cols[c].isNull(r.indices[r.offset-1])
+}
+
+func (r *StaticRowGroup) TimeValue(name string) (time.Time, bool) {
+	if c, exists := r.dt.colorder[name]; exists {
+		return r.dt.timeAt(c, r.indices[r.offset-1])
+	}
+	return time.Time{}, false
+}
+
+func (r *StaticRowGroup) Materialize() *DataTable {
+	dt, _ := r.dt.SelectIndex(r.dt.Names(), r.indices)
+	return dt
+}
+
 // Where applies a matcher to the rows in this row group, returning a new
 // row group that contains only the rows that matched. It does not affect
 // the current position of r's iteration.
@@ -1310,6 +2643,10 @@ func (m *MatchingRowGroup) RowIndex() int {
 	return m.next - 1
 }
 
+func (m *MatchingRowGroup) RowRef() RowRef {
+	return RowRef{index: m.next - 1, dt: m.dt}
+}
+
 func (m *MatchingRowGroup) Next() bool {
 	rr := RowRef{dt: m.dt}
 	for rr.index = m.next; rr.index < m.dt.Len() && rr.index < m.start+m.length; rr.index++ {
@@ -1321,6 +2658,18 @@ func (m *MatchingRowGroup) Next() bool {
 	return false
 }
 
+func (m *MatchingRowGroup) Materialize() *DataTable {
+	var indices []int
+	rr := RowRef{dt: m.dt}
+	for rr.index = m.start; rr.index < m.dt.Len() && rr.index < m.start+m.length; rr.index++ {
+		if m.matcher.Match(rr) {
+			indices = append(indices, rr.index)
+		}
+	}
+	dt, _ := m.dt.SelectIndex(m.dt.Names(), indices)
+	return dt
+}
+
 func (m *MatchingRowGroup) Value(name string) (interface{}, bool) {
 	if c, exists := m.dt.colorder[name]; exists {
 		if m.dt.cols[c].f != nil {
@@ -1345,6 +2694,25 @@ func (m *MatchingRowGroup) StringValue(name string) (string, bool) {
 	return "", false
 }
 
+func (m *MatchingRowGroup) IntValue(name string) (int64, bool) {
+	if c, exists := m.dt.colorder[name]; exists {
+		return m.dt.intValue(c, m.next-1)
+	}
+	return 0, false
+}
+
+func (m *MatchingRowGroup) IsNull(name string) bool {
+	c, exists := m.dt.colorder[name]
+	return exists && m.dt.cols[c].isNull(m.next-1)
+}
+
+func (m *MatchingRowGroup) TimeValue(name string) (time.Time, bool) {
+	if c, exists := m.dt.colorder[name]; exists {
+		return m.dt.timeAt(c, m.next-1)
+	}
+	return time.Time{}, false
+}
+
 type RowRef struct {
 	index int
 	dt    *DataTable
@@ -1374,6 +2742,25 @@ func (r *RowRef) StringValue(name string) (string, bool) {
 	return "", false
 }
 
+func (r *RowRef) IntValue(name string) (int64, bool) {
+	if c, exists := r.dt.colorder[name]; exists {
+		return r.dt.intValue(c, r.index)
+	}
+	return 0, false
+}
+
+func (r *RowRef) IsNull(name string) bool {
+	c, exists := r.dt.colorder[name]
+	return exists && r.dt.cols[c].isNull(r.index)
+}
+
+func (r *RowRef) TimeValue(name string) (time.Time, bool) {
+	if c, exists := r.dt.colorder[name]; exists {
+		return r.dt.timeAt(c, r.index)
+	}
+	return time.Time{}, false
+}
+
 type RowMap map[string]interface{}
 
 func (r RowMap) Value(name string) (interface{}, bool) {
@@ -1407,3 +2794,21 @@ func (r RowMap) StringValue(name string) (string, bool) {
 	}
 	return "", false
 }
+
+func (r RowMap) IntValue(name string) (int64, bool) {
+	if r == nil {
+		return 0, false
+	}
+	if v, ok := r[name]; ok {
+		if vi, ok := v.(int64); ok {
+			return vi, true
+		}
+	}
+	return 0, false
+}
+
+// IsNull reports whether the map holds a nil value for the named column.
+func (r RowMap) IsNull(name string) bool {
+	v, exists := r[name]
+	return exists && v == nil
+}
@@ -2,11 +2,13 @@ package datatable
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -323,6 +325,65 @@ func TestAggregateWhere(t *testing.T) {
 	}
 }
 
+func TestAggregateSplit(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"n", "n", "s", "s", "s"})
+	dt.AddStringColumn("channel", []string{"web", "store", "web", "web", "phone"})
+	dt.AddColumn("sales", []float64{1, 2, 3, 4, 5})
+	dt.SetKeys("region")
+
+	dt.AggregateSplit("sum_", Sum("sales"), map[string]Matcher{
+		"web":   IsEqualString("channel", "web"),
+		"store": IsEqualString("channel", "store"),
+		"other": Not(MultiColumnMatcher(map[string]string{"channel": "web"})),
+	})
+
+	expectedNames := []string{"region", "channel", "sales", "sum_other", "sum_store", "sum_web"}
+	if !reflect.DeepEqual(dt.Names(), expectedNames) {
+		t.Errorf("got %v, wanted %v", dt.Names(), expectedNames)
+	}
+
+	expectedRows := [][]interface{}{
+		{"n", "web", 1.0, 2.0, 2.0, 1.0},
+		{"n", "store", 2.0, 2.0, 2.0, 1.0},
+		{"s", "web", 3.0, 5.0, 0.0, 7.0},
+		{"s", "web", 4.0, 5.0, 0.0, 7.0},
+		{"s", "phone", 5.0, 5.0, 0.0, 7.0},
+	}
+
+	rows := dt.RawRows(false)
+	if !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
+
+func TestAggregateMany(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"s", "n", "s", "n", "s"})
+	dt.AddColumn("sales", []float64{1, 2, 3, 4, 5})
+	dt.SetKeys("region")
+
+	if err := dt.AggregateMany([]string{"total", "mean", "count"}, []Aggregator{Sum("sales"), Mean("sales"), Count()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"n", 2.0, 6.0, 3.0, 2.0},
+		{"n", 4.0, 6.0, 3.0, 2.0},
+		{"s", 1.0, 9.0, 3.0, 3.0},
+		{"s", 3.0, 9.0, 3.0, 3.0},
+		{"s", 5.0, 9.0, 3.0, 3.0},
+	}
+	rows := dt.RawRows(false)
+	if !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	if err := dt.AggregateMany([]string{"x"}, nil); err == nil {
+		t.Errorf("got no error for mismatched lengths")
+	}
+}
+
 func equivalentFloats(a, b float64) bool {
 	if math.IsNaN(a) && math.IsNaN(b) {
 		return true
@@ -563,6 +624,105 @@ func TestCalcWhere(t *testing.T) {
 	}
 }
 
+func TestCalcCombinators(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("price", []float64{10, 20, 30})
+	dt.AddColumn("discount", []float64{0, 0.5, 0.1})
+	dt.AddStringColumn("name", []string{"a", "b", "c"})
+
+	testCases := []struct {
+		calc     Calculator
+		expected []float64
+	}{
+		{
+			calc:     Mul(Col("price"), Sub(Lit(1), Col("discount"))),
+			expected: []float64{10, 10, 27},
+		},
+		{
+			calc:     Add(Col("price"), Lit(1)),
+			expected: []float64{11, 21, 31},
+		},
+		{
+			calc:     Div(Col("discount"), Col("price")),
+			expected: []float64{0, 0.025, 0.1 / 30},
+		},
+		{
+			calc:     Pow(Col("price"), Lit(2)),
+			expected: []float64{100, 400, 900},
+		},
+		{ // non-numeric columns yield NaN
+			calc:     Add(Col("name"), Lit(1)),
+			expected: []float64{math.NaN(), math.NaN(), math.NaN()},
+		},
+	}
+
+	for i, tc := range testCases {
+		col := fillNaN(dt.Len())
+		dt.CalcIndexFill(col, tc.calc, fillSeq(dt.Len()))
+		if !equivalentFloatSlices(col, tc.expected) {
+			t.Errorf("%d: got %v, wanted %v", i, col, tc.expected)
+		}
+	}
+}
+
+func TestCalcString(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"North", "south", "Éast"})
+	dt.AddColumn("id", []float64{1, 22, 3.5})
+
+	testCases := []struct {
+		calc     StringCalculator
+		expected []string
+	}{
+		{
+			calc:     ConcatCols("-", "region", "id"),
+			expected: []string{"North-1", "south-22", "Éast-3.5"},
+		},
+		{
+			calc:     SubstrCol("region", 0, 2),
+			expected: []string{"No", "so", "Éa"},
+		},
+		{
+			calc:     SubstrCol("region", 3, 10),
+			expected: []string{"th", "th", "t"},
+		},
+		{
+			calc:     UpperCol("region"),
+			expected: []string{"NORTH", "SOUTH", "ÉAST"},
+		},
+		{
+			calc:     LowerCol("region"),
+			expected: []string{"north", "south", "éast"},
+		},
+		{
+			calc:     FormatFloatCol("id", "%05.1f"),
+			expected: []string{"001.0", "022.0", "003.5"},
+		},
+	}
+
+	for i, tc := range testCases {
+		dt.CalcString("calc", tc.calc)
+		c := dt.colorder["calc"]
+		if !reflect.DeepEqual(dt.cols[c].s, tc.expected) {
+			t.Errorf("%d: got %q, wanted %q", i, dt.cols[c].s, tc.expected)
+		}
+	}
+}
+
+func TestCalcStringWhere(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"north", "south", "east"})
+	dt.AddColumn("id", []float64{1, 2, 3})
+
+	dt.CalcStringWhere("label", UpperCol("region"), GreaterThan("id", 1))
+
+	expected := []string{"", "SOUTH", "EAST"}
+	c := dt.colorder["label"]
+	if !reflect.DeepEqual(dt.cols[c].s, expected) {
+		t.Errorf("got %q, wanted %q", dt.cols[c].s, expected)
+	}
+}
+
 func TestMatches(t *testing.T) {
 	dt := &DataTable{}
 	dt.AddColumn("c0", []float64{
@@ -588,6 +748,74 @@ func TestMatches(t *testing.T) {
 	}
 }
 
+func TestScalarWhere(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"n", "s", "n", "n", "s"})
+	dt.AddColumn("v", []float64{4, 10, math.NaN(), -2, 7})
+
+	north := IsEqualString("region", "n")
+	testCases := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"SumWhere", dt.SumWhere("v", north), 2},
+		{"MeanWhere", dt.MeanWhere("v", north), 1},
+		{"MinWhere", dt.MinWhere("v", north), -2},
+		{"MaxWhere", dt.MaxWhere("v", north), 4},
+		{"SumWhere no rows", dt.SumWhere("v", IsEqualString("region", "w")), 0},
+		{"MeanWhere no rows", dt.MeanWhere("v", IsEqualString("region", "w")), math.NaN()},
+		{"MaxWhere no rows", dt.MaxWhere("v", IsEqualString("region", "w")), math.NaN()},
+		{"SumWhere string column", dt.SumWhere("region", north), math.NaN()},
+		{"MinWhere unknown column", dt.MinWhere("x", north), math.NaN()},
+	}
+	for _, tc := range testCases {
+		if tc.got != tc.want && !(math.IsNaN(tc.got) && math.IsNaN(tc.want)) {
+			t.Errorf("%s: got %v, wanted %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestReduceWhereMulti(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("v", []float64{1, 2, 3, 4})
+
+	if got := dt.ReduceWhere(Sum("v"), GreaterThan("v", 2)); got != 7 {
+		t.Errorf("got ReduceWhere %v, wanted 7", got)
+	}
+	if got := dt.ReduceWhere(Count(), GreaterThan("v", 10)); got != 0 {
+		t.Errorf("got ReduceWhere %v for no matching rows, wanted 0", got)
+	}
+
+	got := dt.ReduceMulti(map[string]Aggregator{
+		"sum":   Sum("v"),
+		"mean":  Mean("v"),
+		"count": Count(),
+	})
+	want := map[string]float64{"sum": 10, "mean": 2.5, "count": 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got ReduceMulti %v, wanted %v", got, want)
+	}
+
+	// partial aggregators share a single scan, mixed with other aggregators
+	dt.SetNull("v", 0)
+	psum := PartialSum("v")
+	got = dt.ReduceMulti(map[string]Aggregator{
+		"sum":   psum,
+		"min":   PartialMin("v"),
+		"var":   PartialVariance("v"),
+		"count": PartialCount(),
+		"max":   Max("v"),
+	})
+	want = map[string]float64{"sum": 9, "min": 2, "var": 1, "count": 4, "max": 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got ReduceMulti %v, wanted %v", got, want)
+	}
+	if psum.Result() != 0 {
+		t.Errorf("ReduceMulti changed the partial state of an aggregator")
+	}
+}
+
 func TestCalcWhereEmptyTable(t *testing.T) {
 	dt := &DataTable{}
 
@@ -921,6 +1149,18 @@ func TestAggregate(t *testing.T) {
 			indices:  []int{1, 3, 5, 7},
 			expected: []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), 2, math.NaN(), 2, math.NaN()},
 		},
+
+		{ // compute count of rows in each group matching a condition
+			agg:      CountIf(GreaterThan("c3", 0)),
+			indices:  []int{0, 1, 2, 3, 4, 5, 6, 7, 8},
+			expected: []float64{1, 1, 1, 1, 1, 2, 2, 2, 0},
+		},
+
+		{ // compute sum of rows in each group matching a condition
+			agg:      SumIf("c2", IsZero("c3")),
+			indices:  []int{0, 1, 2, 3, 4, 5, 6, 7, 8},
+			expected: []float64{4, 4, 4, 5, 5, 7, 7, 7, 9},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -945,6 +1185,42 @@ var expectedCSV = `c1,c2
 4,9
 `
 
+// sliceRowGroup is a RowGroup over a slice of values of a single column that
+// has only the methods required by the interface.
+type sliceRowGroup struct {
+	name string
+	vals []float64
+	pos  int
+}
+
+func (g *sliceRowGroup) Reset()        { g.pos = 0 }
+func (g *sliceRowGroup) RowIndex() int { return g.pos - 1 }
+func (g *sliceRowGroup) Next() bool    { g.pos++; return g.pos <= len(g.vals) }
+
+func (g *sliceRowGroup) Value(name string) (interface{}, bool) { return g.FloatValue(name) }
+
+func (g *sliceRowGroup) FloatValue(name string) (float64, bool) {
+	if name != g.name {
+		return math.NaN(), false
+	}
+	return g.vals[g.pos-1], true
+}
+
+func (g *sliceRowGroup) StringValue(name string) (string, bool) { return "", false }
+func (g *sliceRowGroup) IntValue(name string) (int64, bool)     { return 0, false }
+func (g *sliceRowGroup) Materialize() *DataTable                { return nil }
+
+func TestCustomRowGroup(t *testing.T) {
+	rg := &sliceRowGroup{name: "v", vals: []float64{1, 2, 4}}
+	if got := Sum("v").Aggregate(rg); got != 7 {
+		t.Errorf("got sum %v, wanted 7", got)
+	}
+	rg.Reset()
+	if got := CountIf(GreaterThan("v", 1)).Aggregate(rg); !math.IsNaN(got) {
+		t.Errorf("got CountIf %v for a group without row references, wanted NaN", got)
+	}
+}
+
 func TestCSV(t *testing.T) {
 	dt := &DataTable{}
 	dt.AddColumn("c1", []float64{1, 1, 1, 2, 2, 3, 3, 3, 4})
@@ -1223,6 +1499,56 @@ func TestApplyWhere(t *testing.T) {
 	}
 }
 
+func TestApplyBreak(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("user", []string{"a", "a", "a", "a", "b", "b"})
+	dt.AddColumn("ts", []float64{0, 600, 4000, 4100, 4200, 4300})
+	dt.SetKeys("user", "ts")
+
+	// Sessions end after 30 minutes of inactivity or when the user changes
+	expected := [][]float64{{0, 600}, {4000, 4100}, {4200, 4300}}
+	actual := [][]float64{}
+	dt.ApplyBreak(GrouperFunc(func(rg RowGroup) {
+		session := []float64{}
+		for rg.Next() {
+			v, _ := rg.FloatValue("ts")
+			session = append(session, v)
+		}
+		actual = append(actual, session)
+	}), AnyBreak(Changed("user"), GapExceeds("ts", 1800)))
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %+v, wanted %+v", actual, expected)
+	}
+}
+
+func TestApplyRuns(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("state", []string{"idle", "idle", "busy", "idle", "busy", "busy"})
+	dt.AddColumn("seq", []float64{1, 2, 3, 4, 5, 6})
+
+	expected := [][]float64{{1, 2}, {3}, {4}, {5, 6}}
+	actual := [][]float64{}
+	err := dt.ApplyRuns("state", GrouperFunc(func(rg RowGroup) {
+		run := []float64{}
+		for rg.Next() {
+			v, _ := rg.FloatValue("seq")
+			run = append(run, v)
+		}
+		actual = append(actual, run)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %+v, wanted %+v", actual, expected)
+	}
+
+	if err := dt.ApplyRuns("nope", GrouperFunc(func(rg RowGroup) {})); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
 func doBenchmarkApplyWhere(dt *DataTable, m Matcher, b *testing.B) {
 	g := GrouperFunc(func(rg RowGroup) {})
 	b.ResetTimer()
@@ -1268,3 +1594,415 @@ func BenchmarkApplyWhereBigMedNumeric(b *testing.B) {
 func BenchmarkApplyWhereBigHighNumeric(b *testing.B) {
 	doBenchmarkApplyWhere(makeTable(3, 10000), GreaterThan("c0", 0.05), b)
 }
+
+func TestAppendRowNamed(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("name", []string{"a"})
+	dt.AddColumn("score", []float64{1})
+
+	if err := dt.AppendRowNamed([]string{"score", "name"}, []interface{}{2.0, "b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"a", 1.0},
+		{"b", 2.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	err := dt.AppendRowNamed([]string{"score", "extra"}, []interface{}{3.0, "x"})
+	if !errors.Is(err, ErrWrongNumberOfColumns) {
+		t.Errorf("got %v, wanted %v", err, ErrWrongNumberOfColumns)
+	}
+	if err == nil || !strings.Contains(err.Error(), "missing [name]") || !strings.Contains(err.Error(), "unknown [extra]") {
+		t.Errorf("error does not list missing and unknown columns: %v", err)
+	}
+
+	if err := dt.AppendRowNamed([]string{"score", "name"}, []interface{}{"c", 3.0}); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if dt.Len() != 2 {
+		t.Errorf("got %d rows, wanted 2", dt.Len())
+	}
+}
+
+func TestSetValues(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{1, 2, 3, 4})
+	dt.AddStringColumn("c1", []string{"a", "b", "c", "d"})
+
+	if err := dt.SetFloatValues("c0", []int{3, 0}, []float64{40, 10}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.SetStringValues("c1", []int{1}, []string{"B"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{10.0, "a"},
+		{2.0, "B"},
+		{3.0, "c"},
+		{40.0, "d"},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	// no values are changed if any row is out of range
+	if err := dt.SetFloatValues("c0", []int{1, 4}, []float64{0, 0}); !errors.Is(err, ErrRowOutOfRange) {
+		t.Errorf("got %v, wanted %v", err, ErrRowOutOfRange)
+	}
+	if err := dt.SetFloatValues("c1", []int{1}, []float64{0}); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if err := dt.SetStringValues("c1", []int{1, 2}, []string{"x"}); err == nil {
+		t.Errorf("got no error for mismatched lengths")
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
+
+func TestBoundsChecks(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{1, 2, 3})
+
+	for _, n := range []int{-1, 3} {
+		if err := dt.CheckRow(n); !errors.Is(err, ErrRowOutOfRange) {
+			t.Errorf("CheckRow(%d): got %v, wanted %v", n, err, ErrRowOutOfRange)
+		}
+		if err := dt.SetFloatValue("c0", n, 1); !errors.Is(err, ErrRowOutOfRange) {
+			t.Errorf("SetFloatValue(%d): got %v, wanted %v", n, err, ErrRowOutOfRange)
+		}
+		if _, ok := dt.Row(n); ok {
+			t.Errorf("Row(%d): got true, wanted false", n)
+		}
+		if _, ok := dt.RowRef(n); ok {
+			t.Errorf("RowRef(%d): got true, wanted false", n)
+		}
+		if _, ok := dt.RowMap(n); ok {
+			t.Errorf("RowMap(%d): got true, wanted false", n)
+		}
+	}
+	if err := dt.CheckRow(2); err != nil {
+		t.Errorf("CheckRow(2): got %v, wanted no error", err)
+	}
+}
+
+func TestStrictBounds(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{1, 2, 3})
+	dt.SetStrictBounds(true)
+
+	if _, ok := dt.Row(2); !ok {
+		t.Errorf("Row(2): got false, wanted true")
+	}
+
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || !errors.Is(err, ErrRowOutOfRange) {
+			t.Errorf("got panic %v, wanted %v", r, ErrRowOutOfRange)
+		}
+	}()
+	dt.RowMap(3)
+}
+
+func TestCopyColumnsFrom(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{1, 2})
+
+	src := &DataTable{}
+	src.AddStringColumn("c0", []string{"a", "b"})
+	src.AddColumn("c1", []float64{3, 4})
+
+	if err := dt.CopyColumnsFrom(src, []string{"c1", "c0"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"c0", "c1"},
+		{"a", 3.0},
+		{"b", 4.0},
+	}
+	if rows := dt.RawRows(true); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	// the copy does not share storage with the source
+	src.SetFloatValue("c1", 0, 99)
+	if rows := dt.RawRows(true); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v after changing source, wanted %+v", rows, expected)
+	}
+
+	if err := dt.CopyColumnsFrom(src, []string{"nope"}); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+	short := &DataTable{}
+	short.AddColumn("c2", []float64{1})
+	if err := dt.CopyColumnsFrom(short, []string{"c2"}); !errors.Is(err, ErrInvalidColumnLength) {
+		t.Errorf("got %v, wanted %v", err, ErrInvalidColumnLength)
+	}
+}
+
+func TestAppendDedup(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("id", []string{"a", "b"})
+	dt.AddColumn("v", []float64{1, 2})
+	dt.SetKeys("id")
+
+	drop := &DataTable{}
+	drop.AddStringColumn("id", []string{"c", "b", "a", "c", "d"})
+	drop.AddColumn("v", []float64{3, 20, 10, 30, 4})
+
+	n, err := dt.AppendDedup(drop, []string{"id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d rows appended, wanted 2", n)
+	}
+
+	// appending the same data again has no effect
+	if n, _ := dt.AppendDedup(drop, []string{"id"}); n != 0 {
+		t.Errorf("got %d rows appended on second append, wanted 0", n)
+	}
+
+	expected := [][]interface{}{
+		{"a", 1.0},
+		{"b", 2.0},
+		{"c", 3.0},
+		{"d", 4.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	if _, err := dt.AppendDedup(drop, []string{"v", "nope"}); err == nil {
+		t.Errorf("got no error for unknown key column")
+	}
+}
+
+func TestSetKeyComparator(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("name", []string{"b", "A", "a", "B"})
+	dt.AddColumn("v", []float64{1, 2, 3, 4})
+	dt.SetKeys("name")
+
+	caseless := func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }
+	if err := dt.SetKeyComparator("name", caseless); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dt.Aggregate("total", Sum("v"))
+
+	expected := [][]interface{}{
+		{"A", 2.0, 5.0},
+		{"a", 3.0, 5.0},
+		{"B", 4.0, 5.0},
+		{"b", 1.0, 5.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	if err := dt.SetKeyComparator("v", caseless); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+}
+
+func TestNaturalCompare(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"item2", "item10", -1},
+		{"item10", "item2", 1},
+		{"item10", "item10", 0},
+		{"a1b2", "a1b10", -1},
+		{"file", "file1", -1},
+		{"file01", "file1", -1},
+		{"file1", "file01", 1},
+		{"abc", "abd", -1},
+		{"10", "9", 1},
+	}
+	for _, tc := range testCases {
+		if got := NaturalCompare(tc.a, tc.b); got != tc.expected {
+			t.Errorf("NaturalCompare(%q, %q): got %d, wanted %d", tc.a, tc.b, got, tc.expected)
+		}
+	}
+
+	dt := &DataTable{}
+	dt.AddStringColumn("f", []string{"f10", "f2", "f1"})
+	dt.SetKeys("f")
+	dt.SetKeyComparator("f", NaturalCompare)
+	expected := [][]interface{}{{"f1"}, {"f2"}, {"f10"}}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+}
+
+func TestIsSortedBy(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("a", []string{"x", "x", "y"})
+	dt.AddColumn("b", []float64{2, 1, 0})
+
+	if !dt.IsSortedBy("a") {
+		t.Errorf("IsSortedBy(a): got false, wanted true")
+	}
+	if dt.IsSortedBy("a", "b") {
+		t.Errorf("IsSortedBy(a, b): got true, wanted false")
+	}
+	if dt.IsSortedBy("nope") {
+		t.Errorf("IsSortedBy(nope): got true, wanted false")
+	}
+
+	dt.SetKeys("a", "b")
+	if err := dt.VerifyKeys(); err != nil {
+		t.Errorf("got %v, wanted no error", err)
+	}
+	dt.SetFloatValue("b", 0, 5)
+	if err := dt.VerifyKeys(); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("got %v, wanted %v", err, ErrUnsorted)
+	}
+}
+
+func TestSelectKeepKeys(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("a", []string{"y", "x", "x"})
+	dt.AddColumn("b", []float64{1, 3, 2})
+	dt.AddColumn("c", []float64{7, 8, 9})
+	dt.SetKeys("a", "b")
+
+	sel, err := dt.SelectKeepKeys([]string{"c", "b", "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := sel.KeyNames(); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("got keys %v, wanted [a b]", keys)
+	}
+	if err := sel.VerifyKeys(); err != nil {
+		t.Errorf("got %v, wanted no error", err)
+	}
+
+	sel, _ = dt.SelectKeepKeys([]string{"a", "c"})
+	if keys := sel.KeyNames(); len(keys) != 0 {
+		t.Errorf("got keys %v, wanted none when a key column is omitted", keys)
+	}
+
+	clone := dt.CloneWithKeys()
+	if keys := clone.KeyNames(); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("got keys %v, wanted [a b]", keys)
+	}
+	if !equivalentRows(clone.RawRows(true), dt.RawRows(true)) {
+		t.Errorf("got %+v, wanted %+v", clone.RawRows(true), dt.RawRows(true))
+	}
+}
+
+func TestMapGroups(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "b", "a", "c"})
+	dt.AddColumn("v", []float64{1, 2, 3, 4})
+	dt.SetKeys("g")
+
+	res, err := dt.MapGroups(func(rg RowGroup) (*DataTable, error) {
+		out := &DataTable{}
+		out.AddStringColumn("g", []string{})
+		out.AddColumn("v2", []float64{})
+		for rg.Next() {
+			g, _ := rg.StringValue("g")
+			v, _ := rg.FloatValue("v")
+			if g == "c" {
+				return nil, nil
+			}
+			out.AppendRow([]interface{}{g, v * 2})
+		}
+		return out, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]interface{}{
+		{"g", "v2"},
+		{"a", 2.0},
+		{"a", 6.0},
+		{"b", 4.0},
+	}
+	if rows := res.RawRows(true); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	calls := 0
+	_, err = dt.MapGroups(func(rg RowGroup) (*DataTable, error) {
+		calls++
+		return nil, errors.New("failed")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("got error %v after %d calls, wanted an error after 1 call", err, calls)
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "b", "a", "a"})
+	dt.AddColumn("v", []float64{3, 2, 1, 5})
+	dt.SetKeys("g")
+
+	var tables []*DataTable
+	g := GrouperFunc(func(rg RowGroup) {
+		rg.Next()
+		before := rg.RowIndex()
+		sub := rg.Materialize()
+		if rg.RowIndex() != before {
+			t.Errorf("Materialize changed the current row from %d to %d", before, rg.RowIndex())
+		}
+		sub.SetKeys("v")
+		tables = append(tables, sub)
+	})
+
+	dt.Apply(g)
+	if len(tables) != 2 {
+		t.Fatalf("got %d groups, wanted 2", len(tables))
+	}
+	expected := [][]interface{}{{"a", 1.0}, {"a", 3.0}, {"a", 5.0}}
+	if rows := tables[0].RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	tables = nil
+	dt.ApplyWhere(g, GreaterThan("v", 2))
+	expected = [][]interface{}{{"a", 3.0}, {"a", 5.0}}
+	if rows := tables[0].RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+}
+
+func TestApplySorted(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("customer", []string{"b", "a", "a", "b", "a"})
+	dt.AddColumn("ts", []float64{20, 30, 10, 5, 20})
+	dt.SetKeys("customer")
+
+	actual := [][]float64{}
+	err := dt.ApplySorted(GrouperFunc(func(rg RowGroup) {
+		ts := []float64{}
+		for rg.Next() {
+			v, _ := rg.FloatValue("ts")
+			ts = append(ts, v)
+		}
+		actual = append(actual, ts)
+	}), "ts")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := [][]float64{{10, 20, 30}, {5, 20}}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %+v, wanted %+v", actual, expected)
+	}
+
+	// the table keeps its original order within groups
+	expectedRows := [][]interface{}{{"a", 30.0}, {"a", 10.0}, {"a", 20.0}, {"b", 20.0}, {"b", 5.0}}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
@@ -0,0 +1,246 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalScale is the largest scale supported by decimal columns.
+const maxDecimalScale = 18
+
+// AddDecimalColumn adds a fixed-point decimal column whose values are given as
+// integers in units of 10^-scale, so with a scale of 2 the value 12345
+// represents 123.45. Decimal columns are numeric columns, readable with
+// FloatValue and usable anywhere a numeric column is, but also keep their
+// exact values for use by DecimalSum, SumDecimal and CSV. Numbers stored in a
+// decimal column by other operations are rounded to the column's scale and
+// NaN values are held exactly as zero. scale must be between 0 and 18.
+func (dt *DataTable) AddDecimalColumn(name string, units []int64, scale int) error {
+	if scale < 0 || scale > maxDecimalScale {
+		return fmt.Errorf("invalid decimal scale: %d", scale)
+	}
+	if len(dt.cols) != 0 && len(units) != dt.Len() {
+		return ErrInvalidColumnLength
+	}
+	cv := colvals{
+		f:     make([]float64, len(units)),
+		d:     make([]int64, len(units)),
+		scale: scale,
+	}
+	copy(cv.d, units)
+	for i, u := range units {
+		cv.f[i] = fromUnits(u, scale)
+	}
+	dt.addColumn(name, cv)
+	dt.mutated()
+	return nil
+}
+
+// DecimalValues returns a copy of the exact values of the named decimal column
+// in units of 10^-scale, together with the scale.
+func (dt *DataTable) DecimalValues(name string) ([]int64, int, error) {
+	c, err := dt.decimalCol(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append([]int64(nil), dt.cols[c].d...), dt.cols[c].scale, nil
+}
+
+// SumDecimal returns the exact sum of the non-NaN values of the named decimal
+// column in units of 10^-scale, together with the scale. An error is returned
+// if the sum overflows.
+func (dt *DataTable) SumDecimal(name string) (int64, int, error) {
+	c, err := dt.decimalCol(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	cv := dt.cols[c]
+	var sum int64
+	for i, u := range cv.d {
+		if math.IsNaN(cv.f[i]) {
+			continue
+		}
+		var ok bool
+		if sum, ok = addUnits(sum, u); !ok {
+			return 0, 0, fmt.Errorf("decimal sum overflows: %s", name)
+		}
+	}
+	return sum, cv.scale, nil
+}
+
+// addUnits returns a+b, or false if the sum overflows an int64.
+func addUnits(a, b int64) (int64, bool) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, false
+	}
+	return a + b, true
+}
+
+// DecimalValue returns the exact value of the named decimal column in units of
+// 10^-scale, together with the scale, or false if the column does not exist,
+// is not a decimal column or the value is NaN.
+func (r *RowRef) DecimalValue(name string) (int64, int, bool) {
+	c, exists := r.dt.colorder[name]
+	if !exists {
+		return 0, 0, false
+	}
+	cv := r.dt.cols[c]
+	if cv.d == nil || math.IsNaN(cv.f[r.index]) {
+		return 0, 0, false
+	}
+	return cv.d[r.index], cv.scale, true
+}
+
+// DecimalSum returns an Aggregator that sums the exact values of the named
+// decimal column, so the result is the closest float to the true sum rather
+// than accumulating rounding error. Rows whose value is NaN are skipped. The
+// sum of a column that is not a decimal column is NaN, as is a sum too large
+// to be held exactly.
+func DecimalSum(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		var sum int64
+		scale := 0
+		for rg.Next() {
+			rr, ok := rowRefOf(rg)
+			if !ok {
+				return math.NaN()
+			}
+			c, exists := rr.dt.colorder[name]
+			if !exists || rr.dt.cols[c].d == nil {
+				return math.NaN()
+			}
+			if u, sc, ok := rr.DecimalValue(name); ok {
+				if sum, ok = addUnits(sum, u); !ok {
+					return math.NaN()
+				}
+				scale = sc
+			}
+		}
+		return fromUnits(sum, scale)
+	}), callExpr("DecimalSum", name), name)
+}
+
+// FormatDecimal formats a value given in units of 10^-scale with exactly scale
+// digits after the decimal point.
+func FormatDecimal(units int64, scale int) string {
+	neg := units < 0
+	digits := strconv.FormatUint(absUnits(units), 10)
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// parseDecimal parses s, written according to the locale, as an exact number
+// of units of 10^-scale, rounding half away from zero.
+func (l Locale) parseDecimal(s string, scale int) (int64, error) {
+	if l.Thousands != 0 {
+		s = strings.ReplaceAll(s, string(l.Thousands), "")
+	}
+	dec := "."
+	if l.Decimal != 0 {
+		dec = string(l.Decimal)
+	}
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimLeft(s, "+-")
+	whole, frac, _ := strings.Cut(digits, dec)
+	if whole == "" && frac == "" || len(s)-len(digits) > 1 || strings.ContainsAny(whole+frac, "+-") {
+		return 0, fmt.Errorf("invalid decimal: %q", s)
+	}
+
+	round := false
+	if len(frac) > scale {
+		round = frac[scale] >= '5'
+		if strings.Trim(frac[scale:], "0123456789") != "" {
+			return 0, fmt.Errorf("invalid decimal: %q", s)
+		}
+		frac = frac[:scale]
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+
+	if whole == "" {
+		whole = "0"
+	}
+	u, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid decimal: %q", s)
+	}
+	if round {
+		u++
+	}
+	if neg {
+		u = -u
+	}
+	return u, nil
+}
+
+// decimalCol looks up the position of the named decimal column.
+func (dt *DataTable) decimalCol(name string) (int, error) {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return 0, fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.cols[c].d == nil {
+		return 0, ErrMismatchedColumnTypes
+	}
+	return c, nil
+}
+
+// compareExact compares the exact values at positions i and j of a decimal,
+// integer or time column. It reports false if the column holds no exact values
+// or either value is NaN, in which case the float values must be compared
+// instead.
+func (cv colvals) compareExact(i, j int) (int, bool) {
+	if cv.d == nil || math.IsNaN(cv.f[i]) || math.IsNaN(cv.f[j]) {
+		return 0, false
+	}
+	switch a, b := cv.d[i], cv.d[j]; {
+	case a < b:
+		return -1, true
+	case a > b:
+		return 1, true
+	}
+	return 0, true
+}
+
+var pow10 = func() [maxDecimalScale + 1]float64 {
+	var p [maxDecimalScale + 1]float64
+	p[0] = 1
+	for i := 1; i < len(p); i++ {
+		p[i] = p[i-1] * 10
+	}
+	return p
+}()
+
+// toUnits rounds v to the nearest unit of 10^-scale, returning the units and
+// the float value they represent. NaN is held as zero units, as are infinite
+// values and values with more units than an int64 can hold, which become NaN
+// rather than wrapping to an unrelated value.
+func toUnits(v float64, scale int) (int64, float64) {
+	r := math.Round(v * pow10[scale])
+	if !(r >= math.MinInt64 && r < math.MaxInt64) {
+		return 0, math.NaN()
+	}
+	u := int64(r)
+	return u, fromUnits(u, scale)
+}
+
+// fromUnits returns the float closest to units times 10^-scale.
+func fromUnits(units int64, scale int) float64 {
+	return float64(units) / pow10[scale]
+}
+
+func absUnits(u int64) uint64 {
+	if u < 0 {
+		return uint64(-(u + 1)) + 1
+	}
+	return uint64(u)
+}
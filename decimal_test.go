@@ -0,0 +1,160 @@
+package datatable
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDecimalColumn(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"b", "a", "b"})
+	if err := dt.AddDecimalColumn("amount", []int64{10, 20, 30}, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dt.SetKeys("k")
+
+	dt.AppendRow([]interface{}{"a", 0.014})
+	dt.ParseRow("c", "1.005")
+	dt.SetFloatValue("amount", 0, 0.1)
+
+	units, scale, err := dt.DecimalValues("amount")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []int64{10, 10, 30, 1, 101}; !reflect.DeepEqual(units, expected) || scale != 2 {
+		t.Errorf("got %v scale %d, wanted %v scale 2", units, scale, expected)
+	}
+
+	sum, _, err := dt.SumDecimal("amount")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum != 152 {
+		t.Errorf("got sum %d, wanted 152", sum)
+	}
+
+	buf := new(bytes.Buffer)
+	dt.CSV(buf)
+	if expected := "k,amount\na,0.10\nb,0.10\nb,0.30\na,0.01\nc,1.01\n"; buf.String() != expected {
+		t.Errorf("got %q, wanted %q", buf.String(), expected)
+	}
+
+	// decimal values survive sorting, selection and row removal
+	dt.SetKeys("amount")
+	dt.RemoveRows(CloselyEqual("amount", 0.3, 0))
+	sel, _ := dt.Select([]string{"amount"})
+	if units, _, _ := sel.DecimalValues("amount"); !reflect.DeepEqual(units, []int64{1, 10, 10, 101}) {
+		t.Errorf("got %v, wanted [1 10 10 101]", units)
+	}
+
+	if _, _, err := dt.DecimalValues("k"); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if err := dt.AddDecimalColumn("bad", []int64{1, 2, 3, 4}, 19); err == nil {
+		t.Errorf("got no error for invalid scale")
+	}
+}
+
+func TestDecimalSum(t *testing.T) {
+	dt := &DataTable{}
+	units := make([]int64, 10)
+	floats := make([]float64, 10)
+	for i := range units {
+		units[i] = 10
+		floats[i] = 0.1
+	}
+	dt.AddDecimalColumn("d", units, 2)
+	dt.AddColumn("f", floats)
+
+	if got := dt.Reduce(DecimalSum("d")); got != 1 {
+		t.Errorf("got %v, wanted exactly 1", got)
+	}
+	if got := dt.Reduce(Sum("f")); got == 1 {
+		t.Errorf("float sum unexpectedly exact")
+	}
+	if got := dt.Reduce(DecimalSum("f")); !math.IsNaN(got) {
+		t.Errorf("got %v for non-decimal column, wanted NaN", got)
+	}
+
+	big := &DataTable{}
+	big.AddDecimalColumn("d", []int64{math.MaxInt64 - 1, 2}, 0)
+	if got := big.Reduce(DecimalSum("d")); !math.IsNaN(got) {
+		t.Errorf("got %v for overflowing sum, wanted NaN", got)
+	}
+	if _, _, err := big.SumDecimal("d"); err == nil {
+		t.Errorf("got no error for overflowing sum")
+	}
+}
+
+func TestDecimalOutOfRange(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddDecimalColumn("d", []int64{100, 200, 300, 400}, 2)
+	dt.AddIntColumn("i", []int64{0, 1, 2, 3})
+
+	dt.SetFloatValue("d", 0, math.Inf(1))
+	dt.SetFloatValue("d", 1, math.Inf(-1))
+	dt.SetFloatValue("d", 2, 1e20)
+	dt.Log("i")
+
+	expected := [][]interface{}{
+		{"d", "i"},
+		{math.NaN(), math.NaN()},
+		{math.NaN(), 0.0},
+		{math.NaN(), 1.0},
+		{4.0, 1.0},
+	}
+	if !equivalentRows(dt.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", dt.RawRows(true), expected)
+	}
+	units, _, _ := dt.DecimalValues("d")
+	if expected := []int64{0, 0, 0, 400}; !reflect.DeepEqual(units, expected) {
+		t.Errorf("got units %v, wanted %v", units, expected)
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	testCases := []struct {
+		units    int64
+		scale    int
+		expected string
+	}{
+		{12345, 2, "123.45"},
+		{5, 2, "0.05"},
+		{-5, 3, "-0.005"},
+		{-120, 0, "-120"},
+		{math.MinInt64, 2, "-92233720368547758.08"},
+	}
+	for _, tc := range testCases {
+		if got := FormatDecimal(tc.units, tc.scale); got != tc.expected {
+			t.Errorf("FormatDecimal(%d, %d): got %q, wanted %q", tc.units, tc.scale, got, tc.expected)
+		}
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	testCases := []struct {
+		l        Locale
+		s        string
+		scale    int
+		expected int64
+		err      bool
+	}{
+		{Locale{}, "1.005", 2, 101, false},
+		{Locale{}, "-1.004", 2, -100, false},
+		{Locale{}, "12", 2, 1200, false},
+		{Locale{}, ".5", 0, 1, false},
+		{Locale{Decimal: ',', Thousands: '.'}, "1.234,5", 1, 12345, false},
+		{Locale{}, "1.2.3", 2, 0, true},
+		{Locale{}, "--1", 2, 0, true},
+		{Locale{}, "", 2, 0, true},
+	}
+	for _, tc := range testCases {
+		got, err := tc.l.parseDecimal(tc.s, tc.scale)
+		if (err != nil) != tc.err || got != tc.expected {
+			t.Errorf("parseDecimal(%q, %d): got %d, %v, wanted %d (error %v)", tc.s, tc.scale, got, err, tc.expected, tc.err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// A FieldComparator scores how alike the values of the named column are in
+// two rows, from 0 for rows that differ to 1 for rows that agree.
+type FieldComparator func(a, b RowRef, name string) float64
+
+// ExactMatch returns a FieldComparator that scores 1 if the values are equal
+// and 0 otherwise. NaN values are never equal.
+func ExactMatch() FieldComparator {
+	return func(a, b RowRef, name string) float64 {
+		if va, ok := a.StringValue(name); ok {
+			if vb, _ := b.StringValue(name); va == vb {
+				return 1
+			}
+			return 0
+		}
+		va, _ := a.FloatValue(name)
+		if vb, _ := b.FloatValue(name); va == vb {
+			return 1
+		}
+		return 0
+	}
+}
+
+// NumericTolerance returns a FieldComparator for numeric columns that scores 1
+// for values that differ by no more than tol and 0 otherwise.
+func NumericTolerance(tol float64) FieldComparator {
+	return func(a, b RowRef, name string) float64 {
+		va, _ := a.FloatValue(name)
+		vb, _ := b.FloatValue(name)
+		if math.Abs(va-vb) <= tol {
+			return 1
+		}
+		return 0
+	}
+}
+
+// StringSimilarity returns a FieldComparator for string columns that scores
+// values using sim, such as Levenshtein or JaroWinkler.
+func StringSimilarity(sim SimilarityFunc) FieldComparator {
+	return func(a, b RowRef, name string) float64 {
+		va, _ := a.StringValue(name)
+		vb, _ := b.StringValue(name)
+		return sim(va, vb)
+	}
+}
+
+// A DedupeField describes how a column contributes to the score of a pair of
+// rows.
+type DedupeField struct {
+	Name    string
+	Compare FieldComparator
+	Weight  float64 // relative importance of the column, 1 if zero
+}
+
+// DedupeOptions controls how Dedupe finds duplicate rows.
+type DedupeOptions struct {
+	// Fields lists the columns compared and how. The score of a pair of
+	// rows is the weighted mean of the scores of the fields.
+	Fields []DedupeField
+
+	// Threshold is the minimum score for a pair of rows to be considered
+	// duplicates.
+	Threshold float64
+
+	// Block lists columns whose values must be equal for a pair of rows to
+	// be compared at all, which avoids comparing every pair of rows in a
+	// large table. The default compares every pair.
+	Block []string
+}
+
+// A DuplicatePair is a pair of rows, given by position, that Dedupe scored as
+// duplicates.
+type DuplicatePair struct {
+	A, B  int // A is less than B
+	Score float64
+}
+
+// DedupeResult holds the duplicates found by Dedupe.
+type DedupeResult struct {
+	// Pairs lists the pairs of rows scoring at least the threshold, ordered
+	// by A and then B.
+	Pairs []DuplicatePair
+
+	// Clusters groups the rows linked directly or indirectly by Pairs. Each
+	// cluster lists the positions of its rows in ascending order and
+	// clusters are ordered by their first row. Rows without duplicates are
+	// omitted.
+	Clusters [][]int
+}
+
+// Dedupe finds rows that are likely to describe the same entity despite
+// differences in their values, such as customer records with misspelled
+// names, by scoring pairs of rows according to opts. Unlike Unique it does not
+// modify the table. Rows are identified by their position in the table's
+// current order.
+func (dt *DataTable) Dedupe(opts DedupeOptions) (*DedupeResult, error) {
+	if len(opts.Fields) == 0 {
+		return nil, fmt.Errorf("no dedupe fields given")
+	}
+	total := 0.0
+	for _, f := range opts.Fields {
+		if _, exists := dt.colorder[f.Name]; !exists {
+			return nil, fmt.Errorf("unknown column: %s", f.Name)
+		}
+		if f.Compare == nil {
+			return nil, fmt.Errorf("no comparator given for column %s", f.Name)
+		}
+		total += fieldWeight(f)
+	}
+	block := make([]int, len(opts.Block))
+	for i, name := range opts.Block {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		block[i] = c
+	}
+
+	blocks := map[string][]int{}
+	var order []string
+	for i := 0; i < dt.Len(); i++ {
+		k := dt.rowKey(block, i)
+		if _, exists := blocks[k]; !exists {
+			order = append(order, k)
+		}
+		blocks[k] = append(blocks[k], i)
+	}
+
+	ret := &DedupeResult{}
+	a, b := RowRef{dt: dt}, RowRef{dt: dt}
+	for _, k := range order {
+		rows := blocks[k]
+		for x, i := range rows {
+			a.index = i
+			for _, j := range rows[x+1:] {
+				b.index = j
+				score := 0.0
+				for _, f := range opts.Fields {
+					score += fieldWeight(f) * f.Compare(a, b, f.Name)
+				}
+				score /= total
+				if score >= opts.Threshold {
+					ret.Pairs = append(ret.Pairs, DuplicatePair{A: i, B: j, Score: score})
+				}
+			}
+		}
+	}
+	sort.Slice(ret.Pairs, func(x, y int) bool {
+		if ret.Pairs[x].A != ret.Pairs[y].A {
+			return ret.Pairs[x].A < ret.Pairs[y].A
+		}
+		return ret.Pairs[x].B < ret.Pairs[y].B
+	})
+	ret.Clusters = clusterPairs(ret.Pairs, dt.Len())
+	return ret, nil
+}
+
+func fieldWeight(f DedupeField) float64 {
+	if f.Weight == 0 {
+		return 1
+	}
+	return f.Weight
+}
+
+// clusterPairs finds the connected components of the graph of n rows whose
+// edges are pairs, omitting rows with no edges.
+func clusterPairs(pairs []DuplicatePair, n int) [][]int {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, p := range pairs {
+		ra, rb := find(p.A), find(p.B)
+		if ra != rb {
+			parent[max(ra, rb)] = min(ra, rb)
+		}
+	}
+
+	linked := make([]bool, n)
+	for _, p := range pairs {
+		linked[p.A], linked[p.B] = true, true
+	}
+	var clusters [][]int
+	index := map[int]int{}
+	for i := 0; i < n; i++ {
+		if !linked[i] {
+			continue
+		}
+		r := find(i)
+		c, exists := index[r]
+		if !exists {
+			c = len(clusters)
+			index[r] = c
+			clusters = append(clusters, nil)
+		}
+		clusters[c] = append(clusters[c], i)
+	}
+	return clusters
+}
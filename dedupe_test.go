@@ -0,0 +1,70 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("name", []string{"Jon Smith", "Jane Doe", "John Smith", "Jane Doe", "Johnny Smith", "Bob Jones"})
+	dt.AddColumn("age", []float64{40, 31, 41, 31, 40, 55})
+	dt.AddStringColumn("city", []string{"Leeds", "York", "Leeds", "Hull", "Leeds", "Leeds"})
+
+	opts := DedupeOptions{
+		Fields: []DedupeField{
+			{Name: "name", Compare: StringSimilarity(JaroWinkler), Weight: 2},
+			{Name: "age", Compare: NumericTolerance(1)},
+		},
+		Threshold: 0.9,
+	}
+	res, err := dt.Dedupe(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var pairs [][2]int
+	for _, p := range res.Pairs {
+		pairs = append(pairs, [2]int{p.A, p.B})
+		if p.Score < opts.Threshold || p.Score > 1 {
+			t.Errorf("pair %v: got score %v", p, p.Score)
+		}
+	}
+	if expected := [][2]int{{0, 2}, {0, 4}, {1, 3}, {2, 4}}; !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("got pairs %v, wanted %v", pairs, expected)
+	}
+	if expected := [][]int{{0, 2, 4}, {1, 3}}; !reflect.DeepEqual(res.Clusters, expected) {
+		t.Errorf("got clusters %v, wanted %v", res.Clusters, expected)
+	}
+
+	// blocking on city stops the Jane Does being compared
+	opts.Block = []string{"city"}
+	res, err = dt.Dedupe(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := [][]int{{0, 2, 4}}; !reflect.DeepEqual(res.Clusters, expected) {
+		t.Errorf("got clusters %v, wanted %v", res.Clusters, expected)
+	}
+
+	opts.Fields = append(opts.Fields, DedupeField{Name: "missing", Compare: ExactMatch()})
+	if _, err := dt.Dedupe(opts); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
+func TestExactMatch(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("s", []string{"a", "a", "b"})
+	dt.AddColumn("f", []float64{1, 2, 1})
+
+	a, _ := dt.RowRef(0)
+	b, _ := dt.RowRef(1)
+	c, _ := dt.RowRef(2)
+	cmp := ExactMatch()
+	if cmp(a, b, "s") != 1 || cmp(a, c, "s") != 0 {
+		t.Errorf("string column compared incorrectly")
+	}
+	if cmp(a, b, "f") != 0 || cmp(a, c, "f") != 1 {
+		t.Errorf("numeric column compared incorrectly")
+	}
+}
@@ -0,0 +1,109 @@
+package datatable
+
+import (
+	"fmt"
+	"sort"
+)
+
+// derivation records how a derived column is calculated.
+type derivation struct {
+	c       Calculator
+	sources []string
+}
+
+// CalcDerived behaves like Calc but also records c and the names of the
+// columns it reads so that the column can be refreshed by Recompute after
+// the source data changes. Each source must name an existing column and may
+// not itself be derived from colName. Replacing or removing the derived
+// column discards the recorded calculation.
+func (dt *DataTable) CalcDerived(colName string, c Calculator, sources ...string) error {
+	for _, src := range sources {
+		if _, exists := dt.colorder[src]; !exists {
+			return fmt.Errorf("unknown column: %s", src)
+		}
+		if src == colName || dt.dependsOn(src, colName) {
+			return fmt.Errorf("circular derivation: %s depends on %s", src, colName)
+		}
+	}
+	dt.Calc(colName, c)
+	if dt.derived == nil {
+		dt.derived = map[string]derivation{}
+	}
+	dt.derived[colName] = derivation{c: c, sources: append([]string(nil), sources...)}
+	return nil
+}
+
+// DerivedSources returns the names of the columns the named column was
+// derived from by CalcDerived or nil and false if it is not a derived column.
+func (dt *DataTable) DerivedSources(name string) ([]string, bool) {
+	d, exists := dt.derived[name]
+	if !exists {
+		return nil, false
+	}
+	return append([]string(nil), d.sources...), true
+}
+
+// Recompute recalculates every column created by CalcDerived. Columns are
+// recalculated after any derived columns they read so chains of derived
+// columns are refreshed consistently. An error is returned if a source
+// column has since been removed. If a key column is recalculated the table is
+// sorted again.
+func (dt *DataTable) Recompute() error {
+	names := make([]string, 0, len(dt.derived))
+	for name := range dt.derived {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	done := map[string]bool{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		d, exists := dt.derived[name]
+		if !exists || done[name] {
+			return nil
+		}
+		done[name] = true
+		for _, src := range d.sources {
+			if _, exists := dt.colorder[src]; !exists {
+				return fmt.Errorf("unknown column: %s (source of %s)", src, name)
+			}
+			if err := visit(src); err != nil {
+				return err
+			}
+		}
+		col := fillNaN(dt.Len())
+		dt.CalcIndexFill(col, d.c, fillSeq(dt.Len()))
+		dt.cols[dt.colorder[name]] = colvals{f: col}
+		dt.markDirty(dt.colorder[name])
+		return nil
+	}
+
+	resort := false
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+		resort = resort || dt.isKeyCol(dt.colorder[name])
+	}
+	if resort {
+		sort.Stable(dt)
+		dt.markAllDirty()
+	}
+	dt.mutated()
+	return nil
+}
+
+// dependsOn reports whether the named column is derived, directly or
+// indirectly, from the column target.
+func (dt *DataTable) dependsOn(name, target string) bool {
+	d, exists := dt.derived[name]
+	if !exists {
+		return false
+	}
+	for _, src := range d.sources {
+		if src == target || dt.dependsOn(src, target) {
+			return true
+		}
+	}
+	return false
+}
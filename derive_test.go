@@ -0,0 +1,59 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecompute(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("price", []float64{1, 2, 3})
+	dt.AddColumn("qty", []float64{2, 2, 2})
+	if err := dt.CalcDerived("total", Mul(Col("price"), Col("qty")), "price", "qty"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.CalcDerived("taxed", Mul(Col("total"), Lit(1.5)), "total"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dt.SetFloatValue("price", 0, 10)
+	if err := dt.Recompute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{10.0, 2.0, 20.0, 30.0},
+		{2.0, 2.0, 4.0, 6.0},
+		{3.0, 2.0, 6.0, 9.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	if sources, ok := dt.DerivedSources("total"); !ok || !reflect.DeepEqual(sources, []string{"price", "qty"}) {
+		t.Errorf("got %v %v, wanted [price qty] true", sources, ok)
+	}
+}
+
+func TestCalcDerivedErrors(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("a", []float64{1, 2})
+	if err := dt.CalcDerived("b", Col("nope"), "nope"); err == nil {
+		t.Errorf("got no error for unknown source")
+	}
+
+	dt.CalcDerived("b", Col("a"), "a")
+	if err := dt.CalcDerived("a", Col("b"), "b"); err == nil {
+		t.Errorf("got no error for circular derivation")
+	}
+
+	dt.RemoveColumn("a")
+	if err := dt.Recompute(); err == nil {
+		t.Errorf("got no error for removed source")
+	}
+
+	dt.AddColumn("b", []float64{5, 6})
+	if _, ok := dt.DerivedSources("b"); ok {
+		t.Errorf("replaced column still derived")
+	}
+}
@@ -0,0 +1,47 @@
+package datatable
+
+// DirtyColumns returns the names of the columns that have been added or
+// modified since the table was created or ResetDirty was last called, in the
+// order the columns were added to the table. Operations that add, remove or
+// reorder rows modify every column. Columns that have been removed are not
+// reported.
+func (dt *DataTable) DirtyColumns() []string {
+	var names []string
+	for _, name := range dt.colnames {
+		if dt.dirty[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ResetDirty marks every column as unmodified.
+func (dt *DataTable) ResetDirty() {
+	dt.dirty = nil
+}
+
+// Version returns a number that increases whenever the data, columns, keys
+// or row order of the table are modified. Caches of results calculated from
+// the table can compare versions to detect that they are stale, and the
+// version can serve as a token for optimistic concurrency control, such as an
+// HTTP entity tag. Versions of different tables are not comparable.
+func (dt *DataTable) Version() uint64 {
+	return dt.version
+}
+
+// markDirty records that column c has been modified.
+func (dt *DataTable) markDirty(c int) {
+	if dt.dirty == nil {
+		dt.dirty = map[string]bool{}
+	}
+	dt.dirty[dt.colnames[c]] = true
+	delete(dt.stats, dt.colnames[c])
+	dt.version++
+}
+
+// markAllDirty records that every column has been modified.
+func (dt *DataTable) markAllDirty() {
+	for c := range dt.colnames {
+		dt.markDirty(c)
+	}
+}
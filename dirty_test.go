@@ -0,0 +1,78 @@
+package datatable
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDirtyColumns(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{1, 2})
+	dt.AddColumn("c1", []float64{3, 4})
+	dt.AddStringColumn("c2", []string{"a", "b"})
+
+	if got := dt.DirtyColumns(); !reflect.DeepEqual(got, []string{"c0", "c1", "c2"}) {
+		t.Errorf("got %v, wanted all new columns", got)
+	}
+
+	dt.ResetDirty()
+	if got := dt.DirtyColumns(); got != nil {
+		t.Errorf("got %v after reset, wanted none", got)
+	}
+
+	dt.SetFloatValue("c1", 0, 5)
+	dt.Calc("c3", Col("c0"))
+	if got := dt.DirtyColumns(); !reflect.DeepEqual(got, []string{"c1", "c3"}) {
+		t.Errorf("got %v, wanted [c1 c3]", got)
+	}
+
+	dt.ResetDirty()
+	dt.AppendRow([]interface{}{1.0, 1.0, "c", 1.0})
+	if got := dt.DirtyColumns(); !reflect.DeepEqual(got, []string{"c0", "c1", "c2", "c3"}) {
+		t.Errorf("got %v after appending a row, wanted all columns", got)
+	}
+
+	dt.RemoveColumn("c1")
+	if got := dt.DirtyColumns(); !reflect.DeepEqual(got, []string{"c0", "c2", "c3"}) {
+		t.Errorf("got %v after removing a column, wanted [c0 c2 c3]", got)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	dt := &DataTable{}
+	v := dt.Version()
+
+	changed := func(op string) {
+		t.Helper()
+		if dt.Version() <= v {
+			t.Errorf("version not increased by %s", op)
+		}
+		v = dt.Version()
+	}
+
+	dt.AddColumn("x", []float64{3, 1, 2})
+	changed("AddColumn")
+	dt.SetKeys("x")
+	changed("SetKeys")
+	dt.SetFloatValue("x", 0, 5)
+	changed("SetFloatValue")
+	dt.AppendRow([]interface{}{4.0})
+	changed("AppendRow")
+	dt.RemoveRows(IsZero("x"))
+	if dt.Version() != v {
+		t.Errorf("version changed when no rows were removed")
+	}
+	dt.Unique()
+	changed("Unique")
+	dt.Swap(0, 1)
+	changed("Swap")
+	sort.Sort(sort.Reverse(dt))
+	changed("sort.Sort")
+
+	dt.Len()
+	dt.CountWhere(GreaterThan("x", 1))
+	if dt.Version() != v {
+		t.Errorf("version changed by reading the table")
+	}
+}
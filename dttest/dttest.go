@@ -0,0 +1,146 @@
+// Package dttest provides helpers for testing code that uses data tables,
+// including a generator of random tables for fuzz and property based tests.
+package dttest
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/iand/datatable"
+)
+
+// A Spec describes the shape of a table generated by RandomTable.
+type Spec struct {
+	Rows        int     // number of rows
+	NumericCols int     // number of numeric columns, named n0, n1, ...
+	StringCols  int     // number of string columns, named s0, s1, ...
+	NaNFraction float64 // fraction of numeric values that are NaN
+	Cardinality int     // number of distinct values per column, or unlimited if zero
+	Seed        int64   // seed for the random number generator
+}
+
+// RandomTable generates a table of random data according to spec. Tables
+// generated from the same spec are identical. Numeric values are drawn
+// uniformly from [0, 1) and string values are made of lower case letters so
+// that they are never mistaken for numbers when read back from text formats.
+func RandomTable(spec Spec) *datatable.DataTable {
+	rng := rand.New(rand.NewSource(spec.Seed))
+
+	// draw returns the index of a distinct value, or -1 for an unlimited value.
+	draw := func() int {
+		if spec.Cardinality <= 0 {
+			return -1
+		}
+		return rng.Intn(spec.Cardinality)
+	}
+
+	dt := &datatable.DataTable{}
+	for c := 0; c < spec.NumericCols; c++ {
+		values := make([]float64, spec.Rows)
+		for i := range values {
+			switch k := draw(); {
+			case rng.Float64() < spec.NaNFraction:
+				values[i] = math.NaN()
+			case k >= 0:
+				values[i] = float64(k)
+			default:
+				values[i] = rng.Float64()
+			}
+		}
+		dt.AddColumn(fmt.Sprintf("n%d", c), values)
+	}
+	for c := 0; c < spec.StringCols; c++ {
+		values := make([]string, spec.Rows)
+		for i := range values {
+			if k := draw(); k >= 0 {
+				values[i] = letters(k + 1)
+			} else {
+				values[i] = letters(rng.Int() + 1)
+			}
+		}
+		dt.AddStringColumn(fmt.Sprintf("s%d", c), values)
+	}
+	return dt
+}
+
+// letters encodes a positive n in bijective base 26 using lower case letters.
+func letters(n int) string {
+	var b []byte
+	for n > 0 {
+		n--
+		b = append([]byte{byte('a' + n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+// Compare reports the first difference between the column names, column
+// types and values of a and b, or nil if they hold the same data. NaN values
+// are considered equal to each other. Keys are not compared.
+func Compare(a, b *datatable.DataTable) error {
+	ra, rb := a.RawRows(true), b.RawRows(true)
+	if len(ra) != len(rb) {
+		return fmt.Errorf("got %d rows, wanted %d", len(rb)-1, len(ra)-1)
+	}
+	for i := range ra {
+		if len(ra[i]) != len(rb[i]) {
+			return fmt.Errorf("got %d columns, wanted %d", len(rb[i]), len(ra[i]))
+		}
+		for j := range ra[i] {
+			if !equal(ra[i][j], rb[i][j]) {
+				if i == 0 {
+					return fmt.Errorf("column %d: got name %v, wanted %v", j, rb[i][j], ra[i][j])
+				}
+				return fmt.Errorf("row %d, column %v: got %#v, wanted %#v", i-1, ra[0][j], rb[i][j], ra[i][j])
+			}
+		}
+	}
+	return nil
+}
+
+func equal(a, b interface{}) bool {
+	fa, aok := a.(float64)
+	fb, bok := b.(float64)
+	if aok && bok && math.IsNaN(fa) && math.IsNaN(fb) {
+		return true
+	}
+	return a == b
+}
+
+// CheckCSVRoundTrip writes dt as CSV, reads it back and reports any
+// difference from the original.
+func CheckCSVRoundTrip(dt *datatable.DataTable) error {
+	var buf bytes.Buffer
+	if err := dt.CSV(&buf); err != nil {
+		return fmt.Errorf("writing csv: %w", err)
+	}
+	dt2, err := datatable.ReadCSV(&buf, datatable.CSVOptions{})
+	if err != nil {
+		return fmt.Errorf("reading csv: %w", err)
+	}
+	if err := Compare(dt, dt2); err != nil {
+		return fmt.Errorf("csv round trip: %w", err)
+	}
+	return nil
+}
+
+// CheckJSONRoundTrip writes dt as JSON, reads it back and reports any
+// difference from the original. Since column types are inferred from the
+// values read, numeric columns holding only NaN values and tables with no rows
+// are not reproduced.
+func CheckJSONRoundTrip(dt *datatable.DataTable) error {
+	var buf bytes.Buffer
+	if err := dt.ToJSON(&buf); err != nil {
+		return fmt.Errorf("writing json: %w", err)
+	}
+	dt2, err := datatable.FromJSON(&buf)
+	if err != nil {
+		return fmt.Errorf("reading json: %w", err)
+	}
+	if err := Compare(dt, dt2); err != nil {
+		return fmt.Errorf("json round trip: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package dttest
+
+import (
+	"testing"
+)
+
+func TestRandomTable(t *testing.T) {
+	spec := Spec{Rows: 50, NumericCols: 2, StringCols: 2, NaNFraction: 0.1, Seed: 7}
+	dt := RandomTable(spec)
+	if dt.Len() != 50 || dt.N() != 4 {
+		t.Fatalf("got %d rows and %d columns, wanted 50 and 4", dt.Len(), dt.N())
+	}
+	if err := Compare(dt, RandomTable(spec)); err != nil {
+		t.Errorf("tables from the same spec differ: %v", err)
+	}
+
+	spec.Seed = 8
+	if err := Compare(dt, RandomTable(spec)); err == nil {
+		t.Errorf("tables from different seeds are identical")
+	}
+
+	spec.Cardinality = 3
+	spec.NaNFraction = 0
+	dt = RandomTable(spec)
+	if u := dt.Unique(); u.Len() > 3*3*3*3 {
+		t.Errorf("got %d unique rows, wanted at most 81", u.Len())
+	}
+}
+
+func TestCheckCSVRoundTrip(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		dt := RandomTable(Spec{Rows: 20, NumericCols: 3, StringCols: 2, NaNFraction: 0.2, Seed: seed})
+		if err := CheckCSVRoundTrip(dt); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+func TestCheckJSONRoundTrip(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		dt := RandomTable(Spec{Rows: 20, NumericCols: 3, StringCols: 2, NaNFraction: 0.2, Seed: seed})
+		if err := CheckJSONRoundTrip(dt); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+func FuzzCSVRoundTrip(f *testing.F) {
+	f.Add(int64(1), uint8(10))
+	f.Fuzz(func(t *testing.T, seed int64, rows uint8) {
+		dt := RandomTable(Spec{Rows: int(rows), NumericCols: 2, StringCols: 1, NaNFraction: 0.1, Seed: seed})
+		if err := CheckCSVRoundTrip(dt); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package datatable
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// An Encoding identifies the character encoding of textual input.
+type Encoding int
+
+const (
+	// EncodingAuto requests that the encoding be detected from a byte order
+	// mark or the content of the input.
+	EncodingAuto Encoding = iota
+	EncodingUTF8
+	EncodingUTF16LE
+	EncodingUTF16BE
+	EncodingLatin1      // ISO-8859-1
+	EncodingWindows1252 // Windows Western European code page
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingAuto:
+		return "auto"
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingLatin1:
+		return "ISO-8859-1"
+	case EncodingWindows1252:
+		return "Windows-1252"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// DecodeReader reads all of r and returns a reader over its content transcoded
+// to UTF-8 with any byte order mark removed, together with the encoding of the
+// input. If enc is EncodingAuto the encoding is detected: a byte order mark
+// identifies UTF-8 or UTF-16, otherwise input with a high proportion of zero
+// bytes is taken to be UTF-16, valid UTF-8 is taken to be UTF-8 and anything
+// else is taken to be Windows-1252.
+func DecodeReader(r io.Reader, enc Encoding) (io.Reader, Encoding, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, enc, err
+	}
+
+	if enc == EncodingAuto {
+		enc = detectEncoding(data)
+	}
+
+	switch enc {
+	case EncodingUTF8:
+		data = bytes.TrimPrefix(data, bomUTF8)
+		return bytes.NewReader(data), enc, nil
+	case EncodingUTF16LE:
+		data = bytes.TrimPrefix(data, bomUTF16LE)
+		return strings.NewReader(decodeUTF16(data, false)), enc, nil
+	case EncodingUTF16BE:
+		data = bytes.TrimPrefix(data, bomUTF16BE)
+		return strings.NewReader(decodeUTF16(data, true)), enc, nil
+	case EncodingLatin1:
+		return strings.NewReader(decodeSingleByte(data, nil)), enc, nil
+	case EncodingWindows1252:
+		return strings.NewReader(decodeSingleByte(data, &windows1252)), enc, nil
+	}
+	return nil, enc, fmt.Errorf("unsupported encoding: %v", enc)
+}
+
+func detectEncoding(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16BE
+	}
+
+	// Mostly ASCII text encoded as UTF-16 has a zero in every other byte
+	sample := data
+	if len(sample) > 1024 {
+		sample = sample[:1024]
+	}
+	var evenZeros, oddZeros int
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	pairs := len(sample) / 2
+	if pairs > 0 {
+		if oddZeros > pairs/2 && evenZeros == 0 {
+			return EncodingUTF16LE
+		}
+		if evenZeros > pairs/2 && oddZeros == 0 {
+			return EncodingUTF16BE
+		}
+	}
+
+	if utf8.Valid(data) {
+		return EncodingUTF8
+	}
+	return EncodingWindows1252
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeSingleByte decodes data in which each byte is a character. Bytes in
+// the range 0x80-0x9F are mapped using high if supplied, otherwise each byte
+// is the Unicode code point of the same value, as in ISO-8859-1.
+func decodeSingleByte(data []byte, high *[32]rune) string {
+	var sb strings.Builder
+	sb.Grow(len(data))
+	for _, b := range data {
+		if high != nil && b >= 0x80 && b < 0xA0 {
+			sb.WriteRune(high[b-0x80])
+			continue
+		}
+		sb.WriteRune(rune(b))
+	}
+	return sb.String()
+}
+
+// windows1252 maps the bytes 0x80-0x9F, where Windows-1252 differs from
+// ISO-8859-1. Unassigned bytes map to the replacement character.
+var windows1252 = [32]rune{
+	'€', '�', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '�', 'Ž', '�',
+	'�', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '�', 'ž', 'Ÿ',
+}
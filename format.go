@@ -0,0 +1,85 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// A FloatFormat controls how numeric values are written by CSV and other
+// text exports. Tables without a format write values using the %v verb.
+type FloatFormat struct {
+	// Decimals is the number of digits written after the decimal point. A
+	// negative value writes the fewest digits that represent the value exactly.
+	Decimals int
+
+	// SciThreshold is the magnitude at or above which values are written in
+	// scientific notation. Non-zero values with a magnitude below the
+	// reciprocal of SciThreshold are also written in scientific notation.
+	// Zero writes all values without an exponent.
+	SciThreshold float64
+}
+
+// Format formats v according to f. NaN and infinite values are written as
+// NaN, +Inf and -Inf.
+func (f FloatFormat) Format(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	prec := f.Decimals
+	if prec < 0 {
+		prec = -1
+	}
+	if f.SciThreshold > 0 {
+		if a := math.Abs(v); a >= f.SciThreshold || (a != 0 && a < 1/f.SciThreshold) {
+			return strconv.FormatFloat(v, 'e', prec, 64)
+		}
+	}
+	return strconv.FormatFloat(v, 'f', prec, 64)
+}
+
+// SetFloatFormat sets the format used to write numeric values for all columns
+// that do not have a format set by SetColumnFloatFormat.
+func (dt *DataTable) SetFloatFormat(f FloatFormat) {
+	dt.floatFormat = &f
+}
+
+// SetColumnFloatFormat sets the format used to write values of the named
+// numeric column.
+func (dt *DataTable) SetColumnFloatFormat(name string, f FloatFormat) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if !dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	if dt.colFloatFormats == nil {
+		dt.colFloatFormats = map[string]FloatFormat{}
+	}
+	dt.colFloatFormats[name] = f
+	return nil
+}
+
+// formatFloat formats the value v held in row n of column c for text export.
+func (dt *DataTable) formatFloat(c, n int, v float64) string {
+	if f, exists := dt.colFloatFormats[dt.colnames[c]]; exists {
+		return f.Format(v)
+	}
+	if cv := dt.cols[c]; cv.layouts != nil && !math.IsNaN(v) {
+		return time.Unix(0, cv.d[n]).UTC().Format(cv.layouts[0])
+	}
+	if cv := dt.cols[c]; cv.d != nil && !math.IsNaN(v) {
+		return FormatDecimal(cv.d[n], cv.scale)
+	}
+	if dt.floatFormat != nil {
+		return dt.floatFormat.Format(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
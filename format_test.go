@@ -0,0 +1,50 @@
+package datatable
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestFloatFormat(t *testing.T) {
+	testCases := []struct {
+		f        FloatFormat
+		v        float64
+		expected string
+	}{
+		{FloatFormat{Decimals: 2}, 0.30000000000000004, "0.30"},
+		{FloatFormat{Decimals: -1}, 0.30000000000000004, "0.30000000000000004"},
+		{FloatFormat{Decimals: 0}, 2.5, "2"},
+		{FloatFormat{Decimals: 2, SciThreshold: 1e6}, 1234567, "1.23e+06"},
+		{FloatFormat{Decimals: 2, SciThreshold: 1e6}, 0.0000001, "1.00e-07"},
+		{FloatFormat{Decimals: 2, SciThreshold: 1e6}, 0, "0.00"},
+		{FloatFormat{Decimals: 2}, math.NaN(), "NaN"},
+		{FloatFormat{Decimals: 2}, math.Inf(-1), "-Inf"},
+	}
+	for _, tc := range testCases {
+		if got := tc.f.Format(tc.v); got != tc.expected {
+			t.Errorf("%+v.Format(%v): got %q, wanted %q", tc.f, tc.v, got, tc.expected)
+		}
+	}
+}
+
+func TestCSVFloatFormat(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("a", []float64{0.30000000000000004})
+	dt.AddColumn("b", []float64{1.0 / 3})
+	dt.AddColumn("c", []float64{1.5})
+	dt.SetFloatFormat(FloatFormat{Decimals: 2})
+	dt.SetColumnFloatFormat("b", FloatFormat{Decimals: 4})
+
+	buf := new(bytes.Buffer)
+	if err := dt.CSV(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "a,b,c\n0.30,0.3333,1.50\n"; buf.String() != expected {
+		t.Errorf("got %q, wanted %q", buf.String(), expected)
+	}
+
+	if err := dt.SetColumnFloatFormat("nope", FloatFormat{}); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
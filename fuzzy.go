@@ -0,0 +1,178 @@
+package datatable
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A SimilarityFunc returns a measure of how similar two strings are, from 0
+// for completely different strings to 1 for identical strings.
+type SimilarityFunc func(a, b string) float64
+
+// FuzzyJoin returns a new table joining the table with dt2 on approximately
+// equal values of the left string column of the table and the right string
+// column of dt2, such as reconciling names that were entered differently in
+// two datasets. The result holds a row for each pair of rows, one from each
+// table, whose values have a similarity of at least threshold according to
+// sim. Its columns are those of the table followed by those of dt2. An error
+// is returned if any column of dt2 has the same name as a column of the
+// table. Rows are ordered by their position in the table and then in dt2.
+//
+// To avoid comparing every pair of rows only values that share a pair of
+// adjacent characters, ignoring case, are compared, so pairs with no letters
+// in common in the same order are never joined whatever the threshold.
+func (dt *DataTable) FuzzyJoin(dt2 *DataTable, left, right string, sim SimilarityFunc, threshold float64) (*DataTable, error) {
+	c, err := dt.stringCol(left)
+	if err != nil {
+		return nil, err
+	}
+	c2, err := dt2.stringCol(right)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := map[string][]int{}
+	for j, v := range dt2.cols[c2].s {
+		for _, b := range bigrams(v) {
+			blocks[b] = append(blocks[b], j)
+		}
+	}
+
+	var li, ri []int
+	for i, v := range dt.cols[c].s {
+		seen := map[int]bool{}
+		var candidates []int
+		for _, b := range bigrams(v) {
+			for _, j := range blocks[b] {
+				if !seen[j] {
+					seen[j] = true
+					candidates = append(candidates, j)
+				}
+			}
+		}
+		sort.Ints(candidates)
+		for _, j := range candidates {
+			if sim(v, dt2.cols[c2].s[j]) >= threshold {
+				li = append(li, i)
+				ri = append(ri, j)
+			}
+		}
+	}
+	return joinRows(dt, dt2, nil, li, ri)
+}
+
+// stringCol looks up the position of the named string column.
+func (dt *DataTable) stringCol(name string) (int, error) {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return 0, fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.isFloatCol(c) {
+		return 0, ErrMismatchedColumnTypes
+	}
+	return c, nil
+}
+
+// bigrams returns the distinct pairs of adjacent characters of s in lower
+// case, including the pairs formed with a space at each end so that single
+// characters still have bigrams.
+func bigrams(s string) []string {
+	r := []rune(" " + strings.ToLower(s) + " ")
+	seen := make(map[string]bool, len(r))
+	var ret []string
+	for i := 1; i < len(r); i++ {
+		b := string(r[i-1 : i+1])
+		if !seen[b] {
+			seen[b] = true
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
+// Levenshtein is a SimilarityFunc giving one minus the number of single
+// character insertions, deletions and substitutions needed to change a into
+// b divided by the length of the longer string. It is case sensitive.
+func Levenshtein(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// JaroWinkler is a SimilarityFunc giving the Jaro-Winkler similarity of a and
+// b, which favours strings that match from the beginning and so suits short
+// strings such as names. It is case sensitive.
+func JaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	ma := make([]bool, len(ra))
+	mb := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		for j := max(0, i-window); j < min(len(rb), i+window+1); j++ {
+			if !mb[j] && ra[i] == rb[j] {
+				ma[i], mb[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !ma[i] {
+			continue
+		}
+		for !mb[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
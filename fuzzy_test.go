@@ -0,0 +1,62 @@
+package datatable
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	testCases := []struct {
+		sim      SimilarityFunc
+		a, b     string
+		expected float64
+	}{
+		{Levenshtein, "kitten", "sitting", 1 - 3.0/7},
+		{Levenshtein, "", "", 1},
+		{Levenshtein, "abc", "", 0},
+		{Levenshtein, "café", "cafe", 0.75},
+		{JaroWinkler, "MARTHA", "MARHTA", 0.9611},
+		{JaroWinkler, "DIXON", "DICKSONX", 0.8133},
+		{JaroWinkler, "abc", "abc", 1},
+		{JaroWinkler, "abc", "xyz", 0},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.sim(tc.a, tc.b); math.Abs(got-tc.expected) > 1e-4 {
+			t.Errorf("similarity of %q and %q: got %v, wanted %v", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
+
+func TestFuzzyJoin(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("name", []string{"Acme Ltd", "Globex Corp", "Initech"})
+	dt.AddColumn("sales", []float64{1, 2, 3})
+
+	dt2 := &DataTable{}
+	dt2.AddStringColumn("company", []string{"Initech Inc", "ACME Ltd.", "Globex Corporation", "Acme Ltd"})
+	dt2.AddStringColumn("region", []string{"n", "s", "e", "w"})
+
+	joined, err := dt.FuzzyJoin(dt2, "name", "company", JaroWinkler, 0.9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"name", "sales", "company", "region"}; !reflect.DeepEqual(joined.Names(), expected) {
+		t.Errorf("got columns %v, wanted %v", joined.Names(), expected)
+	}
+	if expected := []string{"Acme Ltd", "Globex Corp", "Initech"}; !reflect.DeepEqual(joined.cols[0].s, expected) {
+		t.Errorf("got %v, wanted %v", joined.cols[0].s, expected)
+	}
+	if expected := []string{"Acme Ltd", "Globex Corporation", "Initech Inc"}; !reflect.DeepEqual(joined.cols[2].s, expected) {
+		t.Errorf("got %v, wanted %v", joined.cols[2].s, expected)
+	}
+
+	if _, err := dt.FuzzyJoin(dt2, "sales", "company", Levenshtein, 0.5); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if _, err := dt.FuzzyJoin(dt2, "name", "missing", Levenshtein, 0.5); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
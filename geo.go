@@ -0,0 +1,159 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// earthRadius is the mean radius of the Earth in kilometres.
+const earthRadius = 6371.0088
+
+// HaversineDistance returns a Calculator that computes the great circle
+// distance in kilometres between the point whose latitude and longitude, in
+// degrees, are in columns latA and lonA and the point in columns latB and
+// lonB. NaN is returned if any value is missing.
+func HaversineDistance(latA, lonA, latB, lonB string) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 {
+		var v [4]float64
+		for i, name := range []string{latA, lonA, latB, lonB} {
+			f, exists := row.FloatValue(name)
+			if !exists {
+				return math.NaN()
+			}
+			v[i] = f
+		}
+		return haversine(v[0], v[1], v[2], v[3])
+	}), callExpr("HaversineDistance", latA, lonA, latB, lonB), latA, lonA, latB, lonB)
+}
+
+// haversine returns the great circle distance in kilometres between two
+// points given in degrees.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dlat := (lat2 - lat1) * rad
+	dlon := (lon2 - lon1) * rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(math.Min(1, a)))
+}
+
+// NearestJoin returns a new table joining each row of the table with the k
+// rows of dt2 nearest to it, such as finding the closest stores to each
+// customer. Both tables must have numeric latCol and lonCol columns holding
+// latitudes and longitudes in degrees, and distances are measured with the
+// haversine formula. The result has the columns of the table followed by the
+// columns of dt2 other than latCol and lonCol and a numeric column named
+// distance holding the distance between the points in kilometres. An error is
+// returned if any of those columns has the same name as a column of the
+// table. Rows are ordered by their position in the table and then by
+// increasing distance, with ties broken by position in dt2. Rows of either
+// table with a missing latitude or longitude are not joined and rows of the
+// table have fewer than k partners if dt2 has fewer than k rows.
+func (dt *DataTable) NearestJoin(dt2 *DataTable, latCol, lonCol string, k int) (*DataTable, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("invalid number of neighbours: %d", k)
+	}
+	lat, lon, err := dt.pointCols(latCol, lonCol)
+	if err != nil {
+		return nil, err
+	}
+	lat2, lon2, err := dt2.pointCols(latCol, lonCol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order the points of dt2 by latitude so the search for each row can
+	// stop once the difference in latitude alone rules out closer points.
+	var byLat []int
+	for j := 0; j < dt2.Len(); j++ {
+		if !math.IsNaN(lat2[j]) && !math.IsNaN(lon2[j]) {
+			byLat = append(byLat, j)
+		}
+	}
+	sort.SliceStable(byLat, func(a, b int) bool { return lat2[byLat[a]] < lat2[byLat[b]] })
+
+	type neighbour struct {
+		row  int
+		dist float64
+	}
+	var li, ri []int
+	var dists []float64
+	for i := 0; i < dt.Len(); i++ {
+		if math.IsNaN(lat[i]) || math.IsNaN(lon[i]) {
+			continue
+		}
+		var best []neighbour // the nearest points so far, in order
+		consider := func(p int) bool {
+			j := byLat[p]
+			if len(best) == k && earthRadius*math.Abs(lat2[j]-lat[i])*math.Pi/180 > best[k-1].dist {
+				return false
+			}
+			n := neighbour{row: j, dist: haversine(lat[i], lon[i], lat2[j], lon2[j])}
+			pos := sort.Search(len(best), func(x int) bool {
+				return best[x].dist > n.dist || best[x].dist == n.dist && best[x].row > n.row
+			})
+			if pos < k {
+				if len(best) < k {
+					best = append(best, neighbour{})
+				}
+				copy(best[pos+1:], best[pos:])
+				best[pos] = n
+			}
+			return true
+		}
+
+		start := sort.Search(len(byLat), func(p int) bool { return lat2[byLat[p]] >= lat[i] })
+		for up, down := start, start-1; up < len(byLat) || down >= 0; {
+			if up < len(byLat) {
+				if consider(up) {
+					up++
+				} else {
+					up = len(byLat)
+				}
+			}
+			if down >= 0 {
+				if consider(down) {
+					down--
+				} else {
+					down = -1
+				}
+			}
+		}
+
+		for _, n := range best {
+			li = append(li, i)
+			ri = append(ri, n.row)
+			dists = append(dists, n.dist)
+		}
+	}
+
+	ret, err := joinRows(dt, dt2, []string{latCol, lonCol}, li, ri)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := ret.colorder["distance"]; exists {
+		return nil, fmt.Errorf("duplicate column: distance")
+	}
+	if dists == nil {
+		dists = []float64{}
+	}
+	ret.addColumn("distance", colvals{f: dists})
+	return ret, nil
+}
+
+// pointCols returns the values of the named numeric latitude and longitude
+// columns.
+func (dt *DataTable) pointCols(latCol, lonCol string) ([]float64, []float64, error) {
+	var vals [2][]float64
+	for i, name := range []string{latCol, lonCol} {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil, nil, fmt.Errorf("unknown column: %s", name)
+		}
+		if !dt.isFloatCol(c) {
+			return nil, nil, ErrMismatchedColumnTypes
+		}
+		vals[i] = dt.cols[c].f
+	}
+	return vals[0], vals[1], nil
+}
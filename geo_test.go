@@ -0,0 +1,77 @@
+package datatable
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestHaversineDistance(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("lat1", []float64{51.5074, 0, math.NaN()})
+	dt.AddColumn("lon1", []float64{-0.1278, 0, 0})
+	dt.AddColumn("lat2", []float64{48.8566, 0, 0})
+	dt.AddColumn("lon2", []float64{2.3522, 180, 0})
+	dt.Calc("d", HaversineDistance("lat1", "lon1", "lat2", "lon2"))
+
+	d := dt.cols[dt.colorder["d"]].f
+	expected := []float64{343.56, math.Pi * earthRadius, math.NaN()}
+	for i := range expected {
+		if math.IsNaN(expected[i]) {
+			if !math.IsNaN(d[i]) {
+				t.Errorf("row %d: got %v, wanted NaN", i, d[i])
+			}
+			continue
+		}
+		if math.Abs(d[i]-expected[i]) > 0.1 {
+			t.Errorf("row %d: got %v, wanted %v", i, d[i], expected[i])
+		}
+	}
+}
+
+func TestNearestJoin(t *testing.T) {
+	customers := &DataTable{}
+	customers.AddStringColumn("customer", []string{"c1", "c2", "c3"})
+	customers.AddColumn("lat", []float64{51.50, 55.95, math.NaN()})
+	customers.AddColumn("lon", []float64{-0.12, -3.19, 0})
+
+	stores := &DataTable{}
+	stores.AddStringColumn("store", []string{"edinburgh", "london", "glasgow", "brighton", "nowhere"})
+	stores.AddColumn("lat", []float64{55.95, 51.51, 55.86, 50.82, math.NaN()})
+	stores.AddColumn("lon", []float64{-3.19, -0.13, -4.25, -0.14, 0})
+
+	joined, err := customers.NearestJoin(stores, "lat", "lon", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"customer", "lat", "lon", "store", "distance"}; !reflect.DeepEqual(joined.Names(), expected) {
+		t.Errorf("got columns %v, wanted %v", joined.Names(), expected)
+	}
+	c := joined.cols[joined.colorder["customer"]].s
+	s := joined.cols[joined.colorder["store"]].s
+	if expected := []string{"c1", "c1", "c2", "c2"}; !reflect.DeepEqual(c, expected) {
+		t.Errorf("got customers %v, wanted %v", c, expected)
+	}
+	if expected := []string{"london", "brighton", "edinburgh", "glasgow"}; !reflect.DeepEqual(s, expected) {
+		t.Errorf("got stores %v, wanted %v", s, expected)
+	}
+
+	// asking for more neighbours than there are points joins every point
+	joined, _ = customers.NearestJoin(stores, "lat", "lon", 10)
+	if joined.Len() != 8 {
+		t.Errorf("got %d rows, wanted 8", joined.Len())
+	}
+	d := joined.cols[joined.colorder["distance"]].f
+	for i := 1; i < 8; i++ {
+		if i != 4 && d[i] < d[i-1] {
+			t.Errorf("distances not increasing: %v", d)
+		}
+	}
+
+	if _, err := customers.NearestJoin(stores, "lat", "customer", 1); err == nil {
+		t.Errorf("got no error for string column")
+	}
+	if _, err := customers.NearestJoin(stores, "lat", "lon", 0); err == nil {
+		t.Errorf("got no error for zero neighbours")
+	}
+}
@@ -0,0 +1,123 @@
+package datatable
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// A Format is a file format that tables can be read from.
+type Format int
+
+const (
+	FormatCSV  Format = iota // read with ReadCSV
+	FormatJSON               // read with FromJSON
+)
+
+// GlobOptions controls how ReadGlob loads files.
+type GlobOptions struct {
+	// CSV holds the options used to read files in FormatCSV. Its
+	// DetectedEncoding is ignored, since files may differ in encoding.
+	CSV CSVOptions
+
+	// SourceColumn is the name of the string column added to hold the path
+	// of the file each row was read from. The default is source.
+	SourceColumn string
+
+	// Workers is the number of files read at once. The default reads one
+	// file at a time.
+	Workers int
+}
+
+// ReadGlob reads every file whose path matches pattern, using the syntax of
+// filepath.Match, in the given format and appends them into a single table,
+// such as when loading a directory of daily exports. The files are appended in
+// lexical order of their paths, whatever the number of workers, and a column
+// is added holding the path of the file each row came from. Columns missing
+// from some files are null in their rows. An error is returned if no files
+// match or any file cannot be read, naming the file.
+func ReadGlob(pattern string, format Format, opts GlobOptions) (*DataTable, error) {
+	if format != FormatCSV && format != FormatJSON {
+		return nil, fmt.Errorf("unknown format: %d", format)
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match: %s", pattern)
+	}
+	source := opts.SourceColumn
+	if source == "" {
+		source = "source"
+	}
+	workers := max(opts.Workers, 1)
+	opts.CSV.DetectedEncoding = nil
+
+	tables := make([]*DataTable, len(paths))
+	errs := make([]error, len(paths))
+	files := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range files {
+				tables[i], errs[i] = readFile(paths[i], format, opts.CSV, source)
+			}
+		}()
+	}
+	for i := range paths {
+		files <- i
+	}
+	close(files)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", paths[i], err)
+		}
+	}
+	dt := tables[0]
+	for i, t := range tables[1:] {
+		if err := dt.Append(t); err != nil {
+			return nil, fmt.Errorf("appending %s: %w", paths[i+1], err)
+		}
+	}
+	return dt, nil
+}
+
+// readFile reads a table from the file at path and adds a column named source
+// holding the path.
+func readFile(path string, format Format, opts CSVOptions, source string) (*DataTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var read func(io.Reader) (*DataTable, error)
+	switch format {
+	case FormatCSV:
+		read = func(r io.Reader) (*DataTable, error) { return ReadCSV(r, opts) }
+	case FormatJSON:
+		read = FromJSON
+	}
+	dt, err := read(f)
+	if err != nil {
+		return nil, err
+	}
+	if dt.HasColumn(source) {
+		return nil, fmt.Errorf("duplicate column: %s", source)
+	}
+	paths := make([]string, dt.Len())
+	for i := range paths {
+		paths[i] = path
+	}
+	if err := dt.AddStringColumn(source, paths); err != nil {
+		return nil, err
+	}
+	return dt, nil
+}
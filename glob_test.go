@@ -0,0 +1,56 @@
+package datatable
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"2024-01-02.csv": "a,b\nz,3\n",
+		"2024-01-01.csv": "a,b\nx,1\ny,2\n",
+		"2024-01-03.csv": "a,c\nw,q\n",
+		"notes.txt":      "not a table",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	first := filepath.Join(dir, "2024-01-01.csv")
+	second := filepath.Join(dir, "2024-01-02.csv")
+	third := filepath.Join(dir, "2024-01-03.csv")
+
+	for _, workers := range []int{0, 3} {
+		dt, err := ReadGlob(filepath.Join(dir, "*.csv"), FormatCSV, GlobOptions{Workers: workers, SourceColumn: "file"})
+		if err != nil {
+			t.Fatalf("workers %d: unexpected error: %v", workers, err)
+		}
+		want := [][]interface{}{
+			{"a", "b", "file", "c"},
+			{"x", 1.0, first, ""},
+			{"y", 2.0, first, ""},
+			{"z", 3.0, second, ""},
+			{"w", math.NaN(), third, "q"},
+		}
+		if rows := dt.RawRows(true); !equivalentRows(rows, want) {
+			t.Errorf("workers %d: got %v, wanted %v", workers, rows, want)
+		}
+		if n, _ := dt.NullCount("b"); n != 1 {
+			t.Errorf("workers %d: got %d null values of b, wanted 1", workers, n)
+		}
+	}
+
+	if _, err := ReadGlob(filepath.Join(dir, "*.json"), FormatJSON, GlobOptions{}); err == nil {
+		t.Errorf("got no error for pattern without matches")
+	}
+	if _, err := ReadGlob(filepath.Join(dir, "*.txt"), FormatJSON, GlobOptions{}); err == nil {
+		t.Errorf("got no error for invalid file")
+	}
+	if _, err := ReadGlob(filepath.Join(dir, "*.csv"), FormatCSV, GlobOptions{SourceColumn: "a"}); err == nil {
+		t.Errorf("got no error for duplicate source column")
+	}
+}
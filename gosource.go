@@ -0,0 +1,124 @@
+package datatable
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GoString returns Go source code for an expression that reconstructs the
+// table, including its null values, time layouts and keys. It implements
+// fmt.GoStringer so the table may be printed with the %#v verb. The expression
+// refers to the package as datatable. It requires the math package if the
+// table contains NaN or infinite values and the time package if it has time
+// columns.
+func (dt *DataTable) GoString() string {
+	var b strings.Builder
+	b.WriteString("func() *datatable.DataTable {\n")
+	b.WriteString("\tdt := &datatable.DataTable{}\n")
+	var calls []string // made once all the columns have been added
+	for c, name := range dt.colnames {
+		cv := &dt.cols[c]
+		quoted := strconv.Quote(name)
+		switch {
+		case cv.layouts != nil:
+			fmt.Fprintf(&b, "\tdt.AddTimeColumn(%s, []time.Time{", quoted)
+			for i, v := range cv.f {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				if math.IsNaN(v) {
+					b.WriteString("{}")
+				} else {
+					fmt.Fprintf(&b, "time.Unix(0, %d).UTC()", cv.d[i])
+				}
+			}
+			b.WriteString("})\n")
+			if len(cv.layouts) != 1 || cv.layouts[0] != DefaultTimeLayout {
+				args := make([]string, len(cv.layouts))
+				for i := range cv.layouts {
+					args[i] = strconv.Quote(cv.layouts[i])
+				}
+				calls = append(calls, fmt.Sprintf("dt.SetTimeLayouts(%s, %s)", quoted, strings.Join(args, ", ")))
+			}
+		case cv.d != nil:
+			if cv.integer {
+				fmt.Fprintf(&b, "\tdt.AddIntColumn(%s, []int64{", quoted)
+			} else {
+				fmt.Fprintf(&b, "\tdt.AddDecimalColumn(%s, []int64{", quoted)
+			}
+			for i, u := range cv.d {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(strconv.FormatInt(u, 10))
+				if math.IsNaN(cv.f[i]) && !cv.isNull(i) {
+					calls = append(calls, fmt.Sprintf("dt.SetFloatValue(%s, %d, math.NaN())", quoted, i))
+				}
+			}
+			if cv.integer {
+				b.WriteString("})\n")
+			} else {
+				fmt.Fprintf(&b, "}, %d)\n", cv.scale)
+			}
+		case cv.f != nil:
+			fmt.Fprintf(&b, "\tdt.AddColumn(%s, []float64{", quoted)
+			for i, v := range cv.f {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(goFloat(v))
+			}
+			b.WriteString("})\n")
+		default:
+			fmt.Fprintf(&b, "\tdt.AddStringColumn(%s, []string{", quoted)
+			for i, v := range cv.s {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(strconv.Quote(v))
+			}
+			b.WriteString("})\n")
+		}
+		for i := range cv.null {
+			if cv.null[i] {
+				calls = append(calls, fmt.Sprintf("dt.SetNull(%s, %d)", quoted, i))
+			}
+		}
+	}
+	for _, call := range calls {
+		fmt.Fprintf(&b, "\t%s\n", call)
+	}
+	if names := dt.KeyNames(); len(names) > 0 {
+		quoted := make([]string, len(names))
+		for i := range names {
+			quoted[i] = strconv.Quote(names[i])
+		}
+		fmt.Fprintf(&b, "\tdt.SetKeys(%s)\n", strings.Join(quoted, ", "))
+	}
+	b.WriteString("\treturn dt\n")
+	b.WriteString("}()")
+	return b.String()
+}
+
+// WriteGoFixture writes a Go variable declaration named varName whose value
+// reconstructs the table, for embedding expected results in tests.
+func (dt *DataTable) WriteGoFixture(w io.Writer, varName string) error {
+	_, err := fmt.Fprintf(w, "var %s = %s\n", varName, dt.GoString())
+	return err
+}
+
+// goFloat formats v as a Go expression.
+func goFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "math.NaN()"
+	case math.IsInf(v, 1):
+		return "math.Inf(1)"
+	case math.IsInf(v, -1):
+		return "math.Inf(-1)"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
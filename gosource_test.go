@@ -0,0 +1,86 @@
+package datatable
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGoString(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("n", []float64{2, 1.5, math.NaN()})
+	dt.AddStringColumn("s", []string{"b", "a \"q\"", ""})
+	dt.SetKeys("s")
+
+	expected := `func() *datatable.DataTable {
+	dt := &datatable.DataTable{}
+	dt.AddColumn("n", []float64{math.NaN(), 1.5, 2})
+	dt.AddStringColumn("s", []string{"", "a \"q\"", "b"})
+	dt.SetKeys("s")
+	return dt
+}()`
+	if got := fmt.Sprintf("%#v", dt); got != expected {
+		t.Errorf("got:\n%s\nwanted:\n%s", got, expected)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := dt.WriteGoFixture(buf, "expected"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buf.String(); got != "var expected = "+expected+"\n" {
+		t.Errorf("got:\n%s", got)
+	}
+}
+
+func TestGoStringColumnTypes(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddIntColumn("i", []int64{1 << 60, 0, 3})
+	dt.AddDecimalColumn("d", []int64{105, 0, -2}, 2)
+	dt.AddTimeColumn("t", []time.Time{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), {}, time.Unix(1, 0)})
+	dt.AddStringColumn("s", []string{"a", "", "c"})
+	dt.SetFloatValue("i", 1, math.NaN())
+	dt.SetNull("d", 1)
+	dt.SetNull("s", 1)
+	dt.SetTimeLayouts("t", "2006-01-02")
+
+	expected := `func() *datatable.DataTable {
+	dt := &datatable.DataTable{}
+	dt.AddIntColumn("i", []int64{1152921504606846976, 0, 3})
+	dt.AddDecimalColumn("d", []int64{105, 0, -2}, 2)
+	dt.AddTimeColumn("t", []time.Time{time.Unix(0, 1704153600000000000).UTC(), {}, time.Unix(0, 1000000000).UTC()})
+	dt.AddStringColumn("s", []string{"a", "", "c"})
+	dt.SetFloatValue("i", 1, math.NaN())
+	dt.SetNull("d", 1)
+	dt.SetTimeLayouts("t", "2006-01-02")
+	dt.SetNull("s", 1)
+	return dt
+}()`
+	if got := dt.GoString(); got != expected {
+		t.Errorf("got:\n%s\nwanted:\n%s", got, expected)
+	}
+
+	// the expression rebuilds the same table
+	rebuilt := func() *DataTable {
+		dt := &DataTable{}
+		dt.AddIntColumn("i", []int64{1152921504606846976, 0, 3})
+		dt.AddDecimalColumn("d", []int64{105, 0, -2}, 2)
+		dt.AddTimeColumn("t", []time.Time{time.Unix(0, 1704153600000000000).UTC(), {}, time.Unix(0, 1000000000).UTC()})
+		dt.AddStringColumn("s", []string{"a", "", "c"})
+		dt.SetFloatValue("i", 1, math.NaN())
+		dt.SetNull("d", 1)
+		dt.SetTimeLayouts("t", "2006-01-02")
+		dt.SetNull("s", 1)
+		return dt
+	}()
+	if got := rebuilt.GoString(); got != expected {
+		t.Errorf("rebuilt table gives:\n%s", got)
+	}
+	var want, have bytes.Buffer
+	dt.CSV(&want)
+	rebuilt.CSV(&have)
+	if have.String() != want.String() {
+		t.Errorf("rebuilt table has CSV:\n%s\nwanted:\n%s", have.String(), want.String())
+	}
+}
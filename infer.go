@@ -0,0 +1,141 @@
+package datatable
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An InferredType is the type InferTypes decided a string column holds.
+type InferredType int
+
+const (
+	// InferredText means the column was left as text.
+	InferredText InferredType = iota
+
+	// InferredNumber means the column was converted to numeric values.
+	InferredNumber
+
+	// InferredBool means the column held true or false and was converted to
+	// numeric values of 1 and 0.
+	InferredBool
+
+	// InferredDate means the column held dates and was converted to numeric
+	// values holding seconds since the Unix epoch.
+	InferredDate
+)
+
+func (t InferredType) String() string {
+	switch t {
+	case InferredText:
+		return "text"
+	case InferredNumber:
+		return "number"
+	case InferredBool:
+		return "bool"
+	case InferredDate:
+		return "date"
+	}
+	return fmt.Sprintf("InferredType(%d)", int(t))
+}
+
+// A TypeInference reports the decision InferTypes made for a single column.
+type TypeInference struct {
+	Name string
+	Type InferredType
+
+	// Rejected is the first value that prevented the column being converted
+	// to the type suggested by the sample, or empty if there was none.
+	Rejected string
+}
+
+// InferTypes examines each string column and converts those whose non-empty
+// values are all numbers, booleans or dates into numeric columns, reading
+// empty values as NaN. Numbers and dates are parsed according to the locale of
+// the column. The type of a column is chosen by examining at most sampleRows
+// rows, or every row if sampleRows is zero or negative. A column that contains
+// a value outside the sample that does not match the chosen type is left as
+// text. The returned slice reports the decision made for each string column in
+// column order. If a key column is converted the table is sorted again.
+func (dt *DataTable) InferTypes(sampleRows int) []TypeInference {
+	var ret []TypeInference
+	resort, changed := false, false
+	for c := range dt.cols {
+		if dt.isFloatCol(c) {
+			continue
+		}
+		inf := TypeInference{Name: dt.colnames[c]}
+		values := dt.cols[c].s
+		sample := values
+		if sampleRows > 0 && sampleRows < len(sample) {
+			sample = sample[:sampleRows]
+		}
+
+		l := dt.columnLocale(c)
+		for _, cand := range []struct {
+			typ   InferredType
+			parse func(string) (float64, error)
+		}{
+			{InferredNumber, l.ParseFloat},
+			{InferredBool, parseBool},
+			{InferredDate, l.parseDateSeconds},
+		} {
+			if _, ok := parseFloats(sample, cand.parse); !ok {
+				continue
+			}
+			if f, ok := parseFloats(values, cand.parse); ok {
+				inf.Type = cand.typ
+				dt.cols[c] = colvals{f: f, null: dt.cols[c].null}
+				dt.markDirty(c)
+				changed = true
+				resort = resort || dt.isKeyCol(c)
+			} else {
+				inf.Rejected = firstRejected(values, cand.parse)
+			}
+			break
+		}
+		ret = append(ret, inf)
+	}
+	if resort {
+		sort.Stable(dt)
+		dt.markAllDirty()
+	}
+	if changed {
+		dt.mutated()
+	}
+	return ret
+}
+
+// isKeyCol reports whether column c is one of the table's keys.
+func (dt *DataTable) isKeyCol(c int) bool {
+	for _, k := range dt.keys {
+		if k == c {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBool parses true or false in any case as 1 or 0.
+func parseBool(s string) (float64, error) {
+	switch {
+	case strings.EqualFold(s, "true"):
+		return 1, nil
+	case strings.EqualFold(s, "false"):
+		return 0, nil
+	}
+	return 0, fmt.Errorf("invalid boolean: %q", s)
+}
+
+// firstRejected returns the first non-empty value that parse cannot parse.
+func firstRejected(values []string, parse func(string) (float64, error)) string {
+	for _, s := range values {
+		if s == "" {
+			continue
+		}
+		if _, err := parse(s); err != nil {
+			return s
+		}
+	}
+	return ""
+}
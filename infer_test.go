@@ -0,0 +1,50 @@
+package datatable
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestInferTypes(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("num", []string{"1.5", "", "3"})
+	dt.AddStringColumn("flag", []string{"true", "FALSE", "True"})
+	dt.AddStringColumn("when", []string{"2023-01-02", "2023-01-01", ""})
+	dt.AddStringColumn("text", []string{"a", "1", "b"})
+	dt.AddStringColumn("late", []string{"1", "2", "x"})
+	dt.AddColumn("f", []float64{1, 2, 3})
+
+	got := dt.InferTypes(2)
+	expected := []TypeInference{
+		{Name: "num", Type: InferredNumber},
+		{Name: "flag", Type: InferredBool},
+		{Name: "when", Type: InferredDate},
+		{Name: "text", Type: InferredText},
+		{Name: "late", Type: InferredText, Rejected: "x"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got %+v, wanted %+v", got, expected)
+	}
+
+	expectedRows := [][]interface{}{
+		{1.5, 1.0, 1672617600.0, "a", "1", 1.0},
+		{math.NaN(), 0.0, 1672531200.0, "1", "2", 2.0},
+		{3.0, 1.0, math.NaN(), "b", "x", 3.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
+
+func TestInferTypesResortsKeys(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"10", "9", "100"})
+	dt.SetKeys("k")
+
+	dt.InferTypes(0)
+	expectedRows := [][]interface{}{{9.0}, {10.0}, {100.0}}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
@@ -0,0 +1,77 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// AddIntColumn adds an integer column holding vals. Integer columns are
+// numeric columns, readable with FloatValue and usable anywhere a numeric
+// column is, but also keep their exact values so that counts and identifiers
+// above 2^53, which cannot be held exactly as floats, still sort, group and
+// join correctly. The exact values are available from IntValue, IntValues and
+// CSV, and may be summed exactly with DecimalSum and SumDecimal. Numbers
+// stored in an integer column by other operations are rounded to the nearest
+// integer and NaN values are held exactly as zero.
+func (dt *DataTable) AddIntColumn(name string, vals []int64) error {
+	if len(dt.cols) != 0 && len(vals) != dt.Len() {
+		return ErrInvalidColumnLength
+	}
+	cv := colvals{
+		f:       make([]float64, len(vals)),
+		d:       make([]int64, len(vals)),
+		integer: true,
+	}
+	copy(cv.d, vals)
+	for i, v := range vals {
+		cv.f[i] = float64(v)
+	}
+	dt.addColumn(name, cv)
+	dt.mutated()
+	return nil
+}
+
+// IntValues returns a copy of the exact values of the named integer column.
+// Values that are NaN are returned as zero.
+func (dt *DataTable) IntValues(name string) ([]int64, error) {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown column: %s", name)
+	}
+	if !dt.cols[c].integer {
+		return nil, ErrMismatchedColumnTypes
+	}
+	return append([]int64(nil), dt.cols[c].d...), nil
+}
+
+// intValue returns the exact value of column c in row n, or false if the
+// column is not an integer column or the value is NaN.
+func (dt *DataTable) intValue(c, n int) (int64, bool) {
+	cv := dt.cols[c]
+	if !cv.integer || math.IsNaN(cv.f[n]) {
+		return 0, false
+	}
+	return cv.d[n], true
+}
+
+// parseInt parses s, written according to the locale, as an integer,
+// returning its exact value and the value as a float. An empty string is read
+// as NaN. Values with a non-zero fractional part are rejected.
+func (l Locale) parseInt(s string) (int64, float64, error) {
+	if s == "" {
+		return 0, math.NaN(), nil
+	}
+	dec := "."
+	if l.Decimal != 0 {
+		dec = string(l.Decimal)
+	}
+	if _, frac, ok := strings.Cut(s, dec); ok && strings.Trim(frac, "0") != "" {
+		return 0, 0, fmt.Errorf("invalid integer: %q", s)
+	}
+	u, err := l.parseDecimal(s, 0)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid integer: %q", s)
+	}
+	return u, float64(u), nil
+}
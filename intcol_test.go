@@ -0,0 +1,113 @@
+package datatable
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIntColumn(t *testing.T) {
+	const big = 1 << 60 // float64 cannot distinguish big from big+1
+
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"a", "b", "c"})
+	if err := dt.AddIntColumn("id", []int64{big + 1, big, big + 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dt.ParseRow("d", "7")
+	dt.ParseRow("e", "")
+	if err := dt.ParseRow("f", "2.00"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, _, err := (Locale{}).parseInt("1.5"); err == nil {
+		t.Errorf("got no error for fractional value")
+	}
+
+	if typ, _ := dt.ColumnType("id"); typ != ColInt {
+		t.Errorf("got type %v, wanted %v", typ, ColInt)
+	}
+
+	rr, _ := dt.RowRef(3)
+	if v, ok := rr.IntValue("id"); !ok || v != 7 {
+		t.Errorf("got %d %v, wanted 7 true", v, ok)
+	}
+	rr, _ = dt.RowRef(4)
+	if v, ok := rr.FloatValue("id"); !ok || !math.IsNaN(v) {
+		t.Errorf("got %v, wanted NaN for empty value", v)
+	}
+	if _, ok := rr.IntValue("id"); ok {
+		t.Errorf("got ok for NaN value")
+	}
+	if _, ok := rr.IntValue("k"); ok {
+		t.Errorf("got ok for string column")
+	}
+
+	vals, err := dt.IntValues("id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []int64{big + 1, big, big + 1, 7, 0, 2}; !reflect.DeepEqual(vals, expected) {
+		t.Errorf("got %v, wanted %v", vals, expected)
+	}
+
+	buf := new(bytes.Buffer)
+	dt.CSV(buf)
+	if expected := "k,id\na,1152921504606846977\nb,1152921504606846976\nc,1152921504606846977\nd,7\ne,NaN\nf,2\n"; buf.String() != expected {
+		t.Errorf("got %q, wanted %q", buf.String(), expected)
+	}
+
+	sum, _, err := dt.SumDecimal("id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := int64(3*big + 2 + 9); sum != expected {
+		t.Errorf("got sum %d, wanted %d", sum, expected)
+	}
+
+	if _, err := dt.IntValues("k"); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+}
+
+func TestIntColumnGrouping(t *testing.T) {
+	const big = 1 << 60
+
+	dt := &DataTable{}
+	dt.AddIntColumn("id", []int64{big, big + 1, big})
+	dt.AddColumn("v", []float64{1, 2, 3})
+
+	counts := map[int64]int{}
+	dt.SetKeys("id")
+	dt.keyGroups(func(start, end int) {
+		rr, _ := dt.RowRef(start)
+		id, _ := rr.IntValue("id")
+		counts[id] = end - start
+	})
+	if expected := map[int64]int{big: 2, big + 1: 1}; !reflect.DeepEqual(counts, expected) {
+		t.Errorf("got %v, wanted %v", counts, expected)
+	}
+
+	other := &DataTable{}
+	other.AddIntColumn("id", []int64{big + 1})
+	other.AddStringColumn("name", []string{"x"})
+	joined, err := dt.Join(other, []string{"id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if joined.Len() != 1 {
+		t.Errorf("got %d joined rows, wanted 1", joined.Len())
+	}
+
+	// integer values that are exact floats join with float columns
+	floats := &DataTable{}
+	floats.AddColumn("id", []float64{big})
+	if n, err := dt.RemoveMatchingKeys(floats, []string{"id"}); err != nil || n != 2 {
+		t.Errorf("got %d, %v, wanted 2 rows removed", n, err)
+	}
+
+	if v, ok := (RowMap{"n": int64(3)}).IntValue("n"); !ok || v != 3 {
+		t.Errorf("got %d %v, wanted 3 true", v, ok)
+	}
+}
@@ -0,0 +1,92 @@
+package datatable
+
+import (
+	"fmt"
+)
+
+// An InvariantError reports that an invariant added by AddInvariant does not
+// hold.
+type InvariantError struct {
+	Name string // name of the invariant
+	Err  error  // error returned by the invariant's predicate
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("invariant %s violated: %v", e.Name, e.Err)
+}
+
+func (e *InvariantError) Unwrap() error {
+	return e.Err
+}
+
+// invariant is a named predicate registered with AddInvariant.
+type invariant struct {
+	name string
+	pred func(dt *DataTable) error
+}
+
+// AddInvariant adds a named predicate that describes a property of the table
+// that should always hold, such as two columns having equal sums. The
+// predicate should return a non-nil error describing the problem if the
+// property does not hold. Invariants are checked by CheckInvariants and,
+// when enabled by SetCheckInvariants, after every operation that modifies
+// the table. Adding an invariant with the same name as an existing one
+// replaces it.
+func (dt *DataTable) AddInvariant(name string, pred func(dt *DataTable) error) {
+	for i := range dt.invariants {
+		if dt.invariants[i].name == name {
+			dt.invariants[i].pred = pred
+			return
+		}
+	}
+	dt.invariants = append(dt.invariants, invariant{name: name, pred: pred})
+}
+
+// RemoveInvariant removes the named invariant.
+func (dt *DataTable) RemoveInvariant(name string) {
+	for i := range dt.invariants {
+		if dt.invariants[i].name == name {
+			dt.invariants = append(dt.invariants[:i], dt.invariants[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetCheckInvariants controls whether invariants are checked after every
+// operation that modifies the table. When enabled, a modification that
+// leaves the table violating an invariant panics with an *InvariantError,
+// which locates the operation that corrupted the table. Checking is disabled
+// by default since it can be expensive.
+func (dt *DataTable) SetCheckInvariants(check bool) {
+	dt.checkInvariants = check
+}
+
+// CheckInvariants evaluates each invariant in the order they were added and
+// returns an *InvariantError for the first that does not hold.
+func (dt *DataTable) CheckInvariants() error {
+	if dt.checking {
+		// An invariant's predicate is modifying the table
+		return nil
+	}
+	dt.checking = true
+	defer func() { dt.checking = false }()
+
+	for _, inv := range dt.invariants {
+		if err := inv.pred(dt); err != nil {
+			return &InvariantError{Name: inv.name, Err: err}
+		}
+	}
+	return nil
+}
+
+// mutated is called after any operation that modifies the data or order of
+// the table.
+func (dt *DataTable) mutated() {
+	dt.version++
+	if !dt.checkInvariants {
+		return
+	}
+	if err := dt.CheckInvariants(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,65 @@
+package datatable
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func balanced(dt *DataTable) error {
+	var debit, credit float64
+	for i := 0; i < dt.Len(); i++ {
+		row, _ := dt.RowMap(i)
+		d, _ := row.FloatValue("debit")
+		c, _ := row.FloatValue("credit")
+		debit += d
+		credit += c
+	}
+	if debit != credit {
+		return fmt.Errorf("sum(debit) = %v, sum(credit) = %v", debit, credit)
+	}
+	return nil
+}
+
+func TestCheckInvariants(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("debit", []float64{10, 0})
+	dt.AddColumn("credit", []float64{0, 10})
+	dt.AddInvariant("balanced", balanced)
+
+	if err := dt.CheckInvariants(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dt.SetFloatValue("debit", 1, 5)
+	err := dt.CheckInvariants()
+	var ie *InvariantError
+	if !errors.As(err, &ie) || ie.Name != "balanced" {
+		t.Errorf("got %v, wanted invariant error for balanced", err)
+	}
+
+	dt.RemoveInvariant("balanced")
+	if err := dt.CheckInvariants(); err != nil {
+		t.Errorf("got %v after removing invariant, wanted no error", err)
+	}
+}
+
+func TestCheckInvariantsOnMutation(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("debit", []float64{10, 0})
+	dt.AddColumn("credit", []float64{0, 10})
+	dt.AddInvariant("balanced", balanced)
+	dt.SetCheckInvariants(true)
+
+	// a balanced append does not panic
+	dt.AppendRow([]interface{}{1.0, 1.0})
+
+	defer func() {
+		r := recover()
+		if ie, ok := r.(*InvariantError); !ok || ie.Name != "balanced" {
+			t.Errorf("got panic %v, wanted invariant error for balanced", r)
+		}
+	}()
+	dt.RemoveRows(GreaterThan("debit", 5))
+	t.Errorf("no panic after violating invariant")
+}
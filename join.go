@@ -0,0 +1,474 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AggregateInto appends a new numeric column named colName to target whose
+// values are found by executing the aggregator a against the rows of dt that
+// share the values of the joinKeys columns with each row of target. The named
+// columns must exist in both tables with the same types. Rows of target with
+// no matching rows in dt are assigned NaN. Neither table needs to be sorted
+// by joinKeys.
+func (dt *DataTable) AggregateInto(target *DataTable, joinKeys []string, colName string, a Aggregator) error {
+	return dt.aggregateInto(target, joinKeys, []string{colName}, []Aggregator{a})
+}
+
+// JoinAggregate appends to the table a numeric column for each entry in aggs,
+// named by its key, whose values are found by executing the aggregator
+// against the rows of dt2 that share the values of the on columns with each
+// row of the table, such as attaching per-customer totals to a table of
+// customers. The rows of dt2 are grouped once for all the aggregators and no
+// intermediate joined table is built. The named columns must exist in both
+// tables with the same types. Rows with no matching rows in dt2 are assigned
+// NaN. The columns are added in name order. Neither table needs to be sorted
+// by the on columns.
+func (dt *DataTable) JoinAggregate(dt2 *DataTable, on []string, aggs map[string]Aggregator) error {
+	names := make([]string, 0, len(aggs))
+	for name := range aggs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	as := make([]Aggregator, len(names))
+	for i, name := range names {
+		as[i] = aggs[name]
+	}
+	return dt2.aggregateInto(dt, on, names, as)
+}
+
+// aggregateInto appends a column to target for each of names holding the
+// result of the corresponding aggregator against the rows of dt sharing the
+// values of the joinKeys columns with each row of target.
+func (dt *DataTable) aggregateInto(target *DataTable, joinKeys []string, names []string, aggs []Aggregator) error {
+	cols, tcols, err := joinColumns(dt, target, joinKeys)
+	if err != nil {
+		return err
+	}
+
+	groups := map[string][]int{}
+	for i := 0; i < dt.Len(); i++ {
+		k := dt.rowKey(cols, i)
+		groups[k] = append(groups[k], i)
+	}
+
+	keys := make([]string, target.Len())
+	for i := range keys {
+		keys[i] = target.rowKey(tcols, i)
+	}
+	for n, a := range aggs {
+		vals := make(map[string]float64, len(groups))
+		for k, indices := range groups {
+			vals[k] = a.Aggregate(&StaticRowGroup{dt: dt, indices: indices})
+		}
+
+		col := fillNaN(target.Len())
+		for i, k := range keys {
+			if v, exists := vals[k]; exists {
+				col[i] = v
+			}
+		}
+		if err := target.AddColumn(names[n], col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinColumns looks up the positions of the named columns in both tables,
+// checking that each has the same type in both.
+func joinColumns(dt, dt2 *DataTable, names []string) ([]int, []int, error) {
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("no join columns given")
+	}
+	cols := make([]int, len(names))
+	cols2 := make([]int, len(names))
+	for i, name := range names {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil, nil, fmt.Errorf("unknown column: %s", name)
+		}
+		c2, exists := dt2.colorder[name]
+		if !exists {
+			return nil, nil, fmt.Errorf("unknown column: %s", name)
+		}
+		if dt.isFloatCol(c) != dt2.isFloatCol(c2) {
+			return nil, nil, fmt.Errorf("%w: column %s", ErrMismatchedColumnTypes, name)
+		}
+		cols[i], cols2[i] = c, c2
+	}
+	return cols, cols2, nil
+}
+
+// rowKey encodes the values of the given columns in row n as a string that
+// is equal for two rows only if the values are equal.
+func (dt *DataTable) rowKey(cols []int, n int) string {
+	var b strings.Builder
+	for _, c := range cols {
+		if cv := dt.cols[c]; cv.d != nil && !math.IsNaN(cv.f[n]) {
+			if u, _ := toUnits(cv.f[n], cv.scale); u != cv.d[n] {
+				// the float value is not exact so use the exact value
+				b.WriteString(strconv.Itoa(cv.scale))
+				b.WriteByte('e')
+				b.WriteString(strconv.FormatInt(cv.d[n], 10))
+				b.WriteByte(';')
+				continue
+			}
+		}
+		if dt.isFloatCol(c) {
+			v := dt.cols[c].f[n]
+			if v == 0 {
+				v = 0 // treat -0 and +0 as the same key
+			}
+			b.WriteString(strconv.FormatUint(math.Float64bits(v), 16))
+			b.WriteByte(';')
+			continue
+		}
+		s := dt.cols[c].s[n]
+		b.WriteString(strconv.Itoa(len(s)))
+		b.WriteByte(':')
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// CheckForeignKey returns a new table holding the rows of child whose values
+// in the childCols columns do not match the values in the parentCols columns
+// of any row of parent, such as orders referring to unknown customers. These
+// are the rows an inner join on those columns would drop. The columns are
+// paired by position and each pair must have the same type. The returned
+// table has the same columns as child, in the same order, and no keys set.
+func CheckForeignKey(child *DataTable, childCols []string, parent *DataTable, parentCols []string) (*DataTable, error) {
+	if len(childCols) != len(parentCols) {
+		return nil, fmt.Errorf("%d child columns given for %d parent columns", len(childCols), len(parentCols))
+	}
+	if len(childCols) == 0 {
+		return nil, fmt.Errorf("no join columns given")
+	}
+	cols := make([]int, len(childCols))
+	pcols := make([]int, len(parentCols))
+	for i := range childCols {
+		c, exists := child.colorder[childCols[i]]
+		if !exists {
+			return nil, fmt.Errorf("unknown column: %s", childCols[i])
+		}
+		pc, exists := parent.colorder[parentCols[i]]
+		if !exists {
+			return nil, fmt.Errorf("unknown column: %s", parentCols[i])
+		}
+		if child.isFloatCol(c) != parent.isFloatCol(pc) {
+			return nil, fmt.Errorf("%w: columns %s and %s", ErrMismatchedColumnTypes, childCols[i], parentCols[i])
+		}
+		cols[i], pcols[i] = c, pc
+	}
+
+	parentKeys := make(map[string]bool, parent.Len())
+	for i := 0; i < parent.Len(); i++ {
+		parentKeys[parent.rowKey(pcols, i)] = true
+	}
+	indices := []int{}
+	for i := 0; i < child.Len(); i++ {
+		if !parentKeys[child.rowKey(cols, i)] {
+			indices = append(indices, i)
+		}
+	}
+	return child.SelectIndex(child.Names(), indices)
+}
+
+// RemoveMatchingKeys removes the rows whose values in the on columns match
+// those of any row of other, such as removing blocked accounts listed in a
+// second table, without altering the order of the remaining rows. The named
+// columns must exist in both tables with the same types. The number of rows
+// removed is returned.
+func (dt *DataTable) RemoveMatchingKeys(other *DataTable, on []string) (int, error) {
+	cols, ocols, err := joinColumns(dt, other, on)
+	if err != nil {
+		return 0, err
+	}
+
+	remove := make(map[string]bool, other.Len())
+	for i := 0; i < other.Len(); i++ {
+		remove[other.rowKey(ocols, i)] = true
+	}
+	var indices []int
+	for i := 0; i < dt.Len(); i++ {
+		if remove[dt.rowKey(cols, i)] {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) > 0 {
+		dt.removeRows(indices)
+	}
+	return len(indices), nil
+}
+
+// A JoinIndex is a hash index of the rows of a table by the values of a set
+// of join columns. Building the index once allows a table, such as a
+// dimension table, to be joined to many other tables without hashing its rows
+// each time. An index becomes stale if its table is modified.
+type JoinIndex struct {
+	dt      *DataTable
+	on      []string
+	cols    []int
+	rows    map[string][]int // rows of dt keyed by rowKey of cols
+	version uint64           // version of dt when the index was built
+}
+
+// BuildJoinIndex builds a JoinIndex of the rows of the table by the values of
+// the on columns, for use with JoinIndexed. The table does not need to be
+// sorted.
+func (dt *DataTable) BuildJoinIndex(on []string) (*JoinIndex, error) {
+	if len(on) == 0 {
+		return nil, fmt.Errorf("no join columns given")
+	}
+	idx := &JoinIndex{
+		dt:      dt,
+		on:      append([]string(nil), on...),
+		cols:    make([]int, len(on)),
+		rows:    make(map[string][]int),
+		version: dt.version,
+	}
+	for i, name := range on {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		idx.cols[i] = c
+	}
+	for i := 0; i < dt.Len(); i++ {
+		k := dt.rowKey(idx.cols, i)
+		idx.rows[k] = append(idx.rows[k], i)
+	}
+	return idx, nil
+}
+
+// Join returns a new table holding the inner join of the table with right on
+// the named columns, which must exist in both tables with the same types. The
+// result holds a row for each pair of rows, one from each table, that have
+// equal values in the on columns. Its columns are those of the table followed
+// by the columns of right other than the on columns. An error is returned if
+// any of those columns has the same name as a column of the table. Rows are
+// ordered by their position in the table and then in right. The returned
+// table has no keys set. The rows of whichever table is smaller are hashed by
+// their values of the on columns and the rows of the other table are looked
+// up in the hash table, so the time taken grows with the total number of rows
+// rather than their product.
+func (dt *DataTable) Join(right *DataTable, on []string) (*DataTable, error) {
+	return dt.join(right, on, false, false)
+}
+
+// JoinLeft is like Join but also includes each row of the table that matches
+// no row of right, such as facts whose lookup value is missing from a lookup
+// table. The columns from right hold null values in those rows, which appear
+// in the position of the table's row.
+func (dt *DataTable) JoinLeft(right *DataTable, on []string) (*DataTable, error) {
+	return dt.join(right, on, true, false)
+}
+
+// JoinRight is like Join but also includes each row of right that matches no
+// row of the table. The columns of the table other than the on columns hold
+// null values in those rows, which follow the joined rows in the order they
+// appear in right.
+func (dt *DataTable) JoinRight(right *DataTable, on []string) (*DataTable, error) {
+	return dt.join(right, on, false, true)
+}
+
+// JoinOuter is like Join but also includes the rows of either table that
+// match no row of the other, as described by JoinLeft and JoinRight.
+func (dt *DataTable) JoinOuter(right *DataTable, on []string) (*DataTable, error) {
+	return dt.join(right, on, true, true)
+}
+
+// join joins the table with right, including the unmatched rows of the table
+// if keepLeft is set and the unmatched rows of right if keepRight is set.
+func (dt *DataTable) join(right *DataTable, on []string, keepLeft, keepRight bool) (*DataTable, error) {
+	if dt.Len() >= right.Len() {
+		idx, err := right.BuildJoinIndex(on)
+		if err != nil {
+			return nil, err
+		}
+		return dt.joinIndexed(idx, keepLeft, keepRight)
+	}
+
+	// The table is smaller so hash its rows and probe with those of right,
+	// collecting the partners of each row of the table to keep the result
+	// in the same order.
+	idx, err := dt.BuildJoinIndex(on)
+	if err != nil {
+		return nil, err
+	}
+	_, rcols, err := joinColumns(dt, right, on)
+	if err != nil {
+		return nil, err
+	}
+	partners := make([][]int, dt.Len())
+	var unmatched []int // rows of right with no partner
+	for j := 0; j < right.Len(); j++ {
+		rows := idx.rows[right.rowKey(rcols, j)]
+		for _, i := range rows {
+			partners[i] = append(partners[i], j)
+		}
+		if keepRight && len(rows) == 0 {
+			unmatched = append(unmatched, j)
+		}
+	}
+
+	var li, ri []int
+	for i, js := range partners {
+		for _, j := range js {
+			li = append(li, i)
+			ri = append(ri, j)
+		}
+		if keepLeft && len(js) == 0 {
+			li = append(li, i)
+			ri = append(ri, -1)
+		}
+	}
+	for _, j := range unmatched {
+		li = append(li, -1)
+		ri = append(ri, j)
+	}
+	return joinRows(dt, right, on, li, ri)
+}
+
+// JoinIndexed is like Join but joins the table with the table indexed by idx,
+// using the index's join columns. An error is returned if the indexed table
+// has been modified since the index was built.
+func (dt *DataTable) JoinIndexed(idx *JoinIndex) (*DataTable, error) {
+	return dt.joinIndexed(idx, false, false)
+}
+
+func (dt *DataTable) joinIndexed(idx *JoinIndex, keepLeft, keepRight bool) (*DataTable, error) {
+	if idx.dt.version != idx.version {
+		return nil, fmt.Errorf("join index is stale: table modified since the index was built")
+	}
+	cols, _, err := joinColumns(dt, idx.dt, idx.on)
+	if err != nil {
+		return nil, err
+	}
+
+	var li, ri []int
+	var matched []bool // rows of the indexed table with a partner
+	if keepRight {
+		matched = make([]bool, idx.dt.Len())
+	}
+	for i := 0; i < dt.Len(); i++ {
+		rows := idx.rows[dt.rowKey(cols, i)]
+		for _, j := range rows {
+			li = append(li, i)
+			ri = append(ri, j)
+			if keepRight {
+				matched[j] = true
+			}
+		}
+		if keepLeft && len(rows) == 0 {
+			li = append(li, i)
+			ri = append(ri, -1)
+		}
+	}
+	for j, ok := range matched {
+		if !ok {
+			li = append(li, -1)
+			ri = append(ri, j)
+		}
+	}
+	return joinRows(dt, idx.dt, idx.on, li, ri)
+}
+
+// joinRows builds the result of joining dt with right on the named columns
+// from pairs of rows given by position in li and ri. A position of -1 means
+// the row has no partner, in which case the other table's columns hold null
+// values, except for the on columns which take the values of right.
+func joinRows(dt, right *DataTable, on []string, li, ri []int) (*DataTable, error) {
+	isOn := make(map[string]bool, len(on))
+	for _, name := range on {
+		isOn[name] = true
+	}
+
+	ret := &DataTable{}
+	for c, name := range dt.colnames {
+		cv := dt.cols[c].pickPad(li)
+		if isOn[name] {
+			src := right.cols[right.colorder[name]]
+			for i, l := range li {
+				if l < 0 && ri[i] >= 0 {
+					cv.setValue(i, src, ri[i])
+				}
+			}
+		}
+		ret.addColumn(name, cv)
+	}
+	for c, name := range right.colnames {
+		if isOn[name] {
+			continue
+		}
+		if _, exists := ret.colorder[name]; exists {
+			return nil, fmt.Errorf("duplicate column: %s", name)
+		}
+		ret.addColumn(name, right.cols[c].pickPad(ri))
+	}
+	return ret, nil
+}
+
+// JoinAsOf returns a new table joining each row of the table with the most
+// recent row of dt2, being the row with the greatest value of the numeric on
+// column that is no greater than the row's own value, among the rows of dt2
+// with equal values in the by columns. This aligns rows with the latest state
+// in effect at the time, such as trades with the prevailing quotes. Of several
+// rows of dt2 with the same value of on the last is used. The result has a row
+// for each row of the table, in the same order, with the columns of the table
+// followed by the columns of dt2 other than on and the by columns. Those
+// columns hold null values in rows with no earlier row in dt2, or whose value
+// of on is NaN. An error is returned if any of those columns has the same name
+// as a column of the table. The on column must be numeric in both tables and
+// the by columns must exist in both with the same types. Neither table needs
+// to be sorted.
+func (dt *DataTable) JoinAsOf(dt2 *DataTable, on string, by []string) (*DataTable, error) {
+	oc, exists := dt.colorder[on]
+	if !exists {
+		return nil, fmt.Errorf("unknown column: %s", on)
+	}
+	oc2, exists := dt2.colorder[on]
+	if !exists {
+		return nil, fmt.Errorf("unknown column: %s", on)
+	}
+	if !dt.isFloatCol(oc) || !dt2.isFloatCol(oc2) {
+		return nil, fmt.Errorf("%w: column %s", ErrMismatchedColumnTypes, on)
+	}
+	var cols, cols2 []int
+	if len(by) > 0 {
+		var err error
+		if cols, cols2, err = joinColumns(dt, dt2, by); err != nil {
+			return nil, err
+		}
+	}
+
+	vals, vals2 := dt.cols[oc].f, dt2.cols[oc2].f
+	groups := map[string][]int{}
+	for j := 0; j < dt2.Len(); j++ {
+		if !math.IsNaN(vals2[j]) {
+			k := dt2.rowKey(cols2, j)
+			groups[k] = append(groups[k], j)
+		}
+	}
+	for _, rows := range groups {
+		sort.SliceStable(rows, func(a, b int) bool { return vals2[rows[a]] < vals2[rows[b]] })
+	}
+
+	li := fillSeq(dt.Len())
+	ri := make([]int, dt.Len())
+	for i := range ri {
+		rows := groups[dt.rowKey(cols, i)]
+		// position of the first row later than row i
+		p := sort.Search(len(rows), func(p int) bool { return vals2[rows[p]] > vals[i] })
+		if math.IsNaN(vals[i]) || p == 0 {
+			ri[i] = -1
+			continue
+		}
+		ri[i] = rows[p-1]
+	}
+	return joinRows(dt, dt2, append([]string{on}, by...), li, ri)
+}
@@ -0,0 +1,410 @@
+package datatable
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAggregateInto(t *testing.T) {
+	sales := &DataTable{}
+	sales.AddStringColumn("region", []string{"n", "s", "n", "n", "s"})
+	sales.AddColumn("year", []float64{2020, 2020, 2020, 2021, 2021})
+	sales.AddColumn("amount", []float64{1, 2, 3, 4, 5})
+
+	regions := &DataTable{}
+	regions.AddStringColumn("region", []string{"s", "n", "e"})
+	regions.AddStringColumn("manager", []string{"sam", "nia", "eve"})
+
+	if err := sales.AggregateInto(regions, []string{"region"}, "total", Sum("amount")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"s", "sam", 7.0},
+		{"n", "nia", 8.0},
+		{"e", "eve", math.NaN()},
+	}
+	if rows := regions.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	years := &DataTable{}
+	years.AddStringColumn("region", []string{"n", "n"})
+	years.AddColumn("year", []float64{2021, 2020})
+	if err := sales.AggregateInto(years, []string{"region", "year"}, "count", Count()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = [][]interface{}{
+		{"n", 2021.0, 1.0},
+		{"n", 2020.0, 2.0},
+	}
+	if rows := years.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+}
+
+func TestAggregateIntoErrors(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("k", []float64{1})
+	target := &DataTable{}
+	target.AddStringColumn("k", []string{"1"})
+
+	if err := dt.AggregateInto(target, []string{"k"}, "n", Count()); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if err := dt.AggregateInto(target, []string{"nope"}, "n", Count()); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
+func TestCheckForeignKey(t *testing.T) {
+	customers := &DataTable{}
+	customers.AddStringColumn("id", []string{"c1", "c2"})
+	customers.AddStringColumn("name", []string{"Ann", "Bob"})
+
+	orders := &DataTable{}
+	orders.AddColumn("order", []float64{1, 2, 3, 4})
+	orders.AddStringColumn("customer", []string{"c1", "c3", "c2", ""})
+
+	bad, err := CheckForeignKey(orders, []string{"customer"}, customers, []string{"id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"order", "customer"},
+		{2.0, "c3"},
+		{4.0, ""},
+	}
+	if !equivalentRows(bad.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", bad.RawRows(true), expected)
+	}
+
+	if _, err := CheckForeignKey(orders, []string{"order"}, customers, []string{"id"}); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got error %v, wanted ErrMismatchedColumnTypes", err)
+	}
+	if _, err := CheckForeignKey(orders, []string{"customer"}, customers, []string{"id", "name"}); err == nil {
+		t.Errorf("expected error for differing numbers of columns")
+	}
+}
+
+func TestRemoveMatchingKeys(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("bank", []string{"x", "y", "x", "z", "y"})
+	dt.AddColumn("acct", []float64{1, 1, 2, 3, 1})
+	dt.AddColumn("amount", []float64{10, 20, 30, 40, 50})
+	dt.EnableUndo(5)
+
+	blocked := &DataTable{}
+	blocked.AddColumn("acct", []float64{1, 9})
+	blocked.AddStringColumn("bank", []string{"y", "x"})
+
+	n, err := dt.RemoveMatchingKeys(blocked, []string{"bank", "acct"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d rows removed, wanted 2", n)
+	}
+	expected := [][]interface{}{
+		{"bank", "acct", "amount"},
+		{"x", 1.0, 10.0},
+		{"x", 2.0, 30.0},
+		{"z", 3.0, 40.0},
+	}
+	if !equivalentRows(dt.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", dt.RawRows(true), expected)
+	}
+
+	dt.Undo()
+	if dt.Len() != 5 {
+		t.Errorf("got %d rows after undo, wanted 5", dt.Len())
+	}
+
+	if _, err := dt.RemoveMatchingKeys(blocked, []string{"amount"}); err == nil {
+		t.Errorf("expected error for column missing from other table")
+	}
+}
+
+func TestJoin(t *testing.T) {
+	products := &DataTable{}
+	products.AddStringColumn("sku", []string{"b", "a", "c"})
+	products.AddStringColumn("label", []string{"Bolt", "Anchor", "Cable"})
+
+	sales := &DataTable{}
+	sales.AddStringColumn("sku", []string{"a", "b", "a", "z"})
+	sales.AddColumn("qty", []float64{1, 2, 3, 4})
+
+	joined, err := sales.Join(products, []string{"sku"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"sku", "qty", "label"},
+		{"a", 1.0, "Anchor"},
+		{"b", 2.0, "Bolt"},
+		{"a", 3.0, "Anchor"},
+	}
+	if !equivalentRows(joined.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", joined.RawRows(true), expected)
+	}
+
+	clash := products.Clone()
+	clash.AddColumn("qty", []float64{0, 0, 0})
+	if _, err := sales.Join(clash, []string{"sku"}); err == nil {
+		t.Errorf("expected error for duplicate column name")
+	}
+	if _, err := sales.Join(products, []string{"qty"}); err == nil {
+		t.Errorf("expected error for column missing from right table")
+	}
+}
+
+func TestOuterJoins(t *testing.T) {
+	products := &DataTable{}
+	products.AddStringColumn("sku", []string{"b", "a", "c"})
+	products.AddStringColumn("label", []string{"Bolt", "Anchor", "Cable"})
+
+	sales := &DataTable{}
+	sales.AddStringColumn("sku", []string{"a", "z", "b"})
+	sales.AddColumn("qty", []float64{1, 2, 3})
+
+	nan := math.NaN()
+	testCases := []struct {
+		name     string
+		join     func(*DataTable, []string) (*DataTable, error)
+		expected [][]interface{}
+		nulls    []int // rows of the result with a null qty or label
+	}{
+		{
+			name: "left",
+			join: sales.JoinLeft,
+			expected: [][]interface{}{
+				{"sku", "qty", "label"},
+				{"a", 1.0, "Anchor"},
+				{"z", 2.0, ""},
+				{"b", 3.0, "Bolt"},
+			},
+			nulls: []int{1},
+		},
+		{
+			name: "right",
+			join: sales.JoinRight,
+			expected: [][]interface{}{
+				{"sku", "qty", "label"},
+				{"a", 1.0, "Anchor"},
+				{"b", 3.0, "Bolt"},
+				{"c", nan, "Cable"},
+			},
+			nulls: []int{2},
+		},
+		{
+			name: "outer",
+			join: sales.JoinOuter,
+			expected: [][]interface{}{
+				{"sku", "qty", "label"},
+				{"a", 1.0, "Anchor"},
+				{"z", 2.0, ""},
+				{"b", 3.0, "Bolt"},
+				{"c", nan, "Cable"},
+			},
+			nulls: []int{1, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		joined, err := tc.join(products, []string{"sku"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !equivalentRows(joined.RawRows(true), tc.expected) {
+			t.Errorf("%s: got %v, wanted %v", tc.name, joined.RawRows(true), tc.expected)
+		}
+		nulls := append(joined.Matches(IsNull("qty")), joined.Matches(IsNull("label"))...)
+		sort.Ints(nulls)
+		if !reflect.DeepEqual(nulls, tc.nulls) {
+			t.Errorf("%s: got null rows %v, wanted %v", tc.name, nulls, tc.nulls)
+		}
+		if joined.CountWhere(IsNull("sku")) != 0 {
+			t.Errorf("%s: got null join column", tc.name)
+		}
+	}
+}
+
+func TestJoinIndexed(t *testing.T) {
+	dim := &DataTable{}
+	dim.AddColumn("id", []float64{1, 2, 2})
+	dim.AddStringColumn("name", []string{"one", "two", "deux"})
+
+	idx, err := dim.BuildJoinIndex([]string{"id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, ids := range [][]float64{{2, 1}, {3, 1, 1}} {
+		fact := &DataTable{}
+		fact.AddColumn("id", ids)
+		joined, err := fact.JoinIndexed(idx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want, _ := fact.Join(dim, []string{"id"})
+		if !equivalentRows(joined.RawRows(true), want.RawRows(true)) {
+			t.Errorf("got %v, wanted %v", joined.RawRows(true), want.RawRows(true))
+		}
+	}
+
+	fact := &DataTable{}
+	fact.AddColumn("id", []float64{2})
+	joined, _ := fact.JoinIndexed(idx)
+	if joined.Len() != 2 {
+		t.Errorf("got %d rows joining to duplicate keys, wanted 2", joined.Len())
+	}
+
+	dim.AppendRow([]interface{}{3.0, "three"})
+	if _, err := fact.JoinIndexed(idx); err == nil {
+		t.Errorf("expected error using stale index")
+	}
+	if _, err := dim.BuildJoinIndex([]string{"missing"}); err == nil {
+		t.Errorf("expected error for unknown column")
+	}
+}
+
+func TestJoinAggregate(t *testing.T) {
+	customers := &DataTable{}
+	customers.AddStringColumn("cust", []string{"c2", "c1", "c3"})
+
+	orders := &DataTable{}
+	orders.AddStringColumn("cust", []string{"c1", "c2", "c1", "c4"})
+	orders.AddColumn("amount", []float64{10, 5, 30, 99})
+
+	err := customers.JoinAggregate(orders, []string{"cust"}, map[string]Aggregator{
+		"total":  Sum("amount"),
+		"orders": Count(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"cust", "orders", "total"},
+		{"c2", 1.0, 5.0},
+		{"c1", 2.0, 40.0},
+		{"c3", math.NaN(), math.NaN()},
+	}
+	if !equivalentRows(customers.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", customers.RawRows(true), expected)
+	}
+
+	if err := customers.JoinAggregate(orders, []string{"amount"}, map[string]Aggregator{"n": Count()}); err == nil {
+		t.Errorf("expected error for column missing from table")
+	}
+}
+
+func TestJoinSideSelection(t *testing.T) {
+	small := &DataTable{}
+	small.AddColumn("k", []float64{3, 1, 9})
+	small.AddStringColumn("s", []string{"three", "one", "nine"})
+
+	big := &DataTable{}
+	big.AddColumn("k", []float64{1, 2, 3, 1, 4, 3})
+	big.AddColumn("b", []float64{10, 20, 30, 11, 40, 31})
+
+	testCases := []struct {
+		name                string
+		join                func(*DataTable, *DataTable, []string) (*DataTable, error)
+		keepLeft, keepRight bool
+	}{
+		{"inner", (*DataTable).Join, false, false},
+		{"left", (*DataTable).JoinLeft, true, false},
+		{"right", (*DataTable).JoinRight, false, true},
+		{"outer", (*DataTable).JoinOuter, true, true},
+	}
+
+	// hashing the smaller table gives the same rows in the same order as
+	// hashing the larger
+	idx, err := big.BuildJoinIndex([]string{"k"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tc := range testCases {
+		got, err := tc.join(small, big, []string{"k"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		expected, _ := small.joinIndexed(idx, tc.keepLeft, tc.keepRight)
+		if !equivalentRows(got.RawRows(true), expected.RawRows(true)) {
+			t.Errorf("%s: got %v, wanted %v", tc.name, got.RawRows(true), expected.RawRows(true))
+		}
+		if !reflect.DeepEqual(got.Matches(IsNull("b")), expected.Matches(IsNull("b"))) {
+			t.Errorf("%s: got null rows %v, wanted %v", tc.name, got.Matches(IsNull("b")), expected.Matches(IsNull("b")))
+		}
+	}
+}
+
+func BenchmarkJoin(b *testing.B) {
+	const n = 1000000
+	facts := &DataTable{}
+	keys := make([]float64, n)
+	for i := range keys {
+		keys[i] = float64(i % 1000)
+	}
+	facts.AddColumn("k", keys)
+	facts.AddColumn("qty", fillNaN(n))
+
+	dim := &DataTable{}
+	dim.AddColumn("k", keys[:1000])
+	dim.AddColumn("v", keys[:1000])
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dim.Join(facts, []string{"k"})
+	}
+}
+
+func TestJoinAsOf(t *testing.T) {
+	trades := &DataTable{}
+	trades.AddStringColumn("sym", []string{"a", "b", "a", "a", "c"})
+	trades.AddColumn("t", []float64{5, 5, 1, 10, 5})
+
+	quotes := &DataTable{}
+	quotes.AddStringColumn("sym", []string{"a", "a", "b", "a", "a"})
+	quotes.AddColumn("t", []float64{8, 2, 6, 4, 4})
+	quotes.AddColumn("bid", []float64{1.8, 1.2, 2.6, 1.4, 1.5})
+
+	joined, err := trades.JoinAsOf(quotes, "t", []string{"sym"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nan := math.NaN()
+	expected := [][]interface{}{
+		{"sym", "t", "bid"},
+		{"a", 5.0, 1.5},
+		{"b", 5.0, nan},
+		{"a", 1.0, nan},
+		{"a", 10.0, 1.8},
+		{"c", 5.0, nan},
+	}
+	if !equivalentRows(joined.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", joined.RawRows(true), expected)
+	}
+	if got := joined.Matches(IsNull("bid")); !reflect.DeepEqual(got, []int{1, 2, 4}) {
+		t.Errorf("got null rows %v, wanted [1 2 4]", got)
+	}
+
+	// without by columns every row of dt2 is a candidate
+	prices, _ := quotes.Select([]string{"t", "bid"})
+	joined, err = trades.JoinAsOf(prices, "t", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bid := joined.cols[joined.colorder["bid"]].f; !equivalentFloatSlices(bid, []float64{1.5, 1.5, nan, 1.8, 1.5}) {
+		t.Errorf("got %v, wanted [1.5 1.5 NaN 1.8 1.5]", bid)
+	}
+	if _, err := trades.JoinAsOf(quotes, "t", nil); err == nil {
+		t.Errorf("got no error for duplicate column")
+	}
+
+	if _, err := trades.JoinAsOf(quotes, "sym", nil); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+}
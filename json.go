@@ -0,0 +1,208 @@
+package datatable
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ToJSON writes the table to w as a JSON array holding an object for each
+// row, whose members are the row's values keyed by column name in the order
+// the columns were added to the table. Numeric values are written as numbers,
+// formatted according to the table's float formats, except that NaN and
+// infinite values are written as the strings "NaN", "+Inf" and "-Inf". Values
+// of time columns are written as strings formatted with the column's layout.
+// Null values are written as null, so that FromJSON reads them back as null
+// values distinct from NaN.
+func (dt *DataTable) ToJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	names := make([][]byte, len(dt.colnames))
+	for c, name := range dt.colnames {
+		b, err := json.Marshal(name)
+		if err != nil {
+			return fmt.Errorf("writing json: %v", err)
+		}
+		names[c] = b
+	}
+
+	bw.WriteByte('[')
+	for n := 0; n < dt.Len(); n++ {
+		if n > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('{')
+		for c := range dt.cols {
+			if c > 0 {
+				bw.WriteByte(',')
+			}
+			bw.Write(names[c])
+			bw.WriteByte(':')
+			if dt.cols[c].isNull(n) {
+				bw.WriteString("null")
+				continue
+			}
+			if !dt.isFloatCol(c) {
+				b, err := json.Marshal(dt.cols[c].s[n])
+				if err != nil {
+					return fmt.Errorf("writing json: %v", err)
+				}
+				bw.Write(b)
+				continue
+			}
+			switch v := dt.cols[c].f[n]; {
+			case math.IsNaN(v):
+				bw.WriteString(`"NaN"`)
+			case math.IsInf(v, 1):
+				bw.WriteString(`"+Inf"`)
+			case math.IsInf(v, -1):
+				bw.WriteString(`"-Inf"`)
+			case dt.cols[c].layouts != nil:
+				b, err := json.Marshal(dt.formatFloat(c, n, v))
+				if err != nil {
+					return fmt.Errorf("writing json: %v", err)
+				}
+				bw.Write(b)
+			default:
+				bw.WriteString(dt.formatFloat(c, n, v))
+			}
+		}
+		bw.WriteByte('}')
+	}
+	bw.WriteString("]\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing json: %v", err)
+	}
+	return nil
+}
+
+// FromJSON reads a data table from a JSON array of objects in r, such as
+// that written by ToJSON. Each object becomes a row and each member a value
+// of the column with the member's name. Columns are added in the order their
+// names are first seen. A column is numeric if every value is a number, null
+// or one of the strings "NaN", "+Inf" and "-Inf", and at least one is a
+// number. Otherwise the column holds text, with numbers and booleans
+// converted to their JSON text. Null and missing values are read as null
+// values. Objects and arrays may not be nested within the row objects.
+func FromJSON(r io.Reader) (*DataTable, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	pos := map[string]int{}
+	var rows []map[int]interface{}
+	for dec.More() {
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, err
+		}
+		row := map[int]interface{}{}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("reading json: %v", err)
+			}
+			name := tok.(string)
+			c, exists := pos[name]
+			if !exists {
+				c = len(names)
+				pos[name] = c
+				names = append(names, name)
+			}
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return nil, fmt.Errorf("reading json: %v", err)
+			}
+			switch v.(type) {
+			case map[string]interface{}, []interface{}:
+				return nil, fmt.Errorf("reading json: nested value for column %s in row %d", name, len(rows))
+			}
+			row[c] = v
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return nil, err
+	}
+
+	dt := &DataTable{}
+	for c, name := range names {
+		if vals, ok := jsonFloats(rows, c); ok {
+			dt.AddColumn(name, vals)
+			continue
+		}
+		vals := make([]string, len(rows))
+		for i, row := range rows {
+			switch v := row[c].(type) {
+			case string:
+				vals[i] = v
+			case json.Number:
+				vals[i] = v.String()
+			case bool:
+				vals[i] = strconv.FormatBool(v)
+			}
+		}
+		dt.AddStringColumn(name, vals)
+	}
+	for i, row := range rows {
+		for c := range names {
+			if row[c] == nil {
+				dt.cols[c].setNull(i, true)
+			}
+		}
+	}
+	return dt, nil
+}
+
+// jsonFloats attempts to read column c of rows as numeric values.
+func jsonFloats(rows []map[int]interface{}, c int) ([]float64, bool) {
+	vals := make([]float64, len(rows))
+	numbers := false
+	for i, row := range rows {
+		switch v := row[c].(type) {
+		case nil:
+			vals[i] = math.NaN()
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, false
+			}
+			vals[i] = f
+			numbers = true
+		case string:
+			switch v {
+			case "NaN":
+				vals[i] = math.NaN()
+			case "+Inf":
+				vals[i] = math.Inf(1)
+			case "-Inf":
+				vals[i] = math.Inf(-1)
+			default:
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+	return vals, numbers
+}
+
+// expectDelim reads the next token from dec and checks that it is d.
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("reading json: %v", err)
+	}
+	if tok != d {
+		return fmt.Errorf("reading json: found %v, expected %v", tok, d)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package datatable
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToJSON(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("name", []string{"a \"q\"", "b"})
+	dt.AddColumn("score", []float64{1.5, math.NaN()})
+	dt.AddColumn("ratio", []float64{math.Inf(1), 2})
+	dt.AddDecimalColumn("price", []int64{120, 5}, 2)
+
+	buf := new(bytes.Buffer)
+	if err := dt.ToJSON(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `[{"name":"a \"q\"","score":1.5,"ratio":"+Inf","price":1.20},{"name":"b","score":"NaN","ratio":2,"price":0.05}]` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %s, wanted %s", buf.String(), want)
+	}
+
+	dt2, err := FromJSON(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !equivalentRows(dt.RawRows(true), dt2.RawRows(true)) {
+		t.Errorf("got %v, wanted %v", dt2.RawRows(true), dt.RawRows(true))
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	input := `[
+		{"id": 1, "tag": "x", "flag": true},
+		{"id": 2, "extra": null, "tag": 7},
+		{"flag": false, "id": null}
+	]`
+	dt, err := FromJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"id", "tag", "flag", "extra"},
+		{1.0, "x", "true", ""},
+		{2.0, "7", "", ""},
+		{math.NaN(), "", "false", ""},
+	}
+	if !equivalentRows(dt.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", dt.RawRows(true), expected)
+	}
+
+	for _, bad := range []string{`{"a": 1}`, `[{"a": {"b": 1}}]`, `[{"a": 1}`, `[1]`} {
+		if _, err := FromJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error reading %s", bad)
+		}
+	}
+
+	dt, err = FromJSON(strings.NewReader("[]"))
+	if err != nil || dt.N() != 0 {
+		t.Errorf("got %d columns and error %v for empty array, wanted none", dt.N(), err)
+	}
+}
+
+func TestJSONRoundTripNaN(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("x", []float64{1, math.NaN(), 3})
+	dt.SetNull("x", 2)
+
+	var buf bytes.Buffer
+	if err := dt.ToJSON(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	back, err := FromJSON(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{{"x"}, {1.0}, {math.NaN()}, {math.NaN()}}
+	if !equivalentRows(back.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", back.RawRows(true), expected)
+	}
+	if got := back.Matches(IsNull("x")); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("got null rows %v, wanted [2]", got)
+	}
+}
+
+func TestJSONTimeColumn(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddTimeColumn("t", []time.Time{
+		time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	})
+	dt.AddColumn("v", []float64{1, 2})
+	dt.SetNull("t", 1)
+
+	var buf bytes.Buffer
+	if err := dt.ToJSON(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("wrote invalid json: %s", buf.String())
+	}
+
+	got, err := FromJSON(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]interface{}{
+		{"t", "v"},
+		{"2024-01-02T03:04:05Z", 1.0},
+		{"", 2.0},
+	}
+	if rows := got.RawRows(true); !equivalentRows(rows, want) {
+		t.Errorf("got %v, wanted %v", rows, want)
+	}
+}
@@ -0,0 +1,159 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+)
+
+// DetectKeys finds the smallest set of columns, of at most maxCols columns,
+// whose values uniquely identify every row of the table. Sets with the same
+// number of columns are tried in the order the columns were added to the
+// table and the first that is unique is returned. An error wrapping
+// ErrDuplicateKey is returned if no such set exists.
+func (dt *DataTable) DetectKeys(maxCols int) ([]string, error) {
+	if maxCols > dt.N() {
+		maxCols = dt.N()
+	}
+	for size := 1; size <= maxCols; size++ {
+		// cols holds the positions of the columns in ascending order
+		cols := fillSeq(size)
+		for {
+			if _, _, dup := dt.duplicateRows(cols, 0); !dup {
+				names := make([]string, len(cols))
+				for i, c := range cols {
+					names[i] = dt.colnames[c]
+				}
+				return names, nil
+			}
+
+			// Advance to the next combination
+			i := size - 1
+			for i >= 0 && cols[i] == dt.N()-size+i {
+				i--
+			}
+			if i < 0 {
+				break
+			}
+			cols[i]++
+			for j := i + 1; j < size; j++ {
+				cols[j] = cols[j-1] + 1
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: no set of at most %d columns identifies every row", ErrDuplicateKey, maxCols)
+}
+
+// SetPrimaryKey declares that the values of the named columns uniquely
+// identify each row of the table. An error wrapping ErrDuplicateKey is
+// returned if rows already in the table share the same values. Once set,
+// Append, AppendRow and ParseRow return an error wrapping ErrDuplicateKey,
+// without modifying the table, if a new row would have the same values as
+// another row. Checking new rows examines every row in the table so
+// appending many rows individually can be slow. Calling SetPrimaryKey with no
+// names removes the primary key, as does removing one of its columns.
+func (dt *DataTable) SetPrimaryKey(names ...string) error {
+	if len(names) == 0 {
+		dt.primaryKey = nil
+		return nil
+	}
+	cols := make([]int, len(names))
+	for i, name := range names {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return fmt.Errorf("unknown column: %s", name)
+		}
+		cols[i] = c
+	}
+	if i, j, dup := dt.duplicateRows(cols, 0); dup {
+		return fmt.Errorf("%w: rows %d and %d have the same values of %v", ErrDuplicateKey, i, j, names)
+	}
+	dt.primaryKey = append([]string(nil), names...)
+	return nil
+}
+
+// PrimaryKey returns the names of the columns set by SetPrimaryKey or nil if
+// no primary key is set.
+func (dt *DataTable) PrimaryKey() []string {
+	return append([]string(nil), dt.primaryKey...)
+}
+
+// checkPrimaryKey returns an error wrapping ErrDuplicateKey if any row from
+// position start onwards has the same primary key values as another row.
+func (dt *DataTable) checkPrimaryKey(start int) error {
+	if dt.primaryKey == nil {
+		return nil
+	}
+	if i, j, dup := dt.duplicateRows(keyColumns(dt.primaryKey, dt), start); dup {
+		return fmt.Errorf("%w: new row %d has the same values of %v as row %d", ErrDuplicateKey, j, dt.primaryKey, i)
+	}
+	return nil
+}
+
+// checkAppendPrimaryKey is like checkPrimaryKey but checks the rows of dt2
+// before they are appended by Append. Primary key columns missing from dt2
+// are treated as NaN or empty, as Append pads them.
+func (dt *DataTable) checkAppendPrimaryKey(dt2 *DataTable) error {
+	if dt.primaryKey == nil {
+		return nil
+	}
+	combined := &DataTable{}
+	for _, name := range dt.primaryKey {
+		cv := dt.cols[dt.colorder[name]].clone()
+		c2, exists := dt2.colorder[name]
+		switch {
+		case !exists && cv.f != nil:
+			for i := 0; i < dt2.Len(); i++ {
+				cv.appendFloat(math.NaN())
+			}
+		case !exists:
+			cv.s = append(cv.s, make([]string, dt2.Len())...)
+		case (cv.f != nil) != dt2.isFloatCol(c2):
+			return ErrMismatchedColumnTypes
+		case cv.f != nil:
+			cv.appendFloats(dt2.cols[c2])
+		default:
+			cv.s = append(cv.s, dt2.cols[c2].s...)
+		}
+		combined.addColumn(name, cv)
+	}
+	if i, j, dup := combined.duplicateRows(fillSeq(combined.N()), dt.Len()); dup {
+		return fmt.Errorf("%w: appended row %d has the same values of %v as row %d", ErrDuplicateKey, j-dt.Len(), dt.primaryKey, i)
+	}
+	return nil
+}
+
+// duplicateRows finds a row at position j, at or after start, with the same
+// values in cols as an earlier row at position i.
+func (dt *DataTable) duplicateRows(cols []int, start int) (i, j int, dup bool) {
+	seen := make(map[string]int, dt.Len())
+	for j := 0; j < dt.Len(); j++ {
+		k := dt.rowKey(cols, j)
+		if i, exists := seen[k]; exists && j >= start {
+			return i, j, true
+		} else if !exists {
+			seen[k] = j
+		}
+	}
+	return 0, 0, false
+}
+
+// truncate removes every row from position n onwards.
+func (dt *DataTable) truncate(n int) {
+	for c := range dt.cols {
+		cv := &dt.cols[c]
+		if cv.f != nil {
+			cv.f = cv.f[:n]
+		} else {
+			cv.s = cv.s[:n]
+		}
+		if cv.d != nil {
+			cv.d = cv.d[:n]
+		}
+		if len(cv.null) > n {
+			cv.null = cv.null[:n]
+		}
+	}
+	if dt.ids != nil && len(dt.ids) > n {
+		dt.ids = dt.ids[:n]
+	}
+}
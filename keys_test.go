@@ -0,0 +1,85 @@
+package datatable
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDetectKeys(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"north", "north", "south", "south"})
+	dt.AddColumn("year", []float64{2020, 2021, 2020, 2021})
+	dt.AddColumn("sales", []float64{5, 6, 5, 7})
+
+	keys, err := dt.DetectKeys(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"region", "year"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("got %v, wanted %v", keys, want)
+	}
+
+	if _, err := dt.DetectKeys(1); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("got error %v, wanted ErrDuplicateKey", err)
+	}
+
+	dt.AddColumn("id", []float64{1, 2, 3, 4})
+	keys, err = dt.DetectKeys(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"id"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("got %v, wanted %v", keys, want)
+	}
+}
+
+func TestSetPrimaryKey(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"north", "south"})
+	dt.AddColumn("year", []float64{2020, 2020})
+	dt.AddColumn("sales", []float64{5, 6})
+
+	if err := dt.SetPrimaryKey("year"); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("got error %v setting non-unique key, wanted ErrDuplicateKey", err)
+	}
+	if err := dt.SetPrimaryKey("region", "missing"); err == nil {
+		t.Errorf("expected error for unknown column")
+	}
+	if err := dt.SetPrimaryKey("region", "year"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := dt.AppendRow([]interface{}{"north", 2020.0, 9.0}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("got error %v from AppendRow, wanted ErrDuplicateKey", err)
+	}
+	if err := dt.ParseRow("south", "2020", "9"); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("got error %v from ParseRow, wanted ErrDuplicateKey", err)
+	}
+	if dt.Len() != 2 {
+		t.Errorf("got %d rows after rejected appends, wanted 2", dt.Len())
+	}
+
+	if err := dt.AppendRow([]interface{}{"north", 2021.0, 9.0}); err != nil {
+		t.Errorf("unexpected error from AppendRow: %v", err)
+	}
+
+	dt2 := &DataTable{}
+	dt2.AddColumn("year", []float64{2022, 2022})
+	dt2.AddStringColumn("region", []string{"west", "west"})
+	if err := dt.Append(dt2); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("got error %v appending rows duplicated within the new rows, wanted ErrDuplicateKey", err)
+	}
+	dt2.SetStringValues("region", []int{1}, []string{"east"})
+	if err := dt.Append(dt2); err != nil {
+		t.Errorf("unexpected error from Append: %v", err)
+	}
+	if dt.Len() != 5 {
+		t.Errorf("got %d rows, wanted 5", dt.Len())
+	}
+
+	dt.RemoveColumn("year")
+	if pk := dt.PrimaryKey(); pk != nil {
+		t.Errorf("got primary key %v after removing a column, wanted none", pk)
+	}
+}
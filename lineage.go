@@ -0,0 +1,133 @@
+package datatable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lineage describes how a column was calculated.
+type Lineage struct {
+	// Op is the name of the method family that created the column: "Calc",
+	// "CalcString" or "Aggregate".
+	Op string
+
+	// Expr describes the calculation, such as "Mean(price)" or
+	// "Mul(Col(price), Col(qty))". It is empty if the calculator or
+	// aggregator was not created by a function of this package, and parts
+	// of it are shown as "?" if they were not.
+	Expr string
+
+	// Sources holds the names of the columns read by the calculation, as
+	// far as they are known.
+	Sources []string
+
+	// Keys holds the names of the key columns that grouped the rows of an
+	// aggregation.
+	Keys []string
+}
+
+// Lineage returns a description of how the named column was calculated by
+// one of the Calc, CalcString or Aggregate methods. It returns false if the
+// column was not created by one of those methods or has since been replaced.
+func (dt *DataTable) Lineage(name string) (Lineage, bool) {
+	l, exists := dt.lineage[name]
+	if !exists {
+		return Lineage{}, false
+	}
+	l.Sources = append([]string(nil), l.Sources...)
+	l.Keys = append([]string(nil), l.Keys...)
+	return l, true
+}
+
+// recordLineage records that the named column was created by op using the
+// calculator or aggregator x.
+func (dt *DataTable) recordLineage(name, op string, x interface{}) {
+	if _, exists := dt.colorder[name]; !exists {
+		return
+	}
+	l := Lineage{Op: op}
+	if d, ok := x.(described); ok {
+		l.Expr, l.Sources = d.description()
+	}
+	if op == "Aggregate" {
+		l.Keys = dt.KeyNames()
+	}
+	if dt.lineage == nil {
+		dt.lineage = map[string]Lineage{}
+	}
+	dt.lineage[name] = l
+}
+
+// described is implemented by calculators and aggregators that can describe
+// themselves.
+type described interface {
+	description() (expr string, sources []string)
+}
+
+// describedAggregator is an Aggregator that can describe itself.
+type describedAggregator struct {
+	Aggregator
+	expr    string
+	sources []string
+}
+
+func (a describedAggregator) description() (string, []string) {
+	return a.expr, a.sources
+}
+
+func describeAggregator(a Aggregator, expr string, sources ...string) Aggregator {
+	return describedAggregator{Aggregator: a, expr: expr, sources: sources}
+}
+
+// describedCalculator is a Calculator that can describe itself.
+type describedCalculator struct {
+	Calculator
+	expr    string
+	sources []string
+}
+
+func (c describedCalculator) description() (string, []string) {
+	return c.expr, c.sources
+}
+
+func describeCalculator(c Calculator, expr string, sources ...string) Calculator {
+	return describedCalculator{Calculator: c, expr: expr, sources: sources}
+}
+
+// callExpr formats a call of the named function with the given arguments.
+// Calculators are formatted using their own descriptions.
+func callExpr(name string, args ...interface{}) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case described:
+			parts[i], _ = v.description()
+		case Calculator, Matcher:
+			parts[i] = "?"
+		default:
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+	return name + "(" + strings.Join(parts, ", ") + ")"
+}
+
+// calcSources returns the names of the columns read by each of the
+// calculators, without duplicates.
+func calcSources(cs ...Calculator) []string {
+	var sources []string
+	seen := map[string]bool{}
+	for _, c := range cs {
+		d, ok := c.(described)
+		if !ok {
+			continue
+		}
+		_, srcs := d.description()
+		for _, s := range srcs {
+			if !seen[s] {
+				seen[s] = true
+				sources = append(sources, s)
+			}
+		}
+	}
+	return sources
+}
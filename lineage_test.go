@@ -0,0 +1,49 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineage(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"north", "south", "north"})
+	dt.AddColumn("price", []float64{2, 3, 4})
+	dt.AddColumn("qty", []float64{1, 5, 2})
+	dt.SetKeys("region")
+
+	dt.Calc("revenue", Mul(Col("price"), Sub(Col("qty"), Lit(0.5))))
+	dt.Aggregate("avg_price", Mean("price"))
+	dt.Calc("custom", CalculatorFunc(func(row RowRef) float64 { return 1 }))
+	dt.Calc("mixed", Add(Col("qty"), CalculatorFunc(func(row RowRef) float64 { return 1 })))
+
+	testCases := []struct {
+		name string
+		want Lineage
+	}{
+		{"revenue", Lineage{Op: "Calc", Expr: "Mul(Col(price), Sub(Col(qty), Lit(0.5)))", Sources: []string{"price", "qty"}}},
+		{"avg_price", Lineage{Op: "Aggregate", Expr: "Mean(price)", Sources: []string{"price"}, Keys: []string{"region"}}},
+		{"custom", Lineage{Op: "Calc"}},
+		{"mixed", Lineage{Op: "Calc", Expr: "Add(Col(qty), ?)", Sources: []string{"qty"}}},
+	}
+	for _, tc := range testCases {
+		got, ok := dt.Lineage(tc.name)
+		if !ok {
+			t.Errorf("%s: no lineage recorded", tc.name)
+			continue
+		}
+		if got.Op != tc.want.Op || got.Expr != tc.want.Expr || len(got.Sources) != len(tc.want.Sources) ||
+			(len(got.Sources) > 0 && !reflect.DeepEqual(got.Sources, tc.want.Sources)) ||
+			len(got.Keys) != len(tc.want.Keys) || (len(got.Keys) > 0 && !reflect.DeepEqual(got.Keys, tc.want.Keys)) {
+			t.Errorf("%s: got %+v, wanted %+v", tc.name, got, tc.want)
+		}
+	}
+
+	if _, ok := dt.Lineage("price"); ok {
+		t.Errorf("unexpected lineage for a column that was not calculated")
+	}
+	dt.AddColumn("revenue", []float64{0, 0, 0})
+	if _, ok := dt.Lineage("revenue"); ok {
+		t.Errorf("unexpected lineage for a replaced column")
+	}
+}
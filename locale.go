@@ -0,0 +1,112 @@
+package datatable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Locale describes how numbers and dates are written in textual input.
+// The zero value describes numbers such as 1234.5 and month-first dates.
+type Locale struct {
+	// Decimal is the decimal separator. The default is '.'.
+	Decimal rune
+
+	// Thousands is the digit grouping separator, which is removed before
+	// parsing. The default is no grouping separator.
+	Thousands rune
+
+	// DayFirst indicates that numeric dates are written with the day before
+	// the month, as in 31/12/2023, rather than 12/31/2023.
+	DayFirst bool
+}
+
+// ParseFloat parses s as a number written according to the locale.
+func (l Locale) ParseFloat(s string) (float64, error) {
+	if l.Thousands != 0 {
+		s = strings.ReplaceAll(s, string(l.Thousands), "")
+	}
+	if l.Decimal != 0 && l.Decimal != '.' {
+		if strings.ContainsRune(s, '.') {
+			return 0, fmt.Errorf("invalid number for locale: %q", s)
+		}
+		s = strings.ReplaceAll(s, string(l.Decimal), ".")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+var (
+	isoLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+	dayFirstLayouts = []string{
+		"2/1/2006 15:04:05", "2/1/2006 15:04", "2/1/2006",
+		"2.1.2006 15:04:05", "2.1.2006 15:04", "2.1.2006",
+		"2-1-2006",
+	}
+	monthFirstLayouts = []string{
+		"1/2/2006 15:04:05", "1/2/2006 15:04", "1/2/2006",
+		"1.2.2006 15:04:05", "1.2.2006 15:04", "1.2.2006",
+		"1-2-2006",
+	}
+)
+
+// ParseDate parses s as a date, optionally with a time, written according to
+// the locale. ISO 8601 dates are always accepted. Numeric dates separated by
+// slashes, periods or hyphens are read day first or month first according to
+// DayFirst. Dates without a time zone are interpreted in UTC.
+func (l Locale) ParseDate(s string) (time.Time, error) {
+	layouts := monthFirstLayouts
+	if l.DayFirst {
+		layouts = dayFirstLayouts
+	}
+	for _, set := range [][]string{isoLayouts, layouts} {
+		for _, layout := range set {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %q", s)
+}
+
+// SetLocale sets the locale used by ParseRow to parse numbers and dates for
+// all columns that do not have a locale set by SetColumnLocale.
+func (dt *DataTable) SetLocale(l Locale) {
+	dt.locale = l
+}
+
+// SetColumnLocale sets the locale used by ParseRow to parse numbers and dates
+// in the named column.
+func (dt *DataTable) SetColumnLocale(name string, l Locale) error {
+	if _, exists := dt.colorder[name]; !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.collocales == nil {
+		dt.collocales = map[string]Locale{}
+	}
+	dt.collocales[name] = l
+	return nil
+}
+
+// columnLocale returns the locale to use when parsing values for column c.
+func (dt *DataTable) columnLocale(c int) Locale {
+	if l, exists := dt.collocales[dt.colnames[c]]; exists {
+		return l
+	}
+	return dt.locale
+}
+
+// parseDateSeconds parses s as a date, returning seconds since the Unix epoch.
+func (l Locale) parseDateSeconds(s string) (float64, error) {
+	t, err := l.ParseDate(s)
+	if err != nil {
+		return 0, err
+	}
+	return unixSeconds(t), nil
+}
@@ -0,0 +1,117 @@
+package datatable
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocaleParseFloat(t *testing.T) {
+	testCases := []struct {
+		locale   Locale
+		input    string
+		expected float64
+		err      bool
+	}{
+		{locale: Locale{}, input: "1234.5", expected: 1234.5},
+		{locale: Locale{Thousands: ','}, input: "1,234.5", expected: 1234.5},
+		{locale: Locale{Decimal: ',', Thousands: '.'}, input: "1.234,5", expected: 1234.5},
+		{locale: Locale{Decimal: ',', Thousands: ' '}, input: "1 234 567,25", expected: 1234567.25},
+		{locale: Locale{Decimal: ','}, input: "1.5", err: true},
+		{locale: Locale{}, input: "1,5", err: true},
+	}
+
+	for _, tc := range testCases {
+		v, err := tc.locale.ParseFloat(tc.input)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: got %v, wanted error", tc.input, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.input, err)
+			continue
+		}
+		if v != tc.expected {
+			t.Errorf("%q: got %v, wanted %v", tc.input, v, tc.expected)
+		}
+	}
+}
+
+func TestLocaleParseDate(t *testing.T) {
+	testCases := []struct {
+		locale   Locale
+		input    string
+		expected time.Time
+	}{
+		{locale: Locale{}, input: "2023-04-01", expected: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{locale: Locale{DayFirst: true}, input: "2023-04-01", expected: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{locale: Locale{}, input: "04/01/2023", expected: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{locale: Locale{DayFirst: true}, input: "04/01/2023", expected: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{locale: Locale{DayFirst: true}, input: "31.12.2023 18:30", expected: time.Date(2023, 12, 31, 18, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		v, err := tc.locale.ParseDate(tc.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.input, err)
+			continue
+		}
+		if !v.Equal(tc.expected) {
+			t.Errorf("%q: got %v, wanted %v", tc.input, v, tc.expected)
+		}
+	}
+
+	if _, err := (Locale{}).ParseDate("31/12/2023"); err == nil {
+		t.Errorf("got no error for day first date with month first locale")
+	}
+}
+
+func TestParseRowLocale(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("amount", []float64{})
+	dt.AddColumn("rate", []float64{})
+	dt.SetLocale(Locale{Decimal: ',', Thousands: '.', DayFirst: true})
+	dt.SetColumnLocale("rate", Locale{})
+
+	if err := dt.ParseRow("1.234,5", "0.25"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []interface{}{1234.5, 0.25}
+	row, _ := dt.Row(0)
+	if !equivalentRows([][]interface{}{row}, [][]interface{}{expected}) {
+		t.Errorf("got %v, wanted %v", row, expected)
+	}
+
+	// dates are only read into numeric columns by ReadCSV with ParseDates
+	if err := dt.ParseRow("02/01/2023", "0.5"); err == nil {
+		t.Errorf("got no error parsing a date into a numeric column")
+	}
+	if dt.Len() != 1 {
+		t.Errorf("got %d rows after failed parse, wanted 1", dt.Len())
+	}
+}
+
+func TestReadCSVLocale(t *testing.T) {
+	input := "name;amount;rate;when\na;1.234,5;0.5;31/01/2023\nb;;1.5;01/02/2023\n"
+	dt, err := ReadCSV(strings.NewReader(input), CSVOptions{
+		Comma:         ';',
+		Locale:        Locale{Decimal: ',', Thousands: '.', DayFirst: true},
+		ColumnLocales: map[string]Locale{"rate": {}},
+		ParseDates:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"a", 1234.5, 0.5, unixSeconds(time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC))},
+		{"b", math.NaN(), 1.5, unixSeconds(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
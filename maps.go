@@ -0,0 +1,214 @@
+package datatable
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ToMap returns a map from the values of the string column keyCol to the
+// values of the numeric column valueCol. An error wrapping ErrDuplicateKey
+// is returned if a key occurs in more than one row; use ToMapAggregate to
+// combine the values of duplicate keys instead.
+func (dt *DataTable) ToMap(keyCol, valueCol string) (map[string]float64, error) {
+	kc, vc, err := dt.mapColumns(keyCol, valueCol)
+	if err != nil {
+		return nil, err
+	}
+	if !dt.isFloatCol(vc) {
+		return nil, ErrMismatchedColumnTypes
+	}
+
+	m := make(map[string]float64, dt.Len())
+	for i, k := range dt.cols[kc].s {
+		if _, exists := m[k]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKey, k)
+		}
+		m[k] = dt.cols[vc].f[i]
+	}
+	return m, nil
+}
+
+// ToMapString returns a map from the values of the string column keyCol to the
+// values of the string column valueCol. An error wrapping ErrDuplicateKey
+// is returned if a key occurs in more than one row.
+func (dt *DataTable) ToMapString(keyCol, valueCol string) (map[string]string, error) {
+	kc, vc, err := dt.mapColumns(keyCol, valueCol)
+	if err != nil {
+		return nil, err
+	}
+	if dt.isFloatCol(vc) {
+		return nil, ErrMismatchedColumnTypes
+	}
+
+	m := make(map[string]string, dt.Len())
+	for i, k := range dt.cols[kc].s {
+		if _, exists := m[k]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKey, k)
+		}
+		m[k] = dt.cols[vc].s[i]
+	}
+	return m, nil
+}
+
+// ToMapAggregate returns a map from the values of the string column keyCol
+// to the result of executing the aggregator a against the rows sharing
+// each key. The table does not need to be sorted by keyCol.
+func (dt *DataTable) ToMapAggregate(keyCol string, a Aggregator) (map[string]float64, error) {
+	kc, exists := dt.colorder[keyCol]
+	if !exists {
+		return nil, fmt.Errorf("unknown column: %s", keyCol)
+	}
+	if dt.isFloatCol(kc) {
+		return nil, ErrMismatchedColumnTypes
+	}
+
+	groups := make(map[string][]int)
+	for i, k := range dt.cols[kc].s {
+		groups[k] = append(groups[k], i)
+	}
+
+	m := make(map[string]float64, len(groups))
+	for k, indices := range groups {
+		m[k] = a.Aggregate(&StaticRowGroup{dt: dt, indices: indices})
+	}
+	return m, nil
+}
+
+// mapColumns looks up the positions of the key and value columns used for
+// map export, checking that the key column holds strings.
+func (dt *DataTable) mapColumns(keyCol, valueCol string) (int, int, error) {
+	kc, exists := dt.colorder[keyCol]
+	if !exists {
+		return 0, 0, fmt.Errorf("unknown column: %s", keyCol)
+	}
+	vc, exists := dt.colorder[valueCol]
+	if !exists {
+		return 0, 0, fmt.Errorf("unknown column: %s", valueCol)
+	}
+	if dt.isFloatCol(kc) {
+		return 0, 0, ErrMismatchedColumnTypes
+	}
+	return kc, vc, nil
+}
+
+// FromMap creates a new data table with two columns: a string column named
+// keyName holding the keys of m and a numeric column named valueName holding
+// the corresponding values. Rows are ordered by key.
+func FromMap(m map[string]float64, keyName, valueName string) *DataTable {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]float64, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+	}
+
+	dt := &DataTable{}
+	dt.AddStringColumn(keyName, keys)
+	dt.AddColumn(valueName, values)
+	return dt
+}
+
+// FromMaps creates a new data table with one row per map in rows. The columns
+// of the table are the union of the keys of all the maps, ordered by name.
+// Values may be strings or any Go numeric type, which are converted to float64.
+// A column's type is determined by the first non-nil value found for it and
+// an error is returned if a later value has a different type. Keys missing
+// from a map, or holding nil, are filled with NaN or the empty string.
+func FromMaps(rows []map[string]interface{}) (*DataTable, error) {
+	floats := map[string][]float64{}
+	strs := map[string][]string{}
+	for _, row := range rows {
+		for name, v := range row {
+			if v == nil {
+				continue
+			}
+			_, isFloat := floats[name]
+			_, isString := strs[name]
+			if isFloat || isString {
+				continue
+			}
+			if _, ok := v.(string); ok {
+				strs[name] = make([]string, len(rows))
+			} else if _, ok := toFloat(v); ok {
+				floats[name] = fillNaN(len(rows))
+			} else {
+				return nil, fmt.Errorf("unsupported type %T for column %s", v, name)
+			}
+		}
+	}
+
+	for i, row := range rows {
+		for name, v := range row {
+			if v == nil {
+				continue
+			}
+			if col, ok := floats[name]; ok {
+				f, ok := toFloat(v)
+				if !ok {
+					return nil, fmt.Errorf("%w: column %s, row %d", ErrMismatchedColumnTypes, name, i)
+				}
+				col[i] = f
+				continue
+			}
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: column %s, row %d", ErrMismatchedColumnTypes, name, i)
+			}
+			strs[name][i] = s
+		}
+	}
+
+	names := make([]string, 0, len(floats)+len(strs))
+	for name := range floats {
+		names = append(names, name)
+	}
+	for name := range strs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dt := &DataTable{}
+	for _, name := range names {
+		if col, ok := floats[name]; ok {
+			dt.AddColumn(name, col)
+		} else {
+			dt.AddStringColumn(name, strs[name])
+		}
+	}
+	return dt, nil
+}
+
+// toFloat converts any Go numeric value to a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch tv := v.(type) {
+	case float64:
+		return tv, true
+	case float32:
+		return float64(tv), true
+	case int:
+		return float64(tv), true
+	case int8:
+		return float64(tv), true
+	case int16:
+		return float64(tv), true
+	case int32:
+		return float64(tv), true
+	case int64:
+		return float64(tv), true
+	case uint:
+		return float64(tv), true
+	case uint8:
+		return float64(tv), true
+	case uint16:
+		return float64(tv), true
+	case uint32:
+		return float64(tv), true
+	case uint64:
+		return float64(tv), true
+	}
+	return 0, false
+}
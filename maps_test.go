@@ -0,0 +1,107 @@
+package datatable
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("code", []string{"gb", "fr", "de"})
+	dt.AddStringColumn("name", []string{"Britain", "France", "Germany"})
+	dt.AddColumn("rate", []float64{0.2, 0.25, 0.19})
+
+	m, err := dt.ToMap("code", "rate")
+	if err != nil {
+		t.Fatalf("ToMap: unexpected error: %v", err)
+	}
+	expected := map[string]float64{"gb": 0.2, "fr": 0.25, "de": 0.19}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("got %v, wanted %v", m, expected)
+	}
+
+	ms, err := dt.ToMapString("code", "name")
+	if err != nil {
+		t.Fatalf("ToMapString: unexpected error: %v", err)
+	}
+	expectedString := map[string]string{"gb": "Britain", "fr": "France", "de": "Germany"}
+	if !reflect.DeepEqual(ms, expectedString) {
+		t.Errorf("got %v, wanted %v", ms, expectedString)
+	}
+
+	if _, err := dt.ToMap("code", "name"); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if _, err := dt.ToMap("rate", "rate"); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if _, err := dt.ToMap("code", "missing"); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
+func TestToMapDuplicateKeys(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("code", []string{"gb", "fr", "gb"})
+	dt.AddColumn("sales", []float64{1, 2, 3})
+
+	if _, err := dt.ToMap("code", "sales"); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("got %v, wanted %v", err, ErrDuplicateKey)
+	}
+
+	m, err := dt.ToMapAggregate("code", Sum("sales"))
+	if err != nil {
+		t.Fatalf("ToMapAggregate: unexpected error: %v", err)
+	}
+	expected := map[string]float64{"gb": 4, "fr": 2}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("got %v, wanted %v", m, expected)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	dt := FromMap(map[string]float64{"gb": 0.2, "fr": 0.25, "de": 0.19}, "code", "rate")
+
+	expectedRows := [][]interface{}{
+		{"code", "rate"},
+		{"de", 0.19},
+		{"fr", 0.25},
+		{"gb", 0.2},
+	}
+	rows := dt.RawRows(true)
+	if !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+}
+
+func TestFromMaps(t *testing.T) {
+	dt, err := FromMaps([]map[string]interface{}{
+		{"name": "a", "count": 1, "score": 0.5},
+		{"name": "b", "count": int64(2)},
+		{"count": float32(3), "score": nil, "extra": "x"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"count", "extra", "name", "score"},
+		{1.0, "", "a", 0.5},
+		{2.0, "", "b", math.NaN()},
+		{3.0, "x", "", math.NaN()},
+	}
+	rows := dt.RawRows(true)
+	if !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	_, err = FromMaps([]map[string]interface{}{
+		{"name": "a"},
+		{"name": 2},
+	})
+	if !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+}
@@ -0,0 +1,50 @@
+package datatable
+
+import (
+	"sync"
+)
+
+// CacheMatches returns a Matcher that behaves like m but remembers the rows
+// of the last table it was evaluated against by Matches. While that table is
+// unmodified, repeated calls to methods that find matching rows, such as
+// SelectWhere, CountWhere, CalcWhere and AggregateWhere, reuse the remembered
+// rows instead of evaluating m again. Any modification to the table causes m
+// to be evaluated afresh. m must give the same result each time it is
+// evaluated against an unmodified row. The returned Matcher is safe for
+// concurrent use with different tables.
+func CacheMatches(m Matcher) Matcher {
+	return &cachedMatcher{m: m}
+}
+
+// cachedMatcher is a Matcher that remembers the rows it matched.
+type cachedMatcher struct {
+	m Matcher
+
+	mu      sync.Mutex
+	dt      *DataTable // table the rows were found in, nil if none
+	version uint64     // version of dt when the rows were found
+	rows    []int
+}
+
+func (cm *cachedMatcher) Match(row RowRef) bool {
+	return cm.m.Match(row)
+}
+
+// matches returns the rows of dt matched by the underlying matcher, reusing
+// the remembered rows if dt has not been modified since they were found.
+func (cm *cachedMatcher) matches(dt *DataTable) []int {
+	cm.mu.Lock()
+	if cm.dt == dt && cm.version == dt.version {
+		rows := append([]int{}, cm.rows...)
+		cm.mu.Unlock()
+		return rows
+	}
+	cm.mu.Unlock()
+
+	rows := dt.Matches(cm.m)
+
+	cm.mu.Lock()
+	cm.dt, cm.version, cm.rows = dt, dt.version, append([]int{}, rows...)
+	cm.mu.Unlock()
+	return rows
+}
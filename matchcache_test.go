@@ -0,0 +1,60 @@
+package datatable
+
+import (
+	"testing"
+)
+
+func TestCacheMatches(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("x", []float64{1, 5, 3, 7})
+
+	evals := 0
+	m := CacheMatches(MatcherFunc(func(row RowRef) bool {
+		evals++
+		v, _ := row.FloatValue("x")
+		return v > 2
+	}))
+
+	if got := dt.CountWhere(m); got != 3 {
+		t.Errorf("got count %d, wanted 3", got)
+	}
+	if evals != 4 {
+		t.Errorf("got %d evaluations, wanted 4", evals)
+	}
+
+	sel, _ := dt.SelectWhere([]string{"x"}, m)
+	dt.AggregateWhere("total", Sum("x"), m)
+	if sel.Len() != 3 || evals != 4 {
+		t.Errorf("got %d rows and %d evaluations, wanted 3 rows and no further evaluations", sel.Len(), evals)
+	}
+
+	// Adding a column modifies the table so the matcher is evaluated again
+	if evals = 0; dt.CountWhere(m) != 3 || evals != 4 {
+		t.Errorf("got %d evaluations after modification, wanted 4", evals)
+	}
+
+	dt.SetFloatValue("x", 0, 10)
+	if got := dt.Matches(m); len(got) != 4 {
+		t.Errorf("got matches %v after changing a value, wanted all rows", got)
+	}
+
+	// A different table does not reuse the cached rows
+	dt2 := dt.Clone()
+	dt2.RemoveRows(LessThan("x", 6))
+	if got := dt2.CountWhere(m); got != 2 {
+		t.Errorf("got count %d for second table, wanted 2", got)
+	}
+}
+
+func TestCacheMatchesAfterSwap(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("x", []float64{1, 2, 3})
+	m := CacheMatches(GreaterThan("x", 2.5))
+	if got := dt.Matches(m); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("got %v, wanted [2]", got)
+	}
+	dt.Swap(0, 2)
+	if got := dt.Matches(m); len(got) != 1 || got[0] != 0 {
+		t.Errorf("got %v after Swap, wanted [0]", got)
+	}
+}
@@ -0,0 +1,120 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+)
+
+// SetNull sets the value of the named column in a single row to null, which
+// marks it as missing. Null values are distinct from NaN and the empty string,
+// which remain ordinary values, but are held as NaN or the empty string so
+// operations that do not examine nulls treat them as such. Aggregators such as
+// Sum and Mean skip null values, the IsNull and IsNotNull matchers select
+// them and CSV and ToJSON write them as empty fields and null. Appending
+// tables with different columns pads the missing columns with nulls. Setting
+// a value replaces the null. An error wrapping ErrRowOutOfRange is returned if
+// the row number exceeds the bounds of the table.
+func (dt *DataTable) SetNull(name string, row int) error {
+	if err := dt.CheckRow(row); err != nil {
+		return err
+	}
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	cv := &dt.cols[c]
+	if cv.f != nil {
+		cv.setFloat(row, math.NaN())
+	} else {
+		cv.s[row] = ""
+	}
+	cv.setNull(row, true)
+	dt.markDirty(c)
+	dt.mutated()
+	return nil
+}
+
+// NullCount returns the number of null values in the named column.
+func (dt *DataTable) NullCount(name string) (int, error) {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return 0, fmt.Errorf("unknown column: %s", name)
+	}
+	count := 0
+	for _, null := range dt.cols[c].null {
+		if null {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// IsNull returns a Matcher that matches rows whose value in the named column
+// is null.
+func IsNull(name string) Matcher {
+	return MatcherFunc(func(row RowRef) bool {
+		return row.IsNull(name)
+	})
+}
+
+// IsNotNull returns a Matcher that matches rows whose value in the named column
+// is not null. It does not match rows of tables without the column.
+func IsNotNull(name string) Matcher {
+	return MatcherFunc(func(row RowRef) bool {
+		_, exists := row.dt.colorder[name]
+		return exists && !row.IsNull(name)
+	})
+}
+
+// isNull reports whether position i holds a null value.
+func (cv colvals) isNull(i int) bool {
+	return i < len(cv.null) && cv.null[i]
+}
+
+// setNull marks whether position i holds a null value.
+func (cv *colvals) setNull(i int, null bool) {
+	if i >= len(cv.null) {
+		if !null {
+			return
+		}
+		cv.null = append(cv.null, make([]bool, i+1-len(cv.null))...)
+	}
+	cv.null[i] = null
+}
+
+// setNullRange marks the positions from start up to end as null.
+func (cv *colvals) setNullRange(start, end int) {
+	for i := start; i < end; i++ {
+		cv.setNull(i, true)
+	}
+}
+
+// appendNull appends a null value.
+func (cv *colvals) appendNull() {
+	if cv.f != nil {
+		cv.appendFloat(math.NaN())
+	} else {
+		cv.s = append(cv.s, "")
+	}
+	cv.setNull(cv.Len()-1, true)
+}
+
+// appendNulls marks the values appended from position start onwards as null
+// where the corresponding values of src are null.
+func (cv *colvals) appendNulls(src colvals, start int) {
+	for i, null := range src.null {
+		if null {
+			cv.setNull(start+i, true)
+		}
+	}
+}
+
+// pickNulls marks the values picked from src at the given indices as null
+// where the value in src is null or the index is -1.
+func (cv *colvals) pickNulls(src colvals, indices []int) {
+	for i, idx := range indices {
+		if idx < 0 || src.isNull(idx) {
+			cv.setNull(i, true)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package datatable
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNulls(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"c", "a", "b", "d"})
+	dt.AddColumn("v", []float64{3, 1, 2, math.NaN()})
+	dt.AddStringColumn("s", []string{"z", "x", "y", ""})
+	if err := dt.SetNull("v", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.SetNull("s", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.AppendRow([]interface{}{"e", 5.0, nil}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// nulls follow their rows when sorted
+	dt.SetKeys("k")
+	if got := dt.Matches(IsNull("v")); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("got null v rows %v, wanted [1]", got)
+	}
+	if got := dt.Matches(IsNull("s")); !reflect.DeepEqual(got, []int{0, 4}) {
+		t.Errorf("got null s rows %v, wanted [0 4]", got)
+	}
+	if got := dt.CountWhere(IsNotNull("v")); got != 4 {
+		t.Errorf("got %d non-null v rows, wanted 4", got)
+	}
+	if n, _ := dt.NullCount("s"); n != 2 {
+		t.Errorf("got null count %d, wanted 2", n)
+	}
+
+	// null values hold NaN but NaN values are not null
+	dt.RemoveRows(MatcherFunc(func(row RowRef) bool {
+		v, _ := row.FloatValue("v")
+		return math.IsNaN(v) && !row.IsNull("v")
+	}))
+	if got := dt.Reduce(Mean("v")); got != 3 {
+		t.Errorf("got mean %v, wanted 3", got)
+	}
+	if got := dt.Reduce(Sum("v")); got != 9 {
+		t.Errorf("got sum %v, wanted 9", got)
+	}
+
+	buf := new(bytes.Buffer)
+	dt.CSV(buf)
+	if expected := "k,v,s\na,1,\nb,,y\nc,3,z\ne,5,\n"; buf.String() != expected {
+		t.Errorf("got %q, wanted %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	dt.ToJSON(buf)
+	if expected := `[{"k":"a","v":1,"s":null},{"k":"b","v":null,"s":"y"},{"k":"c","v":3,"s":"z"},{"k":"e","v":5,"s":null}]` + "\n"; buf.String() != expected {
+		t.Errorf("got %s, wanted %s", buf.String(), expected)
+	}
+	back, err := FromJSON(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := back.Matches(IsNull("s")); !reflect.DeepEqual(got, []int{0, 3}) {
+		t.Errorf("got null s rows %v, wanted [0 3]", got)
+	}
+
+	// setting a value replaces the null
+	dt.SetFloatValue("v", 1, 2)
+	if dt.CountWhere(IsNull("v")) != 0 {
+		t.Errorf("got null after setting value")
+	}
+}
+
+func TestAppendPadsWithNulls(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("a", []float64{1})
+	dt2 := &DataTable{}
+	dt2.AddStringColumn("b", []string{"x"})
+
+	if err := dt.Append(dt2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := dt.Matches(IsNull("a")); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("got null a rows %v, wanted [1]", got)
+	}
+	if got := dt.Matches(IsNull("b")); !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("got null b rows %v, wanted [0]", got)
+	}
+}
+
+func TestReadCSVNullValues(t *testing.T) {
+	input := "name,score\nann,NA\n,3\nbob,4\n"
+	dt, err := ReadCSV(strings.NewReader(input), CSVOptions{NullValues: []string{"NA", ""}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if typ, _ := dt.ColumnType("score"); typ != ColNumeric {
+		t.Errorf("got type %v, wanted %v", typ, ColNumeric)
+	}
+	if got := dt.Matches(IsNull("score")); !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("got null score rows %v, wanted [0]", got)
+	}
+	if got := dt.Matches(IsNull("name")); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("got null name rows %v, wanted [1]", got)
+	}
+}
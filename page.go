@@ -0,0 +1,45 @@
+package datatable
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Page returns a new table holding up to limit rows of the table starting at
+// the row at position offset, along with the total number of rows, for
+// serving a large table a page at a time. If keys are given the rows are
+// ordered by those columns, without modifying the table, and otherwise the
+// table's current order is used. Rows with equal keys keep their relative
+// order in the table, so the pages of an unchanged table do not overlap or
+// skip rows. The page has the columns of the table and no keys set, and is
+// empty if offset is at or beyond the last row.
+func (dt *DataTable) Page(offset, limit int, keys ...string) (*DataTable, int, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("invalid offset: %d", offset)
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("invalid limit: %d", limit)
+	}
+	cols := make([]int, len(keys))
+	for i, name := range keys {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil, 0, fmt.Errorf("unknown column: %s", name)
+		}
+		cols[i] = c
+	}
+
+	indices := fillSeq(dt.Len())
+	if len(cols) > 0 {
+		sort.SliceStable(indices, func(i, j int) bool {
+			return dt.compareRows(cols, indices[i], indices[j]) < 0
+		})
+	}
+	start := min(offset, len(indices))
+	end := start + min(limit, len(indices)-start)
+	page, err := dt.SelectIndex(dt.Names(), indices[start:end])
+	if err != nil {
+		return nil, 0, err
+	}
+	return page, dt.Len(), nil
+}
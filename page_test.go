@@ -0,0 +1,60 @@
+package datatable
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("name", []string{"e", "b", "d", "a", "c"})
+	dt.AddColumn("rank", []float64{2, 1, 2, 1, 3})
+
+	testCases := []struct {
+		offset, limit int
+		keys          []string
+		want          []string
+	}{
+		{offset: 0, limit: 2, want: []string{"e", "b"}},
+		{offset: 4, limit: 2, want: []string{"c"}},
+		{offset: 9, limit: 2, want: []string{}},
+		{offset: 1, limit: math.MaxInt, want: []string{"b", "d", "a", "c"}},
+		{offset: math.MaxInt, limit: math.MaxInt, want: []string{}},
+		{offset: 0, limit: 2, keys: []string{"rank"}, want: []string{"b", "a"}},
+		{offset: 2, limit: 2, keys: []string{"rank"}, want: []string{"e", "d"}},
+		{offset: 1, limit: 3, keys: []string{"rank", "name"}, want: []string{"b", "d", "e"}},
+	}
+	for _, tc := range testCases {
+		page, total, err := dt.Page(tc.offset, tc.limit, tc.keys...)
+		if err != nil {
+			t.Fatalf("Page(%d, %d, %v): unexpected error: %v", tc.offset, tc.limit, tc.keys, err)
+		}
+		if total != 5 {
+			t.Errorf("Page(%d, %d, %v): got total %d, wanted 5", tc.offset, tc.limit, tc.keys, total)
+		}
+		got := page.cols[page.colorder["name"]].s
+		if len(got) != len(tc.want) {
+			t.Errorf("Page(%d, %d, %v): got %v, wanted %v", tc.offset, tc.limit, tc.keys, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("Page(%d, %d, %v): got %v, wanted %v", tc.offset, tc.limit, tc.keys, got, tc.want)
+				break
+			}
+		}
+	}
+	if dt.cols[0].s[0] != "e" {
+		t.Errorf("paging modified the table")
+	}
+
+	if _, _, err := dt.Page(-1, 2); err == nil {
+		t.Errorf("got no error for negative offset")
+	}
+	if _, _, err := dt.Page(0, 0); err == nil {
+		t.Errorf("got no error for zero limit")
+	}
+	if _, _, err := dt.Page(0, 2, "missing"); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
@@ -0,0 +1,206 @@
+package datatable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// A PartialAggregator is an Aggregator whose results over separate groups of
+// rows can be combined exactly, allowing rows held on separate machines or
+// read in chunks to be aggregated without bringing them together. Rows are
+// added to the aggregator's partial state with Accumulate and the state of
+// another aggregator of the same kind, perhaps one in another process, can be
+// combined with Merge. Aggregate calculates the result for a single group of
+// rows without using or modifying the partial state.
+type PartialAggregator interface {
+	Aggregator
+
+	// Accumulate adds the rows of rg to the partial state.
+	Accumulate(rg RowGroup)
+
+	// State returns an encoding of the partial state suitable for passing
+	// to Merge.
+	State() []byte
+
+	// Merge combines a state returned by State into the partial state. An
+	// error is returned if the state was produced by a different kind of
+	// aggregator or is malformed.
+	Merge(state []byte) error
+
+	// Result returns the aggregate of all the rows accumulated and merged.
+	Result() float64
+}
+
+// PartialSum returns a PartialAggregator that finds the sum of a numeric
+// column.
+func PartialSum(name string) PartialAggregator {
+	return &moments{name: name, kind: momentSum}
+}
+
+// PartialCount returns a PartialAggregator that finds the number of rows.
+func PartialCount() PartialAggregator {
+	return &moments{kind: momentCount}
+}
+
+// PartialMean returns a PartialAggregator that finds the mean value of a
+// numeric column.
+func PartialMean(name string) PartialAggregator {
+	return &moments{name: name, kind: momentMean}
+}
+
+// PartialVariance returns a PartialAggregator that finds the sample variance
+// of a numeric column. Partial states are combined using the count, mean and
+// sum of squared differences from the mean of each, which is numerically
+// stable.
+func PartialVariance(name string) PartialAggregator {
+	return &moments{name: name, kind: momentVariance}
+}
+
+// PartialMin returns a PartialAggregator that finds the minimum value of a
+// numeric column, or NaN if there are no rows.
+func PartialMin(name string) PartialAggregator {
+	return &moments{name: name, kind: momentMin}
+}
+
+// PartialMax returns a PartialAggregator that finds the maximum value of a
+// numeric column, or NaN if there are no rows.
+func PartialMax(name string) PartialAggregator {
+	return &moments{name: name, kind: momentMax}
+}
+
+type momentKind byte
+
+const (
+	momentSum momentKind = iota + 1
+	momentCount
+	momentMean
+	momentVariance
+	momentMin
+	momentMax
+)
+
+// moments holds the partial state shared by all the partial aggregators.
+type moments struct {
+	name string
+	kind momentKind
+
+	n    int64
+	sum  float64
+	mean float64
+	m2   float64 // sum of squared differences from the mean
+	min  float64
+	max  float64
+}
+
+// momentsStateLen is the length of an encoded state: the kind followed by
+// the count and five floats.
+const momentsStateLen = 1 + 8*6
+
+func (m *moments) Aggregate(rg RowGroup) float64 {
+	m2 := &moments{name: m.name, kind: m.kind}
+	m2.Accumulate(rg)
+	return m2.Result()
+}
+
+func (m *moments) Accumulate(rg RowGroup) {
+	for rg.Next() {
+		m.accumulateRow(rg)
+	}
+}
+
+// accumulateRow adds the current row of rg to the state.
+func (m *moments) accumulateRow(rg RowGroup) {
+	if m.kind == momentCount {
+		m.n++
+		return
+	}
+	if isNullIn(rg, m.name) {
+		return
+	}
+	v, _ := rg.FloatValue(m.name)
+	m.add(v)
+}
+
+// add adds a single value to the state using Welford's algorithm.
+func (m *moments) add(v float64) {
+	if m.n == 0 || v < m.min {
+		m.min = v
+	}
+	if m.n == 0 || v > m.max {
+		m.max = v
+	}
+	m.n++
+	m.sum += v
+	d := v - m.mean
+	m.mean += d / float64(m.n)
+	m.m2 += d * (v - m.mean)
+}
+
+func (m *moments) State() []byte {
+	buf := make([]byte, momentsStateLen)
+	buf[0] = byte(m.kind)
+	binary.LittleEndian.PutUint64(buf[1:], uint64(m.n))
+	for i, v := range []float64{m.sum, m.mean, m.m2, m.min, m.max} {
+		binary.LittleEndian.PutUint64(buf[9+8*i:], math.Float64bits(v))
+	}
+	return buf
+}
+
+func (m *moments) Merge(state []byte) error {
+	if len(state) != momentsStateLen {
+		return fmt.Errorf("invalid partial state: length %d", len(state))
+	}
+	if momentKind(state[0]) != m.kind {
+		return fmt.Errorf("invalid partial state: produced by a different aggregator")
+	}
+	var o moments
+	o.n = int64(binary.LittleEndian.Uint64(state[1:]))
+	vals := []*float64{&o.sum, &o.mean, &o.m2, &o.min, &o.max}
+	for i, p := range vals {
+		*p = math.Float64frombits(binary.LittleEndian.Uint64(state[9+8*i:]))
+	}
+	if o.n == 0 {
+		return nil
+	}
+	if m.n == 0 {
+		m.n, m.sum, m.mean, m.m2, m.min, m.max = o.n, o.sum, o.mean, o.m2, o.min, o.max
+		return nil
+	}
+
+	// Combine means and squared differences using the pairwise algorithm of
+	// Chan, Golub and LeVeque.
+	n := m.n + o.n
+	d := o.mean - m.mean
+	m.mean += d * float64(o.n) / float64(n)
+	m.m2 += o.m2 + d*d*float64(m.n)*float64(o.n)/float64(n)
+	m.sum += o.sum
+	m.min = math.Min(m.min, o.min)
+	m.max = math.Max(m.max, o.max)
+	m.n = n
+	return nil
+}
+
+func (m *moments) Result() float64 {
+	switch m.kind {
+	case momentSum:
+		return m.sum
+	case momentCount:
+		return float64(m.n)
+	case momentMean:
+		return m.sum / float64(m.n)
+	case momentVariance:
+		return m.m2 / float64(m.n-1)
+	case momentMin:
+		if m.n == 0 {
+			return math.NaN()
+		}
+		return m.min
+	case momentMax:
+		if m.n == 0 {
+			return math.NaN()
+		}
+		return m.max
+	}
+	return math.NaN()
+}
@@ -0,0 +1,59 @@
+package datatable
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPartialAggregators(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("v", []float64{4, 8, 15, 16, 23, 42, -1})
+
+	testCases := []struct {
+		name    string
+		partial func() PartialAggregator
+		full    Aggregator
+	}{
+		{"sum", func() PartialAggregator { return PartialSum("v") }, Sum("v")},
+		{"count", PartialCount, Count()},
+		{"mean", func() PartialAggregator { return PartialMean("v") }, Mean("v")},
+		{"variance", func() PartialAggregator { return PartialVariance("v") }, Variance("v")},
+		{"min", func() PartialAggregator { return PartialMin("v") }, AggregatorFunc(func(RowGroup) float64 { return -1 })},
+		{"max", func() PartialAggregator { return PartialMax("v") }, Max("v")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := tc.full.Aggregate(dt.Rows())
+
+			// Aggregate each chunk separately then merge the states
+			total := tc.partial()
+			for _, indices := range [][]int{{0, 1}, {2, 3, 4}, {}, {5, 6}} {
+				p := tc.partial()
+				p.Accumulate(&StaticRowGroup{dt: dt, indices: indices})
+				if err := total.Merge(p.State()); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if got := total.Result(); math.Abs(got-want) > 1e-9 {
+				t.Errorf("got merged result %v, wanted %v", got, want)
+			}
+
+			if got := tc.partial().Aggregate(dt.Rows()); math.Abs(got-want) > 1e-9 {
+				t.Errorf("got Aggregate %v, wanted %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPartialMergeErrors(t *testing.T) {
+	if err := PartialSum("v").Merge(PartialMean("v").State()); err == nil {
+		t.Errorf("expected error merging state of a different aggregator")
+	}
+	if err := PartialSum("v").Merge([]byte{1, 2, 3}); err == nil {
+		t.Errorf("expected error merging malformed state")
+	}
+	if got := PartialMin("v").Result(); !math.IsNaN(got) {
+		t.Errorf("got %v for empty minimum, wanted NaN", got)
+	}
+}
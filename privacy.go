@@ -0,0 +1,84 @@
+package datatable
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+// NoiseOptions controls the noise added by LaplaceNoisy and NoisyCount.
+type NoiseOptions struct {
+	// MinGroupSize suppresses the results of groups with fewer rows, which
+	// are returned as NaN, since noise alone may not hide the contribution
+	// of individuals in very small groups. The default suppresses no
+	// groups.
+	MinGroupSize int
+
+	// Rand is the source of the noise. The default draws from crypto/rand,
+	// which should be used for published results. Seeded sources make
+	// results reproducible, which is useful in tests.
+	Rand *rand.Rand
+}
+
+// LaplaceNoisy returns an Aggregator that adds noise drawn from the Laplace
+// distribution to the results of a, so that results published externally give
+// epsilon-differential privacy. sensitivity is the most that adding or
+// removing a single row can change the result of a, such as 1 for a count or
+// the largest possible value for a sum of non-negative values. The noise has
+// scale sensitivity/epsilon, so smaller values of epsilon give more privacy
+// and less accuracy. NaN is returned if epsilon or sensitivity is not
+// positive. Each result consumes privacy budget, so the same group should not
+// be aggregated repeatedly.
+func LaplaceNoisy(a Aggregator, epsilon, sensitivity float64, opts NoiseOptions) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		if !(epsilon > 0) || !(sensitivity > 0) {
+			return math.NaN()
+		}
+		if opts.MinGroupSize > 0 {
+			n := 0
+			for rg.Next() {
+				n++
+			}
+			rg.Reset()
+			if n < opts.MinGroupSize {
+				return math.NaN()
+			}
+		}
+		return a.Aggregate(rg) + laplace(sensitivity/epsilon, opts.Rand)
+	}), callExpr("LaplaceNoisy", a, epsilon, sensitivity))
+}
+
+// NoisyCount returns an Aggregator that counts the rows in a group and adds
+// Laplace noise as LaplaceNoisy does, with a sensitivity of 1.
+func NoisyCount(epsilon float64, opts NoiseOptions) Aggregator {
+	return LaplaceNoisy(Count(), epsilon, 1, opts)
+}
+
+// laplace returns a value drawn from the Laplace distribution with mean zero
+// and the given scale, using r or crypto/rand if r is nil.
+func laplace(scale float64, r *rand.Rand) float64 {
+	for {
+		u := uniform(r) - 0.5
+		if u == -0.5 {
+			continue // the logarithm below would be infinite
+		}
+		if u < 0 {
+			return scale * math.Log(1+2*u)
+		}
+		return -scale * math.Log(1-2*u)
+	}
+}
+
+// uniform returns a value drawn uniformly from [0, 1) using r or crypto/rand
+// if r is nil.
+func uniform(r *rand.Rand) float64 {
+	if r != nil {
+		return r.Float64()
+	}
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		panic("reading random bytes: " + err.Error())
+	}
+	return float64(binary.LittleEndian.Uint64(buf[:])>>11) / (1 << 53)
+}
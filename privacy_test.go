@@ -0,0 +1,48 @@
+package datatable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLaplaceNoisy(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "a", "a", "b"})
+	dt.AddColumn("v", []float64{1, 2, 3, 4})
+	dt.SetKeys("g")
+
+	opts := NoiseOptions{Rand: rand.New(rand.NewSource(1))}
+	const trials = 20000
+	sum, absSum := 0.0, 0.0
+	for i := 0; i < trials; i++ {
+		noise := dt.Reduce(LaplaceNoisy(Sum("v"), 0.5, 2, opts)) - 10
+		sum += noise
+		absSum += math.Abs(noise)
+	}
+	// Laplace noise has mean zero and mean absolute deviation equal to its
+	// scale, here 2/0.5
+	if mean := sum / trials; math.Abs(mean) > 0.1 {
+		t.Errorf("got mean noise %v, wanted about 0", mean)
+	}
+	if mad := absSum / trials; math.Abs(mad-4) > 0.1 {
+		t.Errorf("got mean absolute noise %v, wanted about 4", mad)
+	}
+
+	opts.MinGroupSize = 2
+	dt.Aggregate("count", NoisyCount(1, opts))
+	col := dt.cols[dt.colorder["count"]].f
+	if math.IsNaN(col[0]) || col[0] != col[2] {
+		t.Errorf("got %v for group a, wanted a single noisy count", col[:3])
+	}
+	if !math.IsNaN(col[3]) {
+		t.Errorf("got %v for group b, wanted NaN for suppressed group", col[3])
+	}
+
+	if got := dt.Reduce(NoisyCount(0, NoiseOptions{})); !math.IsNaN(got) {
+		t.Errorf("got %v for zero epsilon, wanted NaN", got)
+	}
+	if got := dt.Reduce(NoisyCount(1, NoiseOptions{})); math.IsNaN(got) || math.IsInf(got, 0) {
+		t.Errorf("got %v with crypto/rand, wanted a finite value", got)
+	}
+}
@@ -0,0 +1,83 @@
+package datatable
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Registry holds a set of named data tables so that they can be referred to
+// by name, and their columns by qualified names of the form "table.column".
+// A Registry is safe for concurrent use but the tables it holds are not.
+type Registry struct {
+	mu     sync.RWMutex
+	tables map[string]*DataTable
+}
+
+// NewRegistry creates a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{tables: map[string]*DataTable{}}
+}
+
+// Register adds dt to the registry under name, replacing any table already
+// registered with that name. Names must be non-empty and may not contain a
+// period since that is used to separate table and column names.
+func (r *Registry) Register(name string, dt *DataTable) error {
+	if name == "" || strings.Contains(name, ".") {
+		return fmt.Errorf("invalid table name: %q", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tables == nil {
+		r.tables = map[string]*DataTable{}
+	}
+	r.tables[name] = dt
+	return nil
+}
+
+// Unregister removes the named table from the registry.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tables, name)
+}
+
+// Table returns the table registered under name or nil and false if there is
+// no such table.
+func (r *Registry) Table(name string) (*DataTable, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dt, exists := r.tables[name]
+	return dt, exists
+}
+
+// Names returns the names of the registered tables in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.tables))
+	for name := range r.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve looks up a qualified column reference of the form "table.column",
+// returning the table and the column name within it. An error is returned if
+// the reference is malformed or names an unknown table or column.
+func (r *Registry) Resolve(ref string) (*DataTable, string, error) {
+	tname, cname, ok := strings.Cut(ref, ".")
+	if !ok || tname == "" || cname == "" {
+		return nil, "", fmt.Errorf("invalid column reference: %q", ref)
+	}
+	dt, exists := r.Table(tname)
+	if !exists {
+		return nil, "", fmt.Errorf("unknown table: %s", tname)
+	}
+	if _, exists := dt.colorder[cname]; !exists {
+		return nil, "", fmt.Errorf("unknown column: %s", ref)
+	}
+	return dt, cname, nil
+}
@@ -0,0 +1,47 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	orders := &DataTable{}
+	orders.AddStringColumn("customer_id", []string{"c1", "c2"})
+	customers := &DataTable{}
+	customers.AddStringColumn("id", []string{"c1"})
+
+	r := NewRegistry()
+	if err := r.Register("orders", orders); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register("customers", customers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register("bad.name", customers); err == nil {
+		t.Errorf("got no error for name containing a period")
+	}
+
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"customers", "orders"}) {
+		t.Errorf("got %v, wanted [customers orders]", names)
+	}
+
+	dt, col, err := r.Resolve("orders.customer_id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dt != orders || col != "customer_id" {
+		t.Errorf("got %p %q, wanted %p %q", dt, col, orders, "customer_id")
+	}
+
+	for _, ref := range []string{"orders", "orders.", "nope.id", "customers.nope"} {
+		if _, _, err := r.Resolve(ref); err == nil {
+			t.Errorf("%q: got no error", ref)
+		}
+	}
+
+	r.Unregister("orders")
+	if _, exists := r.Table("orders"); exists {
+		t.Errorf("table still registered after Unregister")
+	}
+}
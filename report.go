@@ -0,0 +1,113 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TemplateFuncs returns functions for generating reports from tables with
+// text/template or html/template. The result can be passed to the Funcs method
+// of a template from either package. The functions are:
+//
+//	rows TABLE             the rows of the table as RowMaps, in order
+//	col TABLE NAME         the values of the named column, in order
+//	names TABLE            the names of the columns of the table
+//	groups TABLE KEY...    the tables returned by Groups
+//	fmtNum VALUE DECIMALS  the number formatted by FormatNumber
+//
+// For example, with a table passed as the template's data:
+//
+//	{{range groups . "region"}}{{(index (rows .) 0).region}}: {{fmtNum (index (col . "sales") 0) 2}}
+//	{{end}}
+func TemplateFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"rows": func(dt *DataTable) []RowMap {
+			ret := make([]RowMap, dt.Len())
+			for i := range ret {
+				ret[i], _ = dt.RowMap(i)
+			}
+			return ret
+		},
+		"col": func(dt *DataTable, name string) ([]interface{}, error) {
+			c, exists := dt.colorder[name]
+			if !exists {
+				return nil, fmt.Errorf("unknown column: %s", name)
+			}
+			ret := make([]interface{}, dt.Len())
+			for i := range ret {
+				if dt.cols[c].f != nil {
+					ret[i] = dt.cols[c].f[i]
+				} else {
+					ret[i] = dt.cols[c].s[i]
+				}
+			}
+			return ret, nil
+		},
+		"names": func(dt *DataTable) []string {
+			return dt.Names()
+		},
+		"groups": func(dt *DataTable, keys ...string) ([]*DataTable, error) {
+			return dt.Groups(keys...)
+		},
+		"fmtNum": FormatNumber,
+	}
+}
+
+// Groups returns a new table for each group of rows of the table that share
+// the values of the named columns, ordered by those values, such as the
+// sections of a report. Each table has all the columns of the table and no
+// keys set. The table itself is not modified.
+func (dt *DataTable) Groups(keys ...string) ([]*DataTable, error) {
+	grouped := dt.Clone()
+	if err := grouped.SetKeys(keys...); err != nil {
+		return nil, err
+	}
+	var ret []*DataTable
+	grouped.keyGroups(func(start, end int) {
+		g, _ := grouped.SelectIndex(grouped.Names(), fillRange(start, end))
+		ret = append(ret, g)
+	})
+	return ret, nil
+}
+
+// FormatNumber formats v, which must be a float64 or int, with the given
+// number of decimal places and commas separating groups of thousands, such as
+// 1,234.50. NaN values are formatted as an empty string.
+func FormatNumber(v interface{}, decimals int) (string, error) {
+	var f float64
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	default:
+		return "", fmt.Errorf("not a number: %v", v)
+	}
+	if math.IsNaN(f) {
+		return "", nil
+	}
+	if math.IsInf(f, 0) {
+		return FloatFormat{}.Format(f), nil
+	}
+
+	s := FloatFormat{Decimals: max(decimals, 0)}.Format(f)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,93 @@
+package datatable
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateReport(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"south", "north", "south"})
+	dt.AddColumn("sales", []float64{1200, 30.5, 4})
+
+	const report = `{{range groups . "region"}}{{(index (rows .) 0).region}}:{{range col . "sales"}} {{fmtNum . 1}}{{end}}
+{{end}}{{names .}}`
+	tmpl, err := template.New("report").Funcs(TemplateFuncs()).Parse(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, dt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "north: 30.5\nsouth: 1,200.0 4.0\n[region sales]"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	htmpl, err := htmltemplate.New("report").Funcs(TemplateFuncs()).Parse(`{{range rows .}}<td>{{.region}}</td>{{end}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b.Reset()
+	if err := htmpl.Execute(&b, dt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := b.String(); got != "<td>south</td><td>north</td><td>south</td>" {
+		t.Errorf("got %q", got)
+	}
+
+	tmpl = template.Must(template.New("bad").Funcs(TemplateFuncs()).Parse(`{{col . "missing"}}`))
+	if err := tmpl.Execute(&b, dt); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	testCases := []struct {
+		v        interface{}
+		decimals int
+		want     string
+	}{
+		{v: 1234567.891, decimals: 2, want: "1,234,567.89"},
+		{v: -1234.5, decimals: 0, want: "-1,234"},
+		{v: 999.0, decimals: 1, want: "999.0"},
+		{v: 42, decimals: 0, want: "42"},
+		{v: 0.5, decimals: -1, want: "0"},
+	}
+	for _, tc := range testCases {
+		got, err := FormatNumber(tc.v, tc.decimals)
+		if err != nil {
+			t.Errorf("FormatNumber(%v, %d): unexpected error: %v", tc.v, tc.decimals, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("FormatNumber(%v, %d): got %q, wanted %q", tc.v, tc.decimals, got, tc.want)
+		}
+	}
+	if _, err := FormatNumber("x", 0); err == nil {
+		t.Errorf("got no error for string")
+	}
+}
+
+func TestGroups(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"b", "a", "b"})
+	dt.AddColumn("v", []float64{1, 2, 3})
+
+	groups, err := dt.Groups("k")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Len() != 1 || groups[1].Len() != 2 {
+		t.Fatalf("got %d groups, wanted groups of 1 and 2 rows", len(groups))
+	}
+	if rows := groups[1].RawRows(false); !equivalentRows(rows, [][]interface{}{{"b", 1.0}, {"b", 3.0}}) {
+		t.Errorf("got %v", rows)
+	}
+	if _, err := dt.Groups("missing"); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
@@ -0,0 +1,100 @@
+package datatable
+
+import (
+	"fmt"
+)
+
+// A RestrictedTable gives read-only access to some of the columns and rows of
+// a base table, so that the same table can serve several tenants or users
+// that may each see only part of it. Hidden columns behave as if they do not
+// exist: values of them are not found and selecting them fails as for an
+// unknown column. The view reads the base table each time it is used, so it
+// reflects later changes to the base table's data, but the base table must
+// not be modified while rows or values obtained from the view are in use.
+type RestrictedTable struct {
+	base    *DataTable
+	allowed []string
+	filter  Matcher
+}
+
+// RestrictedView returns a view of dt that exposes only the columns named in
+// allowedCols and only the rows matched by rowFilter, or all rows if rowFilter
+// is nil. The filter is applied to the full rows of dt, so it may test columns
+// that the view hides, such as a tenant identifier.
+func RestrictedView(dt *DataTable, allowedCols []string, rowFilter Matcher) (*RestrictedTable, error) {
+	seen := make(map[string]bool, len(allowedCols))
+	for _, name := range allowedCols {
+		if _, exists := dt.colorder[name]; !exists {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column: %s", name)
+		}
+		seen[name] = true
+	}
+	return &RestrictedTable{base: dt, allowed: append([]string{}, allowedCols...), filter: rowFilter}, nil
+}
+
+// visible returns a table sharing the storage of the allowed columns of the
+// base table that still exist, and the positions of the visible rows.
+func (r *RestrictedTable) visible() (*DataTable, []int) {
+	shadow := &DataTable{colorder: map[string]int{}}
+	for _, name := range r.allowed {
+		if c, exists := r.base.colorder[name]; exists {
+			shadow.colorder[name] = len(shadow.cols)
+			shadow.colnames = append(shadow.colnames, name)
+			shadow.cols = append(shadow.cols, r.base.cols[c])
+		}
+	}
+	if r.filter == nil {
+		return shadow, fillSeq(r.base.Len())
+	}
+	return shadow, r.base.Matches(r.filter)
+}
+
+// Names returns the names of the columns exposed by the view, in the order
+// they were allowed.
+func (r *RestrictedTable) Names() []string {
+	shadow, _ := r.visible()
+	return shadow.Names()
+}
+
+// Len returns the number of rows exposed by the view.
+func (r *RestrictedTable) Len() int {
+	_, rows := r.visible()
+	return len(rows)
+}
+
+// Rows returns a RowGroup over the rows exposed by the view, in the base
+// table's order. Only the allowed columns can be read from the group, its row
+// references and the table returned by its Materialize method. RowIndex
+// reports positions in the base table.
+func (r *RestrictedTable) Rows() RowGroup {
+	shadow, rows := r.visible()
+	return &StaticRowGroup{dt: shadow, indices: rows}
+}
+
+// RowRef returns a reference to the row at position n of the rows exposed by
+// the view, through which only the allowed columns can be read, or false if n
+// is out of range.
+func (r *RestrictedTable) RowRef(n int) (RowRef, bool) {
+	shadow, rows := r.visible()
+	if n < 0 || n >= len(rows) {
+		return RowRef{}, false
+	}
+	return RowRef{dt: shadow, index: rows[n]}, true
+}
+
+// Reduce returns the result of the aggregator a over the rows exposed by the
+// view. The aggregator can only read the allowed columns.
+func (r *RestrictedTable) Reduce(a Aggregator) float64 {
+	return a.Aggregate(r.Rows())
+}
+
+// Select returns a new table holding copies of the named columns for the
+// rows exposed by the view. An error is returned if any column is hidden or
+// does not exist.
+func (r *RestrictedTable) Select(names []string) (*DataTable, error) {
+	shadow, rows := r.visible()
+	return shadow.SelectIndex(names, rows)
+}
@@ -0,0 +1,77 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRestrictedView(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("tenant", []string{"a", "b", "a", "b"})
+	dt.AddStringColumn("name", []string{"w", "x", "y", "z"})
+	dt.AddColumn("amount", []float64{1, 2, 3, 4})
+	dt.AddColumn("secret", []float64{10, 20, 30, 40})
+
+	rv, err := RestrictedView(dt, []string{"name", "amount"}, IsEqualString("tenant", "a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rv.Names(); !reflect.DeepEqual(got, []string{"name", "amount"}) {
+		t.Errorf("got names %v", got)
+	}
+	if rv.Len() != 2 {
+		t.Errorf("got %d rows, wanted 2", rv.Len())
+	}
+	if got := rv.Reduce(Sum("amount")); got != 4 {
+		t.Errorf("got sum %v, wanted 4", got)
+	}
+	if got := rv.Reduce(Sum("secret")); got != 0 {
+		t.Errorf("got sum of hidden column %v, wanted 0", got)
+	}
+
+	rg := rv.Rows()
+	for rg.Next() {
+		if _, ok := rg.FloatValue("secret"); ok {
+			t.Errorf("read hidden column from row group")
+		}
+		row, _ := rowRefOf(rg)
+		if _, ok := row.StringValue("tenant"); ok {
+			t.Errorf("read hidden column from row reference")
+		}
+	}
+	if names := rg.Materialize().Names(); !reflect.DeepEqual(names, []string{"name", "amount"}) {
+		t.Errorf("got materialized columns %v", names)
+	}
+
+	row, ok := rv.RowRef(1)
+	if !ok {
+		t.Fatalf("got no row 1")
+	}
+	if v, _ := row.StringValue("name"); v != "y" {
+		t.Errorf("got name %q, wanted y", v)
+	}
+	if _, ok := rv.RowRef(2); ok {
+		t.Errorf("got filtered row 2")
+	}
+
+	sel, err := rv.Select([]string{"amount"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := sel.RawRows(false); !equivalentRows(rows, [][]interface{}{{1.0}, {3.0}}) {
+		t.Errorf("got %v", rows)
+	}
+	if _, err := rv.Select([]string{"secret"}); err == nil {
+		t.Errorf("got no error selecting hidden column")
+	}
+
+	// the view reflects later changes to the base table
+	dt.AppendRow([]interface{}{"a", "v", 5.0, 50.0})
+	if rv.Len() != 3 {
+		t.Errorf("got %d rows after append, wanted 3", rv.Len())
+	}
+
+	if _, err := RestrictedView(dt, []string{"missing"}, nil); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
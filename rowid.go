@@ -0,0 +1,53 @@
+package datatable
+
+// EnableRowIDs assigns a hidden, stable identifier to every row in the table.
+// Identifiers are assigned sequentially as rows are appended and move with
+// their rows when the table is sorted. Tables created by Select, SelectWhere,
+// SelectIndex and Clone carry over the identifiers of the copied rows so results
+// can be mapped back to the rows of the source table. Calling EnableRowIDs on a
+// table that already has identifiers has no effect.
+func (dt *DataTable) EnableRowIDs() {
+	if dt.ids != nil {
+		return
+	}
+	dt.ids = make([]int64, 0, dt.Len())
+	dt.nextID = 0
+	dt.syncIDs()
+}
+
+// HasRowIDs reports whether row identifiers have been enabled for the table.
+func (dt *DataTable) HasRowIDs() bool {
+	return dt.ids != nil
+}
+
+// IndexOfID returns the current index of the row with the identifier id or
+// -1 and false if no such row exists or row identifiers are not enabled.
+func (dt *DataTable) IndexOfID(id int64) (int, bool) {
+	for i, v := range dt.ids {
+		if v == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// syncIDs assigns identifiers to any rows that have been appended since
+// identifiers were last assigned.
+func (dt *DataTable) syncIDs() {
+	if dt.ids == nil {
+		return
+	}
+	for len(dt.ids) < dt.Len() {
+		dt.ids = append(dt.ids, dt.nextID)
+		dt.nextID++
+	}
+}
+
+// ID returns the stable identifier of the row or -1 if row identifiers
+// have not been enabled for the table.
+func (r *RowRef) ID() int64 {
+	if r.dt.ids == nil || r.index < 0 || r.index >= len(r.dt.ids) {
+		return -1
+	}
+	return r.dt.ids[r.index]
+}
@@ -0,0 +1,74 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func rowIDs(dt *DataTable) []int64 {
+	ids := make([]int64, dt.Len())
+	for i := range ids {
+		rr, _ := dt.RowRef(i)
+		ids[i] = rr.ID()
+	}
+	return ids
+}
+
+func TestRowIDs(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{5, 4, 3})
+	dt.AddStringColumn("c1", []string{"a", "b", "c"})
+
+	if rr, _ := dt.RowRef(0); rr.ID() != -1 {
+		t.Errorf("got %d, wanted -1 before row ids are enabled", rr.ID())
+	}
+
+	dt.EnableRowIDs()
+	dt.AppendRow([]interface{}{2.0, "d"})
+	dt.ParseRow("1", "e")
+
+	if got, expected := rowIDs(dt), []int64{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, wanted %v", got, expected)
+	}
+
+	// ids follow their rows when sorted
+	dt.SetKeys("c0")
+	if got, expected := rowIDs(dt), []int64{4, 3, 2, 1, 0}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("after sort: got %v, wanted %v", got, expected)
+	}
+
+	dt.RemoveRows(IsEqualString("c1", "c"))
+	if got, expected := rowIDs(dt), []int64{4, 3, 1, 0}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("after remove: got %v, wanted %v", got, expected)
+	}
+
+	dt2 := &DataTable{}
+	dt2.AddColumn("c0", []float64{0})
+	dt2.AddStringColumn("c1", []string{"f"})
+	dt.Append(dt2)
+	if got, expected := rowIDs(dt), []int64{5, 4, 3, 1, 0}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("after append: got %v, wanted %v", got, expected)
+	}
+
+	// selections carry ids that map back to the source table
+	sel, _ := dt.SelectWhere([]string{"c1"}, GreaterThan("c0", 3))
+	if got, expected := rowIDs(sel), []int64{1, 0}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("selection: got %v, wanted %v", got, expected)
+	}
+	idx, ok := dt.IndexOfID(1)
+	if !ok || idx != 3 {
+		t.Errorf("IndexOfID: got %d,%v, wanted 3,true", idx, ok)
+	}
+	if _, ok := dt.IndexOfID(2); ok {
+		t.Errorf("IndexOfID: found removed row")
+	}
+}
+
+func TestRowIDsEmptyTable(t *testing.T) {
+	dt := &DataTable{}
+	dt.EnableRowIDs()
+	dt.AddColumn("c0", []float64{1, 2})
+	if got, expected := rowIDs(dt), []int64{0, 1}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, wanted %v", got, expected)
+	}
+}
@@ -0,0 +1,164 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+)
+
+// A SchemaPolicy determines how AppendAll resolves a column that is numeric in
+// some input tables and text in others.
+type SchemaPolicy int
+
+const (
+	// SchemaError causes AppendAll to fail with ErrMismatchedColumnTypes.
+	SchemaError SchemaPolicy = iota
+
+	// SchemaPromote converts the numeric values of the column to text.
+	// Values of integer, decimal and time columns are written exactly.
+	SchemaPromote
+
+	// SchemaDrop omits the column from the result.
+	SchemaDrop
+)
+
+// A ColumnAction describes what AppendAll did with a column.
+type ColumnAction int
+
+const (
+	ColumnKept ColumnAction = iota
+	ColumnPromoted
+	ColumnDropped
+)
+
+func (a ColumnAction) String() string {
+	switch a {
+	case ColumnKept:
+		return "kept"
+	case ColumnPromoted:
+		return "promoted"
+	case ColumnDropped:
+		return "dropped"
+	}
+	return fmt.Sprintf("ColumnAction(%d)", int(a))
+}
+
+// A ColumnResolution reports how AppendAll resolved a single column.
+type ColumnResolution struct {
+	Name    string
+	Action  ColumnAction
+	Present int // number of input tables containing the column
+	Padded  int // number of input tables that lacked the column and were padded
+}
+
+// AppendAll creates a new data table containing the rows of each of dts in
+// turn. The columns of the result are the union of the columns of the inputs
+// in the order they are first seen. Columns missing from an input are padded
+// with null values. Numeric columns keep their type, such as integer, decimal
+// or time, if it is the same in every input that has them, and otherwise hold
+// plain numbers. Columns that are numeric in some inputs and
+// text in others are resolved according to policy. The returned resolutions
+// describe what was done with each column, in the same order as the columns
+// were seen. The returned table has no keys set.
+func AppendAll(policy SchemaPolicy, dts ...*DataTable) (*DataTable, []ColumnResolution, error) {
+	var res []ColumnResolution
+	pos := map[string]int{}
+	numeric := map[string]bool{}
+	text := map[string]bool{}
+	kinds := map[string]colvals{} // the kind shared by a column's numeric inputs
+	mixed := map[string]bool{}    // whether its numeric inputs differ in kind
+
+	for _, dt := range dts {
+		for c, name := range dt.colnames {
+			i, seen := pos[name]
+			if !seen {
+				i = len(res)
+				pos[name] = i
+				res = append(res, ColumnResolution{Name: name})
+			}
+			res[i].Present++
+			if cv := dt.cols[c]; cv.f != nil {
+				if k, exists := kinds[name]; !exists {
+					kinds[name] = colvals{d: cv.d, scale: cv.scale, integer: cv.integer, layouts: cv.layouts}
+				} else if !sameKind(k, cv) {
+					mixed[name] = true
+				}
+				numeric[name] = true
+			} else {
+				text[name] = true
+			}
+		}
+	}
+
+	ret := &DataTable{}
+	for i := range res {
+		name := res[i].Name
+		res[i].Padded = len(dts) - res[i].Present
+		if numeric[name] && text[name] {
+			switch policy {
+			case SchemaPromote:
+				res[i].Action = ColumnPromoted
+			case SchemaDrop:
+				res[i].Action = ColumnDropped
+				continue
+			default:
+				return nil, nil, fmt.Errorf("%w: column %s", ErrMismatchedColumnTypes, name)
+			}
+		}
+
+		if !text[name] {
+			cv := colvals{f: []float64{}}
+			if k := kinds[name]; k.d != nil && !mixed[name] {
+				cv.d, cv.scale, cv.integer, cv.layouts = []int64{}, k.scale, k.integer, k.layouts
+			}
+			for _, dt := range dts {
+				start := len(cv.f)
+				if c, exists := dt.colorder[name]; exists {
+					cv.appendFloats(dt.cols[c])
+				} else {
+					for n := 0; n < dt.Len(); n++ {
+						cv.appendFloat(math.NaN())
+					}
+					cv.setNullRange(start, len(cv.f))
+				}
+			}
+			ret.addColumn(name, cv)
+			continue
+		}
+
+		cv := colvals{s: []string{}}
+		for _, dt := range dts {
+			start := len(cv.s)
+			c, exists := dt.colorder[name]
+			switch {
+			case !exists:
+				cv.s = append(cv.s, make([]string, dt.Len())...)
+				cv.setNullRange(start, len(cv.s))
+			case dt.isFloatCol(c):
+				for n, v := range dt.cols[c].f {
+					if dt.cols[c].isNull(n) {
+						cv.s = append(cv.s, "")
+						continue
+					}
+					if dt.cols[c].d != nil {
+						cv.s = append(cv.s, dt.formatFloat(c, n, v))
+					} else {
+						cv.s = append(cv.s, fmt.Sprintf("%v", v))
+					}
+				}
+				cv.appendNulls(dt.cols[c], start)
+			default:
+				cv.appendStrings(dt.cols[c])
+			}
+		}
+		ret.addColumn(name, cv)
+	}
+
+	return ret, res, nil
+}
+
+// sameKind reports whether two numeric columns are of the same type and
+// scale, so that their exact values can be combined in one column.
+func sameKind(a, b colvals) bool {
+	return (a.d != nil) == (b.d != nil) && a.scale == b.scale && a.integer == b.integer &&
+		(a.layouts != nil) == (b.layouts != nil)
+}
@@ -0,0 +1,110 @@
+package datatable
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAppendAll(t *testing.T) {
+	jan := &DataTable{}
+	jan.AddStringColumn("store", []string{"a", "b"})
+	jan.AddColumn("sales", []float64{1, 2})
+	jan.AddColumn("code", []float64{10, 20})
+
+	feb := &DataTable{}
+	feb.AddStringColumn("store", []string{"c"})
+	feb.AddColumn("sales", []float64{3})
+	feb.AddStringColumn("code", []string{"x30"})
+	feb.AddColumn("returns", []float64{1})
+
+	if _, _, err := AppendAll(SchemaError, jan, feb); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+
+	dt, res, err := AppendAll(SchemaPromote, jan, feb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedRows := [][]interface{}{
+		{"store", "sales", "code", "returns"},
+		{"a", 1.0, "10", math.NaN()},
+		{"b", 2.0, "20", math.NaN()},
+		{"c", 3.0, "x30", 1.0},
+	}
+	if rows := dt.RawRows(true); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+	expectedRes := []ColumnResolution{
+		{Name: "store", Action: ColumnKept, Present: 2},
+		{Name: "sales", Action: ColumnKept, Present: 2},
+		{Name: "code", Action: ColumnPromoted, Present: 2},
+		{Name: "returns", Action: ColumnKept, Present: 1, Padded: 1},
+	}
+	if !reflect.DeepEqual(res, expectedRes) {
+		t.Errorf("got %+v, wanted %+v", res, expectedRes)
+	}
+
+	dt, res, err = AppendAll(SchemaDrop, jan, feb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := dt.Names(); !reflect.DeepEqual(names, []string{"store", "sales", "returns"}) {
+		t.Errorf("got %v, wanted [store sales returns]", names)
+	}
+	if res[2].Action != ColumnDropped {
+		t.Errorf("got %v, wanted %v", res[2].Action, ColumnDropped)
+	}
+	if dt.Len() != 3 {
+		t.Errorf("got %d rows, wanted 3", dt.Len())
+	}
+}
+
+func TestAppendAllColumnTypes(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 1, time.UTC) }
+	a := &DataTable{}
+	a.AddIntColumn("id", []int64{1<<60 + 1})
+	a.AddDecimalColumn("amount", []int64{105}, 2)
+	a.AddTimeColumn("at", []time.Time{day(1)})
+	a.AddIntColumn("n", []int64{1})
+	a.AddIntColumn("code", []int64{1<<60 + 3})
+	b := &DataTable{}
+	b.AddIntColumn("id", []int64{1<<60 + 2})
+	b.AddTimeColumn("at", []time.Time{day(2)})
+	b.AddColumn("n", []float64{2.5})
+	b.AddStringColumn("code", []string{"x"})
+
+	dt, _, err := AppendAll(SchemaPromote, a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids, err := dt.IntValues("id"); err != nil || !reflect.DeepEqual(ids, []int64{1<<60 + 1, 1<<60 + 2}) {
+		t.Errorf("got ids %v, %v, wanted exact integers", ids, err)
+	}
+	if units, scale, err := dt.DecimalValues("amount"); err != nil || scale != 2 || !reflect.DeepEqual(units, []int64{105, 0}) {
+		t.Errorf("got amounts %v scale %d, %v, wanted [105 0] scale 2", units, scale, err)
+	}
+	if got := dt.Matches(IsNull("amount")); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("got null amounts %v, wanted [1]", got)
+	}
+	if typ, _ := dt.ColumnType("at"); typ != ColTime {
+		t.Errorf("got column type %v, wanted %v", typ, ColTime)
+	}
+	for row, want := range []time.Time{day(1), day(2)} {
+		rr, _ := dt.RowRef(row)
+		if got, ok := rr.TimeValue("at"); !ok || !got.Equal(want) {
+			t.Errorf("row %d: got %v, wanted %v", row, got, want)
+		}
+	}
+
+	// integer and plain numeric inputs give a plain numeric column
+	if typ, _ := dt.ColumnType("n"); typ != ColNumeric {
+		t.Errorf("got column type %v, wanted %v", typ, ColNumeric)
+	}
+	// promoted integers keep their exact values
+	if got := dt.cols[dt.colorder["code"]].s; !reflect.DeepEqual(got, []string{"1152921504606846979", "x"}) {
+		t.Errorf("got codes %v, wanted exact text", got)
+	}
+}
@@ -0,0 +1,198 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PercentileRank appends a new numeric column to the table holding the
+// percentile rank of each value of the numeric column srcCol within the group
+// of rows that share the same key column values, or within the whole table if
+// no keys are set. The rank of a value is its position, counting from 1, in
+// the group's values sorted in ascending order, divided by the number of
+// values. Tied values share the mean of their positions, so a value tied with
+// others ranks below the fraction of values less than or equal to it. Ranks
+// lie in the range (0, 1]. NaN values are excluded from the ranking and
+// assigned NaN.
+func (dt *DataTable) PercentileRank(colName, srcCol string) error {
+	return dt.transformGroups(colName, srcCol, func(in, out []float64) {
+		order := make([]int, 0, len(in))
+		for i, v := range in {
+			if math.IsNaN(v) {
+				out[i] = math.NaN()
+				continue
+			}
+			order = append(order, i)
+		}
+		sort.SliceStable(order, func(a, b int) bool { return in[order[a]] < in[order[b]] })
+
+		n := float64(len(order))
+		for start := 0; start < len(order); {
+			end := start + 1
+			for end < len(order) && in[order[end]] == in[order[start]] {
+				end++
+			}
+			// ranks are 1 based, tied values share the mean of their ranks
+			rank := float64(start+1+end) / 2
+			for _, i := range order[start:end] {
+				out[i] = rank / n
+			}
+			start = end
+		}
+	})
+}
+
+// ECDF returns the empirical cumulative distribution function of the values
+// of the named numeric column, ignoring NaN values. The returned function
+// reports the fraction of values that are less than or equal to its argument.
+func (dt *DataTable) ECDF(name string) (func(x float64) float64, error) {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown column: %s", name)
+	}
+	if !dt.isFloatCol(c) {
+		return nil, ErrMismatchedColumnTypes
+	}
+
+	sorted := make([]float64, 0, dt.Len())
+	for _, v := range dt.cols[c].f {
+		if !math.IsNaN(v) {
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Float64s(sorted)
+
+	return func(x float64) float64 {
+		if len(sorted) == 0 || math.IsNaN(x) {
+			return math.NaN()
+		}
+		n := sort.Search(len(sorted), func(i int) bool { return sorted[i] > x })
+		return float64(n) / float64(len(sorted))
+	}, nil
+}
+
+// EWMA appends a new numeric column to the table holding the exponentially
+// weighted moving average of the numeric column srcCol, computed separately
+// for each group of rows that share the same key column values in the table's
+// current sort order, or over the whole table if no keys are set. Each average
+// is alpha times the current value plus 1-alpha times the previous average,
+// starting with the group's first value. alpha must be in the range (0, 1].
+// NaN values do not change the average and are assigned the previous average.
+func (dt *DataTable) EWMA(colName, srcCol string, alpha float64) error {
+	if !(alpha > 0 && alpha <= 1) {
+		return fmt.Errorf("alpha out of range: %v", alpha)
+	}
+	return dt.transformGroups(colName, srcCol, func(in, out []float64) {
+		avg := math.NaN()
+		for i, v := range in {
+			switch {
+			case math.IsNaN(v):
+			case math.IsNaN(avg):
+				avg = v
+			default:
+				avg = alpha*v + (1-alpha)*avg
+			}
+			out[i] = avg
+		}
+	})
+}
+
+// Shift appends a new numeric column to the table holding the value of the
+// numeric column srcCol from n rows earlier within the same group of rows that
+// share the key column values, or within the whole table if no keys are set.
+// A negative n takes values from later rows. Rows with no such earlier or
+// later row in their group are assigned NaN.
+func (dt *DataTable) Shift(colName, srcCol string, n int) error {
+	return dt.transformGroups(colName, srcCol, func(in, out []float64) {
+		for i := range out {
+			if j := i - n; j >= 0 && j < len(in) {
+				out[i] = in[j]
+			}
+		}
+	})
+}
+
+// Diff appends a new numeric column to the table holding the difference
+// between each value of the numeric column srcCol and the value lag rows
+// earlier within the same group of rows that share the key column values, or
+// within the whole table if no keys are set. Rows with no earlier row at that
+// distance in their group are assigned NaN.
+func (dt *DataTable) Diff(colName, srcCol string, lag int) error {
+	return dt.transformGroups(colName, srcCol, func(in, out []float64) {
+		for i := range out {
+			if j := i - lag; j >= 0 && j < len(in) {
+				out[i] = in[i] - in[j]
+			}
+		}
+	})
+}
+
+// GroupCounter appends a new numeric column to the table holding the 1-based
+// position of each row within the group of rows that share the same key
+// column values, or within the whole table if no keys are set.
+func (dt *DataTable) GroupCounter(colName string) {
+	col := make([]float64, dt.Len())
+	dt.keyGroups(func(start, end int) {
+		for i := start; i < end; i++ {
+			col[i] = float64(i - start + 1)
+		}
+	})
+	dt.AddColumn(colName, col)
+}
+
+// GroupID appends a new numeric column to the table holding a 1-based
+// identifier for the group of rows that share the same key column values.
+// Groups are numbered in the table's current sort order. All rows are
+// assigned 1 if no keys are set.
+func (dt *DataTable) GroupID(colName string) {
+	col := make([]float64, dt.Len())
+	id := 0.0
+	dt.keyGroups(func(start, end int) {
+		id++
+		for i := start; i < end; i++ {
+			col[i] = id
+		}
+	})
+	dt.AddColumn(colName, col)
+}
+
+// transformGroups appends a new numeric column to the table whose values are
+// computed by calling fn for each group of rows that share the same key
+// column values, or once for the whole table if no keys are set. The values
+// of srcCol for the group's rows, in the table's current sort order, are
+// passed in in and fn writes the corresponding new values to out.
+func (dt *DataTable) transformGroups(colName, srcCol string, fn func(in, out []float64)) error {
+	c, exists := dt.colorder[srcCol]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", srcCol)
+	}
+	if !dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+
+	src := dt.cols[c].f
+	col := fillNaN(dt.Len())
+	var in []float64
+	dt.keyGroups(func(start, end int) {
+		in = append(in[:0], src[start:end]...)
+		fn(in, col[start:end])
+	})
+	return dt.AddColumn(colName, col)
+}
+
+// keyGroups calls fn with the bounds of each run of rows that share the same
+// key column values, or once with the bounds of the whole table if no keys
+// are set. Since the table is sorted by its keys each group is contiguous.
+func (dt *DataTable) keyGroups(fn func(start, end int)) {
+	if dt.Len() == 0 || dt.N() == 0 {
+		return
+	}
+	if len(dt.keys) == 0 {
+		fn(0, dt.Len())
+		return
+	}
+	dt.eachGroup(fillSeq(dt.Len()), func(group []int) {
+		fn(group[0], group[0]+len(group))
+	})
+}
@@ -0,0 +1,134 @@
+package datatable
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPercentileRank(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "a", "a", "a", "b", "b"})
+	dt.AddColumn("v", []float64{3, 1, 3, math.NaN(), 5, 2})
+
+	if err := dt.PercentileRank("whole", "v"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dt.SetKeys("g")
+	if err := dt.PercentileRank("group", "v"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]interface{}{
+		{"a", 3.0, 0.7, 2.5 / 3},
+		{"a", 1.0, 0.2, 1.0 / 3},
+		{"a", 3.0, 0.7, 2.5 / 3},
+		{"a", math.NaN(), math.NaN(), math.NaN()},
+		{"b", 5.0, 1.0, 1.0},
+		{"b", 2.0, 0.4, 0.5},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	if err := dt.PercentileRank("bad", "g"); err == nil {
+		t.Errorf("got no error for string column")
+	}
+}
+
+func TestECDF(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("v", []float64{4, 1, 2, 2, math.NaN()})
+
+	f, err := dt.ECDF("v")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testCases := []struct {
+		x        float64
+		expected float64
+	}{
+		{0, 0},
+		{1, 0.25},
+		{2, 0.75},
+		{3, 0.75},
+		{4, 1},
+	}
+	for _, tc := range testCases {
+		if got := f(tc.x); got != tc.expected {
+			t.Errorf("ECDF(%v): got %v, wanted %v", tc.x, got, tc.expected)
+		}
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "b", "a", "a", "b"})
+	dt.AddColumn("v", []float64{10, 1, 20, math.NaN(), 3})
+	dt.SetKeys("g")
+
+	if err := dt.EWMA("ewma", "v", 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]interface{}{
+		{"a", 10.0, 10.0},
+		{"a", 20.0, 15.0},
+		{"a", math.NaN(), 15.0},
+		{"b", 1.0, 1.0},
+		{"b", 3.0, 2.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	for _, alpha := range []float64{0, 1.5, math.NaN()} {
+		if err := dt.EWMA("bad", "v", alpha); err == nil {
+			t.Errorf("alpha %v: got no error", alpha)
+		}
+	}
+}
+
+func TestShiftDiff(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "a", "a", "b", "b"})
+	dt.AddColumn("v", []float64{1, 4, 9, 10, 15})
+	dt.SetKeys("g")
+
+	dt.Shift("prev", "v", 1)
+	dt.Shift("next", "v", -1)
+	dt.Diff("diff", "v", 1)
+	if err := dt.Diff("diff2", "v", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nan := math.NaN()
+	expected := [][]interface{}{
+		{"a", 1.0, nan, 4.0, nan, nan},
+		{"a", 4.0, 1.0, 9.0, 3.0, nan},
+		{"a", 9.0, 4.0, nan, 5.0, 8.0},
+		{"b", 10.0, nan, 15.0, nan, nan},
+		{"b", 15.0, 10.0, nan, 5.0, nan},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+}
+
+func TestGroupCounterAndID(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"b", "a", "b", "a", "a"})
+	dt.SetKeys("g")
+
+	dt.GroupCounter("n")
+	dt.GroupID("grp")
+
+	expected := [][]interface{}{
+		{"a", 1.0, 1.0},
+		{"a", 2.0, 1.0},
+		{"a", 3.0, 1.0},
+		{"b", 1.0, 2.0},
+		{"b", 2.0, 2.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+}
@@ -0,0 +1,94 @@
+package datatable
+
+import (
+	"math"
+)
+
+// SumSkipNA returns an Aggregator that sums a numeric column in a group of
+// rows, ignoring NaN and null values rather than returning NaN. NaN is
+// returned only if the group has no other values.
+func SumSkipNA(name string) Aggregator {
+	return skipNA(name, Sum(name), callExpr("SumSkipNA", name))
+}
+
+// MeanSkipNA returns an Aggregator that finds the mean value of a numeric
+// column in a group of rows, ignoring NaN and null values. NaN is returned
+// only if the group has no other values.
+func MeanSkipNA(name string) Aggregator {
+	return skipNA(name, Mean(name), callExpr("MeanSkipNA", name))
+}
+
+// VarianceSkipNA returns an Aggregator that finds the variance of a numeric
+// column in a group of rows, ignoring NaN and null values. NaN is returned if
+// the group has fewer than two other values.
+func VarianceSkipNA(name string) Aggregator {
+	return skipNA(name, Variance(name), callExpr("VarianceSkipNA", name))
+}
+
+// MaxSkipNA returns an Aggregator that finds the maximum value of a numeric
+// column in a group of rows, ignoring NaN and null values. NaN is returned
+// only if the group has no other values.
+func MaxSkipNA(name string) Aggregator {
+	return skipNA(name, extreme(name, math.Max), callExpr("MaxSkipNA", name))
+}
+
+// MinSkipNA returns an Aggregator that finds the minimum value of a numeric
+// column in a group of rows, ignoring NaN and null values. NaN is returned
+// only if the group has no other values.
+func MinSkipNA(name string) Aggregator {
+	return skipNA(name, extreme(name, math.Min), callExpr("MinSkipNA", name))
+}
+
+// CountSkipNA returns an Aggregator that counts the rows in a group whose
+// value of a numeric column is not NaN or null.
+func CountSkipNA(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		count := 0
+		for rg.Next() {
+			if v, ok := rg.FloatValue(name); ok && !math.IsNaN(v) && !isNullIn(rg, name) {
+				count++
+			}
+		}
+		return float64(count)
+	}), callExpr("CountSkipNA", name), name)
+}
+
+// skipNA returns an Aggregator that applies a to the rows of a group whose
+// value of the named numeric column is not NaN or null, or returns NaN if
+// there are no such rows.
+func skipNA(name string, a Aggregator, expr string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		valid := &StaticRowGroup{}
+		for rg.Next() {
+			if v, ok := rg.FloatValue(name); ok && !math.IsNaN(v) && !isNullIn(rg, name) {
+				row, ok := rowRefOf(rg)
+				if !ok {
+					return math.NaN()
+				}
+				valid.dt = row.dt
+				valid.indices = append(valid.indices, row.index)
+			}
+		}
+		if len(valid.indices) == 0 {
+			return math.NaN()
+		}
+		return a.Aggregate(valid)
+	}), expr, name)
+}
+
+// extreme returns an Aggregator that combines the values of the named numeric
+// column in a group of rows with better, such as math.Max.
+func extreme(name string, better func(a, b float64) float64) Aggregator {
+	return AggregatorFunc(func(rg RowGroup) float64 {
+		r := math.NaN()
+		for rg.Next() {
+			v, _ := rg.FloatValue(name)
+			if math.IsNaN(r) {
+				r = v
+			} else {
+				r = better(r, v)
+			}
+		}
+		return r
+	})
+}
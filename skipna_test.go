@@ -0,0 +1,38 @@
+package datatable
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSkipNAAggregators(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "a", "a", "a", "b", "b"})
+	dt.AddColumn("v", []float64{-4, math.NaN(), -2, 100, math.NaN(), math.NaN()})
+	dt.SetNull("v", 3)
+	dt.SetKeys("g")
+
+	testCases := []struct {
+		name string
+		agg  Aggregator
+		a, b float64
+	}{
+		{"SumSkipNA", SumSkipNA("v"), -6, math.NaN()},
+		{"MeanSkipNA", MeanSkipNA("v"), -3, math.NaN()},
+		{"VarianceSkipNA", VarianceSkipNA("v"), 2, math.NaN()},
+		{"MaxSkipNA", MaxSkipNA("v"), -2, math.NaN()},
+		{"MinSkipNA", MinSkipNA("v"), -4, math.NaN()},
+		{"CountSkipNA", CountSkipNA("v"), 2, 0},
+	}
+	for _, tc := range testCases {
+		dt.Aggregate(tc.name, tc.agg)
+		col := dt.cols[dt.colorder[tc.name]].f
+		if !equivalentFloats(col[0], tc.a) || !equivalentFloats(col[4], tc.b) {
+			t.Errorf("%s: got %v and %v, wanted %v and %v", tc.name, col[0], col[4], tc.a, tc.b)
+		}
+	}
+
+	if got := dt.Reduce(Sum("v")); !math.IsNaN(got) {
+		t.Errorf("Sum: got %v, wanted NaN to propagate", got)
+	}
+}
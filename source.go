@@ -0,0 +1,135 @@
+package datatable
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// A SourceBackend opens the objects named by URIs with a particular scheme,
+// such as s3 or gs, for reading and writing. Backends are registered with
+// RegisterBackend.
+type SourceBackend interface {
+	// Open opens the object named by uri for reading.
+	Open(uri *url.URL) (io.ReadCloser, error)
+
+	// Create opens the object named by uri for writing, replacing any
+	// existing object. The object is complete once the writer is closed
+	// without error.
+	Create(uri *url.URL) (io.WriteCloser, error)
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]SourceBackend{
+		"file":  fileBackend{},
+		"http":  httpBackend{},
+		"https": httpBackend{},
+	}
+)
+
+// RegisterBackend makes b the backend for URIs with the given scheme,
+// replacing any backend already registered for it. Backends for local files
+// and for reading over http and https are registered by default, and
+// object stores such as S3 and Google Cloud Storage may be supported by
+// registering backends for s3 and gs that wrap their client libraries.
+func RegisterBackend(scheme string, b SourceBackend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[scheme] = b
+}
+
+// backendFor parses uri and finds the backend registered for its scheme.
+// URIs without a scheme are local file paths.
+func backendFor(uri string) (SourceBackend, *url.URL, error) {
+	u, err := url.Parse(uri)
+	if err != nil || len(u.Scheme) <= 1 {
+		// a plain path, which may start with a drive letter
+		return fileBackend{}, &url.URL{Scheme: "file", Path: uri}, nil
+	}
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	b, exists := backends[u.Scheme]
+	if !exists {
+		return nil, nil, fmt.Errorf("no backend registered for scheme: %s", u.Scheme)
+	}
+	return b, u, nil
+}
+
+// OpenSource opens the object named by uri for reading using the backend
+// registered for its scheme. A uri without a scheme is a local file path.
+func OpenSource(uri string) (io.ReadCloser, error) {
+	b, u, err := backendFor(uri)
+	if err != nil {
+		return nil, err
+	}
+	return b.Open(u)
+}
+
+// CreateSource opens the object named by uri for writing using the backend
+// registered for its scheme. A uri without a scheme is a local file path.
+func CreateSource(uri string) (io.WriteCloser, error) {
+	b, u, err := backendFor(uri)
+	if err != nil {
+		return nil, err
+	}
+	return b.Create(u)
+}
+
+// ReadCSVSource reads a data table from the CSV object named by uri, which
+// is opened with OpenSource.
+func ReadCSVSource(uri string, opts CSVOptions) (*DataTable, error) {
+	r, err := OpenSource(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadCSV(r, opts)
+}
+
+// WriteCSVSource writes the table as CSV to the object named by uri, which is
+// opened with CreateSource.
+func (dt *DataTable) WriteCSVSource(uri string) error {
+	w, err := CreateSource(uri)
+	if err != nil {
+		return err
+	}
+	if err := dt.CSV(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// fileBackend reads and writes local files.
+type fileBackend struct{}
+
+func (fileBackend) Open(uri *url.URL) (io.ReadCloser, error) {
+	return os.Open(uri.Path)
+}
+
+func (fileBackend) Create(uri *url.URL) (io.WriteCloser, error) {
+	return os.Create(uri.Path)
+}
+
+// httpBackend reads objects with HTTP GET requests. It cannot write.
+type httpBackend struct{}
+
+func (httpBackend) Open(uri *url.URL) (io.ReadCloser, error) {
+	resp, err := http.Get(uri.String())
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: %s", uri, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (httpBackend) Create(uri *url.URL) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("writing is not supported for scheme: %s", uri.Scheme)
+}
@@ -0,0 +1,81 @@
+package datatable
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+// memBackend stores objects in memory, keyed by host and path.
+type memBackend map[string]*bytes.Buffer
+
+func (m memBackend) Open(uri *url.URL) (io.ReadCloser, error) {
+	buf, exists := m[uri.Host+uri.Path]
+	if !exists {
+		return nil, fmt.Errorf("no such object: %s", uri)
+	}
+	return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+}
+
+func (m memBackend) Create(uri *url.URL) (io.WriteCloser, error) {
+	buf := &bytes.Buffer{}
+	m[uri.Host+uri.Path] = buf
+	return nopWriteCloser{buf}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestSources(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("a", []string{"x", "y"})
+	dt.AddColumn("b", []float64{1, 2})
+	want := dt.RawRows(true)
+
+	mem := memBackend{}
+	RegisterBackend("mem", mem)
+	path := filepath.Join(t.TempDir(), "t.csv")
+	for _, uri := range []string{path, "file://" + filepath.ToSlash(path), "mem://bucket/dir/t.csv"} {
+		if err := dt.WriteCSVSource(uri); err != nil {
+			t.Fatalf("%s: unexpected error writing: %v", uri, err)
+		}
+		got, err := ReadCSVSource(uri, CSVOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error reading: %v", uri, err)
+		}
+		if rows := got.RawRows(true); !equivalentRows(rows, want) {
+			t.Errorf("%s: got %v, wanted %v", uri, rows, want)
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/t.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(mem["bucket/dir/t.csv"].Bytes())
+	}))
+	defer srv.Close()
+	got, err := ReadCSVSource(srv.URL+"/t.csv", CSVOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error reading over http: %v", err)
+	}
+	if rows := got.RawRows(true); !equivalentRows(rows, want) {
+		t.Errorf("http: got %v, wanted %v", rows, want)
+	}
+
+	for _, uri := range []string{srv.URL + "/missing.csv", "unknown://x/y.csv", "mem://bucket/missing.csv"} {
+		if _, err := ReadCSVSource(uri, CSVOptions{}); err == nil {
+			t.Errorf("%s: got no error", uri)
+		}
+	}
+	if err := dt.WriteCSVSource(srv.URL + "/t.csv"); err == nil {
+		t.Errorf("got no error writing over http")
+	}
+}
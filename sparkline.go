@@ -0,0 +1,124 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	sparkTicks = []rune("▁▂▃▄▅▆▇█")
+	barEighths = []rune("▏▎▍▌▋▊▉█")
+)
+
+// Sparkline returns vals drawn as a line of block characters of eight heights,
+// the lowest for the minimum value and the highest for the maximum, such as
+// "▁▃▅█". NaN values are drawn as spaces. Infinite values are drawn at the
+// lowest or highest height and are not counted in the minimum and maximum. If
+// all finite values are equal they are drawn at the middle height.
+func Sparkline(vals []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range vals {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	top := len(sparkTicks) - 1
+	var b strings.Builder
+	for _, v := range vals {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case math.IsInf(v, 1):
+			b.WriteRune(sparkTicks[top])
+		case math.IsInf(v, -1):
+			b.WriteRune(sparkTicks[0])
+		case hi == lo:
+			b.WriteRune(sparkTicks[len(sparkTicks)/2-1])
+		default:
+			// halving keeps the differences finite for values of any size
+			i := int((v/2 - lo/2) / (hi/2 - lo/2) * float64(top))
+			b.WriteRune(sparkTicks[min(max(i, 0), top)])
+		}
+	}
+	return b.String()
+}
+
+// Bar returns v drawn as a horizontal bar of block characters whose length is
+// in proportion to v divided by full, with width characters for full itself.
+// Lengths are rounded to eighths of a character. The bar is empty if v is NaN
+// or not positive and is no longer than width, which it reaches if v is at
+// least full.
+func Bar(v, full float64, width int) string {
+	if math.IsNaN(v) || v <= 0 || !(full > 0) || width <= 0 {
+		return ""
+	}
+	ratio := 1.0
+	if v < full {
+		ratio = v / full
+	}
+	eighths := int(math.Round(ratio * float64(width*8)))
+	s := strings.Repeat(string(barEighths[7]), eighths/8)
+	if eighths%8 != 0 {
+		s += string(barEighths[eighths%8-1])
+	}
+	return s
+}
+
+// BarColumn appends a new string column to the table holding each value of
+// the numeric column srcCol drawn with Bar, scaled so that the maximum finite
+// value of the column is width characters long, for compact text reports.
+// Positive infinite values are drawn width characters long. Null values give
+// null bars.
+func (dt *DataTable) BarColumn(colName, srcCol string, width int) error {
+	c, exists := dt.colorder[srcCol]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", srcCol)
+	}
+	if !dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+
+	src := dt.cols[c]
+	hi := math.Inf(-1)
+	for _, v := range src.f {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			hi = math.Max(hi, v)
+		}
+	}
+	if math.IsInf(hi, -1) {
+		hi = math.Inf(1) // no finite values, so only +Inf has a bar
+	}
+	cv := colvals{s: make([]string, len(src.f))}
+	for i, v := range src.f {
+		cv.s[i] = Bar(v, hi, width)
+	}
+	cv.appendNulls(src, 0)
+	dt.addColumn(colName, cv)
+	dt.mutated()
+	return nil
+}
+
+// SparklineColumn appends a new string column to the table holding, in each
+// row, the Sparkline of the values of the numeric column srcCol for the group
+// of rows that share the row's key column values, or for the whole table if no
+// keys are set. The values are drawn in the table's current sort order.
+func (dt *DataTable) SparklineColumn(colName, srcCol string) error {
+	c, exists := dt.colorder[srcCol]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", srcCol)
+	}
+	if !dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+
+	src := dt.cols[c].f
+	col := make([]string, dt.Len())
+	dt.keyGroups(func(start, end int) {
+		line := Sparkline(src[start:end])
+		for i := start; i < end; i++ {
+			col[i] = line
+		}
+	})
+	return dt.AddStringColumn(colName, col)
+}
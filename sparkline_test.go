@@ -0,0 +1,94 @@
+package datatable
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	testCases := []struct {
+		vals []float64
+		want string
+	}{
+		{vals: []float64{1, 2, 3, 4, 5, 6, 7, 8}, want: "▁▂▃▄▅▆▇█"},
+		{vals: []float64{0, math.NaN(), 10}, want: "▁ █"},
+		{vals: []float64{3, 3}, want: "▄▄"},
+		{vals: []float64{1, math.Inf(1)}, want: "▄█"},
+		{vals: []float64{math.Inf(-1), 0, 7, math.Inf(1)}, want: "▁▁██"},
+		{vals: []float64{-math.MaxFloat64, math.MaxFloat64}, want: "▁█"},
+		{vals: nil, want: ""},
+	}
+	for _, tc := range testCases {
+		if got := Sparkline(tc.vals); got != tc.want {
+			t.Errorf("Sparkline(%v): got %q, wanted %q", tc.vals, got, tc.want)
+		}
+	}
+}
+
+func TestBar(t *testing.T) {
+	testCases := []struct {
+		v, max float64
+		width  int
+		want   string
+	}{
+		{v: 10, max: 10, width: 4, want: "████"},
+		{v: 5, max: 10, width: 4, want: "██"},
+		{v: 1, max: 10, width: 4, want: "▍"},
+		{v: 20, max: 10, width: 2, want: "██"},
+		{v: -1, max: 10, width: 4, want: ""},
+		{v: math.NaN(), max: 10, width: 4, want: ""},
+		{v: math.Inf(1), max: math.Inf(1), width: 2, want: "██"},
+		{v: math.Inf(1), max: 10, width: 2, want: "██"},
+		{v: 5, max: math.Inf(1), width: 2, want: ""},
+	}
+	for _, tc := range testCases {
+		if got := Bar(tc.v, tc.max, tc.width); got != tc.want {
+			t.Errorf("Bar(%v, %v, %d): got %q, wanted %q", tc.v, tc.max, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestSparklineColumns(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"a", "a", "a", "b", "b"})
+	dt.AddColumn("v", []float64{1, 3, 2, 4, 2})
+	dt.SetNull("v", 4)
+
+	if err := dt.BarColumn("bar", "v", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantBars := []string{"▌", "█▌", "█", "██", ""}
+	if got := dt.cols[dt.colorder["bar"]].s; !reflect.DeepEqual(got, wantBars) {
+		t.Errorf("got bars %q, wanted %q", got, wantBars)
+	}
+	if !dt.cols[dt.colorder["bar"]].isNull(4) {
+		t.Errorf("got non-null bar for null value")
+	}
+
+	dt.SetKeys("k")
+	if err := dt.SparklineColumn("spark", "v"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantLines := []string{"▁█▄", "▁█▄", "▁█▄", "▄ ", "▄ "}
+	if got := dt.cols[dt.colorder["spark"]].s; !reflect.DeepEqual(got, wantLines) {
+		t.Errorf("got sparklines %q, wanted %q", got, wantLines)
+	}
+
+	if err := dt.SparklineColumn("spark", "k"); err != ErrMismatchedColumnTypes {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if err := dt.BarColumn("bar", "missing", 2); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+
+	inf := &DataTable{}
+	inf.AddColumn("v", []float64{2, math.Inf(1), 4, math.Inf(-1)})
+	if err := inf.BarColumn("bar", "v", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantBars = []string{"█", "██", "██", ""}
+	if got := inf.cols[inf.colorder["bar"]].s; !reflect.DeepEqual(got, wantBars) {
+		t.Errorf("got bars %q with infinite values, wanted %q", got, wantBars)
+	}
+}
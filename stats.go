@@ -0,0 +1,373 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// WeightedQuantile returns an Aggregator that finds the q-quantile of a numeric
+// column in a group of rows, with each row weighted by the value of a second
+// numeric column, such as a survey weight. The result is the smallest value
+// whose cumulative weight reaches the fraction q of the total weight, or the
+// midpoint of two adjacent values if the cumulative weight equals that
+// fraction exactly, so equal weights give the usual median. Rows whose value or
+// weight is NaN, or whose weight is not positive, are ignored. NaN is returned
+// if q is outside the range [0, 1] or no rows have positive weight.
+func WeightedQuantile(valueCol, weightCol string, q float64) Aggregator {
+	type pair struct{ v, w float64 }
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		if !(q >= 0 && q <= 1) {
+			return math.NaN()
+		}
+		var pairs []pair
+		total := 0.0
+		for rg.Next() {
+			v, _ := rg.FloatValue(valueCol)
+			w, _ := rg.FloatValue(weightCol)
+			if math.IsNaN(v) || !(w > 0) {
+				continue
+			}
+			pairs = append(pairs, pair{v, w})
+			total += w
+		}
+		if len(pairs) == 0 {
+			return math.NaN()
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].v < pairs[j].v })
+
+		target := q * total
+		cum := 0.0
+		for i, p := range pairs {
+			cum += p.w
+			if cum < target {
+				continue
+			}
+			if cum == target && i+1 < len(pairs) && q > 0 {
+				return (p.v + pairs[i+1].v) / 2
+			}
+			return p.v
+		}
+		return pairs[len(pairs)-1].v
+	}), callExpr("WeightedQuantile", valueCol, weightCol, q), valueCol, weightCol)
+}
+
+// An Interpolation selects how Quantile finds a quantile that falls between
+// two values.
+type Interpolation int
+
+const (
+	InterpolateLinear  Interpolation = iota // interpolate between the two values
+	InterpolateNearest                      // take the nearer value, or the one at an even position if equally near
+)
+
+// Quantile returns an Aggregator that finds the q-quantile of a numeric column
+// in a group of rows, such as 0.5 for the median or 0.99 for the 99th
+// percentile. The quantile is at position q*(n-1) of the n sorted values,
+// which falls between two values unless it is a whole number, in which case
+// interp chooses the result. NaN and null values are ignored. NaN is returned
+// if q is outside the range [0, 1] or there are no values.
+func Quantile(name string, q float64, interp Interpolation) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		vals := quantileValues(rg, name, nil)
+		sort.Float64s(vals)
+		return quantile(vals, q, interp)
+	}), callExpr("Quantile", name, q, interp), name)
+}
+
+// Quantiles returns an Aggregator for each of qs that finds that quantile as
+// Quantile does. The aggregators share a sorted copy of the values so, when
+// they are applied to the same group in turn, as AggregateMany does, the values
+// are only sorted once. They must not be used concurrently.
+func Quantiles(name string, qs []float64, interp Interpolation) []Aggregator {
+	var raw, sorted, buf []float64
+	ret := make([]Aggregator, len(qs))
+	for i, q := range qs {
+		q := q
+		ret[i] = describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+			buf = quantileValues(rg, name, buf[:0])
+			if !equalFloats(buf, raw) {
+				raw = append(raw[:0], buf...)
+				sorted = append(sorted[:0], buf...)
+				sort.Float64s(sorted)
+			}
+			return quantile(sorted, q, interp)
+		}), callExpr("Quantile", name, q, interp), name)
+	}
+	return ret
+}
+
+// quantileValues appends the values of the named column in rg that are not
+// NaN or null to buf.
+func quantileValues(rg RowGroup, name string, buf []float64) []float64 {
+	for rg.Next() {
+		if isNullIn(rg, name) {
+			continue
+		}
+		if v, _ := rg.FloatValue(name); !math.IsNaN(v) {
+			buf = append(buf, v)
+		}
+	}
+	return buf
+}
+
+// quantile returns the q-quantile of the sorted values.
+func quantile(sorted []float64, q float64, interp Interpolation) float64 {
+	if len(sorted) == 0 || !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	if interp == InterpolateNearest {
+		return sorted[int(math.RoundToEven(pos))]
+	}
+	lo := int(pos)
+	if lo == len(sorted)-1 {
+		return sorted[lo]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo] + frac*(sorted[lo+1]-sorted[lo])
+}
+
+// equalFloats reports whether a and b hold the same values in the same
+// order.
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GeometricMean returns an Aggregator that finds the geometric mean of a
+// numeric column in a group of rows, ignoring NaN values. The geometric mean
+// is only defined for positive values so NaN is returned if the group contains
+// any zero or negative values. Use CountNonPositive to report how many rows
+// prevented the mean being calculated.
+func GeometricMean(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		sum := 0.0
+		count := 0
+		for rg.Next() {
+			v, _ := rg.FloatValue(name)
+			if math.IsNaN(v) {
+				continue
+			}
+			if v <= 0 {
+				return math.NaN()
+			}
+			sum += math.Log(v)
+			count++
+		}
+		if count == 0 {
+			return math.NaN()
+		}
+		return math.Exp(sum / float64(count))
+	}), callExpr("GeometricMean", name), name)
+}
+
+// HarmonicMean returns an Aggregator that finds the harmonic mean of a numeric
+// column in a group of rows, ignoring NaN values. NaN is returned if the group
+// contains any zero or negative values. Use CountNonPositive to report how many
+// rows prevented the mean being calculated.
+func HarmonicMean(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		sum := 0.0
+		count := 0
+		for rg.Next() {
+			v, _ := rg.FloatValue(name)
+			if math.IsNaN(v) {
+				continue
+			}
+			if v <= 0 {
+				return math.NaN()
+			}
+			sum += 1 / v
+			count++
+		}
+		if count == 0 {
+			return math.NaN()
+		}
+		return float64(count) / sum
+	}), callExpr("HarmonicMean", name), name)
+}
+
+// CountNonPositive returns an Aggregator that counts the rows in a group whose
+// value of a numeric column is zero or negative, which are the rows that cause
+// GeometricMean and HarmonicMean to return NaN.
+func CountNonPositive(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		count := 0
+		for rg.Next() {
+			if v, _ := rg.FloatValue(name); v <= 0 {
+				count++
+			}
+		}
+		return float64(count)
+	}), callExpr("CountNonPositive", name), name)
+}
+
+// Entropy returns an Aggregator that finds the Shannon entropy, in bits, of
+// the distribution of values of a string column in a group of rows. It is zero
+// when every row has the same value and greatest when every row differs. Null
+// values are skipped. NaN is returned for a group with no values.
+func Entropy(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		counts, n := stringCounts(rg, name)
+		if n == 0 {
+			return math.NaN()
+		}
+		h := 0.0
+		for _, c := range counts {
+			p := float64(c) / float64(n)
+			h -= p * math.Log2(p)
+		}
+		return h
+	}), callExpr("Entropy", name), name)
+}
+
+// Gini returns an Aggregator that finds the Gini impurity of the distribution
+// of values of a string column in a group of rows: the probability that two
+// rows drawn at random, with replacement, have different values. Null values
+// are skipped. NaN is returned for a group with no values.
+func Gini(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		counts, n := stringCounts(rg, name)
+		if n == 0 {
+			return math.NaN()
+		}
+		sum := 0.0
+		for _, c := range counts {
+			p := float64(c) / float64(n)
+			sum += p * p
+		}
+		return 1 - sum
+	}), callExpr("Gini", name), name)
+}
+
+// stringCounts counts the occurrences of each value of a string column in a
+// group of rows, skipping null values, returning the counts and the number of
+// rows counted.
+func stringCounts(rg RowGroup, name string) (map[string]int, int) {
+	counts := map[string]int{}
+	n := 0
+	for rg.Next() {
+		if isNullIn(rg, name) {
+			continue
+		}
+		if v, exists := rg.StringValue(name); exists {
+			counts[v]++
+			n++
+		}
+	}
+	return counts, n
+}
+
+// ModeFloat returns an Aggregator that finds the most frequent value of a
+// numeric column in a group of rows, ignoring NaN values. Ties are broken by
+// choosing the smallest of the most frequent values. NaN is returned if there
+// are no values.
+func ModeFloat(name string) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		counts := map[float64]int{}
+		for rg.Next() {
+			if v, _ := rg.FloatValue(name); !math.IsNaN(v) {
+				counts[v]++
+			}
+		}
+		mode, best := math.NaN(), 0
+		for v, c := range counts {
+			if c > best || c == best && v < mode {
+				mode, best = v, c
+			}
+		}
+		return mode
+	}), callExpr("ModeFloat", name), name)
+}
+
+// GroupCov calculates the sample covariance of each pair of the named numeric
+// columns within each group of rows that share the values of the keys
+// columns. The result is a new table in long format with one row per group
+// and pair of columns, holding the key values followed by string columns
+// named "col1" and "col2" identifying the pair and a numeric column named
+// "cov". Pairs are listed in the order of cols and include each column paired
+// with itself, giving its variance. Rows where either value is NaN are ignored
+// for that pair. The result is sorted by the keys and has them set.
+func (dt *DataTable) GroupCov(keys []string, cols []string) (*DataTable, error) {
+	for _, name := range cols {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		if !dt.isFloatCol(c) {
+			return nil, fmt.Errorf("%w: column %s is not numeric", ErrMismatchedColumnTypes, name)
+		}
+	}
+	sel, err := dt.Select(append(append([]string{}, keys...), cols...))
+	if err != nil {
+		return nil, err
+	}
+	if err := sel.SetKeys(keys...); err != nil {
+		return nil, err
+	}
+
+	dataCols := make([]int, len(cols))
+	for i, name := range cols {
+		dataCols[i] = sel.colorder[name]
+	}
+
+	var rows []int
+	var col1, col2 []string
+	var cov []float64
+	sel.keyGroups(func(start, end int) {
+		group := fillRange(start, end)
+		for i, a := range dataCols {
+			for j := i; j < len(dataCols); j++ {
+				rows = append(rows, group[0])
+				col1 = append(col1, cols[i])
+				col2 = append(col2, cols[j])
+				cov = append(cov, covariance(sel.cols[a].f, sel.cols[dataCols[j]].f, group))
+			}
+		}
+	})
+
+	ret, err := sel.SelectIndex(keys, rows)
+	if err != nil {
+		return nil, err
+	}
+	ret.AddStringColumn("col1", col1)
+	ret.AddStringColumn("col2", col2)
+	ret.AddColumn("cov", cov)
+	ret.keys = keyColumns(keys, ret)
+	return ret, nil
+}
+
+// covariance returns the sample covariance of the values of x and y at the
+// given indices, ignoring pairs where either value is NaN.
+func covariance(x, y []float64, indices []int) float64 {
+	var sx, sy float64
+	n := 0
+	for _, i := range indices {
+		if math.IsNaN(x[i]) || math.IsNaN(y[i]) {
+			continue
+		}
+		sx += x[i]
+		sy += y[i]
+		n++
+	}
+	if n < 2 {
+		return math.NaN()
+	}
+	mx, my := sx/float64(n), sy/float64(n)
+	ss := 0.0
+	for _, i := range indices {
+		if math.IsNaN(x[i]) || math.IsNaN(y[i]) {
+			continue
+		}
+		ss += (x[i] - mx) * (y[i] - my)
+	}
+	return ss / float64(n-1)
+}
@@ -0,0 +1,193 @@
+package datatable
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWeightedQuantile(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("v", []float64{4, 1, 3, 2, 100, math.NaN()})
+	dt.AddColumn("w", []float64{1, 1, 1, 1, 0, 5})
+	dt.AddColumn("w2", []float64{1, 1, 1, 5, 1, 1})
+
+	testCases := []struct {
+		weight   string
+		q        float64
+		expected float64
+	}{
+		{"w", 0.5, 2.5},
+		{"w", 0, 1},
+		{"w", 1, 4},
+		{"w", 0.3, 2},
+		{"w2", 0.5, 2},
+		{"w", 1.5, math.NaN()},
+	}
+	for _, tc := range testCases {
+		got := dt.Reduce(WeightedQuantile("v", tc.weight, tc.q))
+		if !equivalentFloats(got, tc.expected) {
+			t.Errorf("WeightedQuantile(v, %s, %v): got %v, wanted %v", tc.weight, tc.q, got, tc.expected)
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("v", []float64{4, 1, math.NaN(), 3, 2, 10})
+	dt.SetNull("v", 5)
+
+	testCases := []struct {
+		q        float64
+		interp   Interpolation
+		expected float64
+	}{
+		{0.5, InterpolateLinear, 2.5},
+		{0.5, InterpolateNearest, 3},
+		{0.9, InterpolateLinear, 3.7},
+		{0.9, InterpolateNearest, 4},
+		{0, InterpolateLinear, 1},
+		{1, InterpolateNearest, 4},
+		{-0.1, InterpolateLinear, math.NaN()},
+	}
+	for _, tc := range testCases {
+		got := dt.Reduce(Quantile("v", tc.q, tc.interp))
+		if !equivalentFloats(got, tc.expected) {
+			t.Errorf("Quantile(v, %v, %d): got %v, wanted %v", tc.q, tc.interp, got, tc.expected)
+		}
+	}
+
+	grouped := &DataTable{}
+	grouped.AddStringColumn("g", []string{"a", "b", "a", "b", "a"})
+	grouped.AddColumn("v", []float64{1, 10, 2, 20, 3})
+	summary, err := grouped.GroupBy("g").AggregateMany([]string{"p50", "p90"}, Quantiles("v", []float64{0.5, 0.9}, InterpolateLinear))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]interface{}{
+		{"a", 2.0, 2.8},
+		{"b", 15.0, 19.0},
+	}
+	if rows := summary.RawRows(false); !equivalentRows(rows, want) {
+		t.Errorf("got %v, wanted %v", rows, want)
+	}
+}
+
+func TestGeometricAndHarmonicMean(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "a", "a", "b", "b"})
+	dt.AddColumn("v", []float64{1, 4, math.NaN(), 2, -1})
+	dt.SetKeys("g")
+
+	dt.Aggregate("geo", GeometricMean("v"))
+	dt.Aggregate("harm", HarmonicMean("v"))
+	dt.Aggregate("excluded", CountNonPositive("v"))
+
+	nan := math.NaN()
+	expected := [][]interface{}{
+		{"a", 1.0, 2.0, 1.6, 0.0},
+		{"a", 4.0, 2.0, 1.6, 0.0},
+		{"a", nan, 2.0, 1.6, 0.0},
+		{"b", 2.0, nan, nan, 1.0},
+		{"b", -1.0, nan, nan, 1.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+}
+
+func TestEntropyAndGini(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("g", []string{"a", "a", "a", "a", "b", "b"})
+	dt.AddStringColumn("v", []string{"x", "y", "z", "w", "x", "x"})
+	dt.SetKeys("g")
+
+	dt.Aggregate("entropy", Entropy("v"))
+	dt.Aggregate("gini", Gini("v"))
+
+	expected := [][]interface{}{
+		{"a", "x", 2.0, 0.75},
+		{"a", "y", 2.0, 0.75},
+		{"a", "z", 2.0, 0.75},
+		{"a", "w", 2.0, 0.75},
+		{"b", "x", 0.0, 0.0},
+		{"b", "x", 0.0, 0.0},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+
+	if got := dt.Reduce(Entropy("nope")); !math.IsNaN(got) {
+		t.Errorf("got %v for unknown column, wanted NaN", got)
+	}
+
+	withNulls := &DataTable{}
+	withNulls.AddStringColumn("v", []string{"x", "y", "", ""})
+	withNulls.SetNull("v", 2)
+	withNulls.SetNull("v", 3)
+	if got := withNulls.Reduce(Entropy("v")); got != 1 {
+		t.Errorf("got entropy %v with nulls, wanted 1", got)
+	}
+	if got := withNulls.Reduce(Gini("v")); got != 0.5 {
+		t.Errorf("got gini %v with nulls, wanted 0.5", got)
+	}
+	withNulls.SetNull("v", 0)
+	withNulls.SetNull("v", 1)
+	if got := withNulls.Reduce(Gini("v")); !math.IsNaN(got) {
+		t.Errorf("got gini %v for only nulls, wanted NaN", got)
+	}
+}
+
+func TestModeFloat(t *testing.T) {
+	testCases := []struct {
+		values   []float64
+		expected float64
+	}{
+		{[]float64{1, 2, 2, 3}, 2},
+		{[]float64{3, 1, 3, 1, 2}, 1},
+		{[]float64{math.NaN(), math.NaN(), 5}, 5},
+		{[]float64{math.NaN()}, math.NaN()},
+	}
+	for _, tc := range testCases {
+		dt := &DataTable{}
+		dt.AddColumn("v", tc.values)
+		if got := dt.Reduce(ModeFloat("v")); !equivalentFloats(got, tc.expected) {
+			t.Errorf("ModeFloat(%v): got %v, wanted %v", tc.values, got, tc.expected)
+		}
+	}
+}
+
+func TestGroupCov(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("seg", []string{"b", "a", "b", "a", "a", "b"})
+	dt.AddColumn("x", []float64{1, 1, 2, 2, 3, 3})
+	dt.AddColumn("y", []float64{6, 2, 4, 4, 6, math.NaN()})
+
+	cov, err := dt.GroupCov([]string{"seg"}, []string{"x", "y"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]interface{}{
+		{"seg", "col1", "col2", "cov"},
+		{"a", "x", "x", 1.0},
+		{"a", "x", "y", 2.0},
+		{"a", "y", "y", 4.0},
+		{"b", "x", "x", 1.0},
+		{"b", "x", "y", -1.0},
+		{"b", "y", "y", 2.0},
+	}
+	if !equivalentRows(cov.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", cov.RawRows(true), expected)
+	}
+	if names := cov.KeyNames(); len(names) != 1 || names[0] != "seg" {
+		t.Errorf("got keys %v, wanted [seg]", names)
+	}
+
+	if _, err := dt.GroupCov([]string{"seg"}, []string{"seg"}); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got error %v, wanted ErrMismatchedColumnTypes", err)
+	}
+	if _, err := dt.GroupCov([]string{"seg"}, []string{"z"}); err == nil {
+		t.Errorf("expected error for unknown column")
+	}
+}
@@ -0,0 +1,200 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MergeSummaries combines summary tables that were aggregated separately,
+// such as partial results calculated by several workers, into a single
+// summary. The rows of all the tables are aligned by the values of the keys
+// columns, which must exist in every table, and the result holds one row for
+// each distinct combination of key values found in any table. Each entry in
+// combine names a numeric column of the result and gives the aggregator used
+// to recombine the partial values of the rows sharing those key values, for
+// example Sum("total") to add partial sums or Max("high") to find the maximum
+// of partial maximums. Columns missing from some of the tables are treated as
+// NaN or empty for the rows of those tables. The result has the key columns
+// followed by the combined columns in name order and is sorted by the keys,
+// which are set.
+func MergeSummaries(tables []*DataTable, keys []string, combine map[string]Aggregator) (*DataTable, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key columns given")
+	}
+	stacked := &DataTable{}
+	for _, t := range tables {
+		for _, key := range keys {
+			if _, exists := t.colorder[key]; !exists {
+				return nil, fmt.Errorf("unknown column: %s", key)
+			}
+		}
+		if err := stacked.Append(t); err != nil {
+			return nil, err
+		}
+	}
+	if stacked.N() == 0 {
+		return nil, fmt.Errorf("no tables given")
+	}
+	if err := stacked.SetKeys(keys...); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(combine))
+	for name := range combine {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	aggs := make([]Aggregator, len(names))
+	for i, name := range names {
+		aggs[i] = combine[name]
+	}
+	return stacked.summarise(names, aggs)
+}
+
+// summarise returns a new table holding one row for each group of rows that
+// share the values of the table's keys, or a single row if no keys are set.
+// The new table has the key columns, set as its keys, followed by a numeric
+// column for each of names holding the result of the corresponding aggregator
+// for the group.
+func (dt *DataTable) summarise(names []string, aggs []Aggregator) (*DataTable, error) {
+	var rows []int
+	vals := make([][]float64, len(aggs))
+	dt.keyGroups(func(start, end int) {
+		rows = append(rows, start)
+		rg := &StaticRowGroup{dt: dt, indices: fillRange(start, end)}
+		for i, a := range aggs {
+			rg.Reset()
+			vals[i] = append(vals[i], a.Aggregate(rg))
+		}
+	})
+
+	keys := dt.KeyNames()
+	ret, err := dt.SelectIndex(keys, rows)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		if _, exists := ret.colorder[name]; exists {
+			return nil, fmt.Errorf("duplicate column: %s", name)
+		}
+		if vals[i] == nil {
+			vals[i] = []float64{}
+		}
+		ret.addColumn(name, colvals{f: vals[i]})
+	}
+	ret.keys = keyColumns(keys, ret)
+	return ret, nil
+}
+
+// A GroupedTable is a view of a table whose rows are grouped by the values of
+// a set of columns, created by GroupBy.
+type GroupedTable struct {
+	dt       *DataTable
+	keys     []string
+	suppress SuppressOptions
+}
+
+// SuppressOptions controls the suppression of small groups from summaries, a
+// common requirement for published statistics so that individuals cannot be
+// identified from groups with very few members.
+type SuppressOptions struct {
+	// MinRows is the fewest rows a group may have for its aggregated values
+	// to be reported. Groups with fewer rows have null values. The default
+	// suppresses no groups.
+	MinRows int
+
+	// Drop removes suppressed groups from the summary entirely instead of
+	// setting their values to null.
+	Drop bool
+}
+
+// GroupBy groups the rows of the table by the values of the named columns,
+// without modifying the table, so that they can be summarised. With no names
+// all rows of the table form a single group.
+func (dt *DataTable) GroupBy(keys ...string) *GroupedTable {
+	return &GroupedTable{dt: dt, keys: keys}
+}
+
+// Suppress returns a copy of the grouping whose summaries suppress groups
+// with too few rows according to opts.
+func (g *GroupedTable) Suppress(opts SuppressOptions) *GroupedTable {
+	return &GroupedTable{dt: g.dt, keys: g.keys, suppress: opts}
+}
+
+// Summarise returns a new table holding one row for each group, unlike
+// Aggregate which repeats the value of a group in each of its rows. The new
+// table has the grouping columns, set as its keys, followed by a numeric
+// column for each entry in aggs, in name order, holding the result of the
+// aggregator for the group. An error is returned if a grouping column does not
+// exist or an entry in aggs has the same name as a grouping column.
+func (g *GroupedTable) Summarise(aggs map[string]Aggregator) (*DataTable, error) {
+	names := make([]string, 0, len(aggs))
+	for name := range aggs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]Aggregator, len(names))
+	for i, name := range names {
+		list[i] = aggs[name]
+	}
+	return g.AggregateMany(names, list)
+}
+
+// AggregateMany is like Summarise but takes the names of the new columns and
+// their aggregators as parallel slices, which also sets the order of the
+// columns. Every aggregator is evaluated for a group before moving on to the
+// next. An error is returned if names and aggs have different lengths.
+func (g *GroupedTable) AggregateMany(names []string, aggs []Aggregator) (*DataTable, error) {
+	if len(names) != len(aggs) {
+		return nil, fmt.Errorf("got %d column names for %d aggregators", len(names), len(aggs))
+	}
+	grouped := g.dt.Clone()
+	if err := grouped.SetKeys(g.keys...); err != nil {
+		return nil, err
+	}
+	ret, err := grouped.summarise(names, aggs)
+	if err != nil || g.suppress.MinRows <= 0 {
+		return ret, err
+	}
+
+	var small []int
+	group := 0
+	grouped.keyGroups(func(start, end int) {
+		if end-start < g.suppress.MinRows {
+			small = append(small, group)
+		}
+		group++
+	})
+	if g.suppress.Drop {
+		ret.removeRows(small)
+		return ret, nil
+	}
+	for _, name := range names {
+		c := ret.colorder[name]
+		for _, row := range small {
+			ret.cols[c].setFloat(row, math.NaN())
+			ret.cols[c].setNull(row, true)
+		}
+	}
+	return ret, nil
+}
+
+// SuppressSmall returns an Aggregator that gives the result of a for groups
+// with at least minRows rows and NaN for smaller groups, so that values
+// computed from very few rows, which might identify individuals, are not
+// reported. It can be used wherever an aggregator is, such as with Aggregate
+// or Crosstab.
+func SuppressSmall(a Aggregator, minRows int) Aggregator {
+	return describeAggregator(AggregatorFunc(func(rg RowGroup) float64 {
+		n := 0
+		for rg.Next() {
+			n++
+		}
+		rg.Reset()
+		if n < minRows {
+			return math.NaN()
+		}
+		return a.Aggregate(rg)
+	}), callExpr("SuppressSmall", a, minRows))
+}
@@ -0,0 +1,160 @@
+package datatable
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMergeSummaries(t *testing.T) {
+	w1 := &DataTable{}
+	w1.AddStringColumn("region", []string{"north", "south"})
+	w1.AddColumn("total", []float64{10, 20})
+	w1.AddColumn("high", []float64{1, 5})
+
+	w2 := &DataTable{}
+	w2.AddStringColumn("region", []string{"west", "north"})
+	w2.AddColumn("high", []float64{7, 7.5})
+	w2.AddColumn("total", []float64{3, 4})
+
+	merged, err := MergeSummaries([]*DataTable{w1, w2}, []string{"region"}, map[string]Aggregator{
+		"total": Sum("total"),
+		"high":  Max("high"),
+		"parts": Count(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]interface{}{
+		{"region", "high", "parts", "total"},
+		{"north", 7.5, 2.0, 14.0},
+		{"south", 5.0, 1.0, 20.0},
+		{"west", 7.0, 1.0, 3.0},
+	}
+	if !equivalentRows(merged.RawRows(true), expected) {
+		t.Errorf("got %v, wanted %v", merged.RawRows(true), expected)
+	}
+	if names := merged.KeyNames(); len(names) != 1 || names[0] != "region" {
+		t.Errorf("got keys %v, wanted [region]", names)
+	}
+
+	w3 := &DataTable{}
+	w3.AddColumn("total", []float64{1})
+	if _, err := MergeSummaries([]*DataTable{w1, w3}, []string{"region"}, map[string]Aggregator{"total": Sum("total")}); err == nil {
+		t.Errorf("expected error for table missing key column")
+	}
+
+	if _, err := MergeSummaries([]*DataTable{w1}, []string{"region"}, map[string]Aggregator{"region": Count()}); err == nil {
+		t.Errorf("expected error for combined column named as key")
+	}
+}
+
+func TestGroupBySummarise(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"south", "north", "south", "north", "west"})
+	dt.AddColumn("sales", []float64{10, 20, 30, 40, 50})
+
+	got, err := dt.GroupBy("region").Summarise(map[string]Aggregator{
+		"total": Sum("sales"),
+		"count": Count(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]interface{}{
+		{"region", "count", "total"},
+		{"north", 2.0, 60.0},
+		{"south", 2.0, 40.0},
+		{"west", 1.0, 50.0},
+	}
+	if rows := got.RawRows(true); !equivalentRows(rows, want) {
+		t.Errorf("got %v, wanted %v", rows, want)
+	}
+	if keys := got.KeyNames(); len(keys) != 1 || keys[0] != "region" {
+		t.Errorf("got keys %v, wanted [region]", keys)
+	}
+	if dt.cols[0].s[0] != "south" || len(dt.KeyNames()) != 0 {
+		t.Errorf("grouping modified the table")
+	}
+
+	got, err = dt.GroupBy().Summarise(map[string]Aggregator{"total": Sum("sales")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := got.RawRows(false); !equivalentRows(rows, [][]interface{}{{150.0}}) {
+		t.Errorf("got %v, wanted [[150]]", rows)
+	}
+
+	if _, err := dt.GroupBy("missing").Summarise(nil); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+	if _, err := dt.GroupBy("region").Summarise(map[string]Aggregator{"region": Count()}); err == nil {
+		t.Errorf("got no error for duplicate column")
+	}
+}
+
+func TestGroupByAggregateMany(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"s", "n", "s", "n", "s"})
+	dt.AddColumn("sales", []float64{1, 2, 3, 4, 5})
+
+	got, err := dt.GroupBy("region").AggregateMany([]string{"total", "mean"}, []Aggregator{Sum("sales"), Mean("sales")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]interface{}{
+		{"region", "total", "mean"},
+		{"n", 6.0, 3.0},
+		{"s", 9.0, 3.0},
+	}
+	if rows := got.RawRows(true); !equivalentRows(rows, want) {
+		t.Errorf("got %v, wanted %v", rows, want)
+	}
+
+	if _, err := dt.GroupBy("region").AggregateMany([]string{"total"}, nil); err == nil {
+		t.Errorf("got no error for mismatched lengths")
+	}
+}
+
+func TestGroupBySuppress(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"s", "n", "s", "w", "s"})
+	dt.AddColumn("sales", []float64{1, 2, 3, 4, 5})
+	aggs := map[string]Aggregator{"total": Sum("sales"), "count": Count()}
+
+	got, err := dt.GroupBy("region").Suppress(SuppressOptions{MinRows: 2}).Summarise(aggs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]interface{}{
+		{"n", math.NaN(), math.NaN()},
+		{"s", 3.0, 9.0},
+		{"w", math.NaN(), math.NaN()},
+	}
+	if rows := got.RawRows(false); !equivalentRows(rows, want) {
+		t.Errorf("got %v, wanted %v", rows, want)
+	}
+	if n, _ := got.NullCount("total"); n != 2 {
+		t.Errorf("got %d null totals, wanted 2", n)
+	}
+
+	got, err = dt.GroupBy("region").Suppress(SuppressOptions{MinRows: 2, Drop: true}).Summarise(aggs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := got.RawRows(false); !equivalentRows(rows, [][]interface{}{{"s", 3.0, 9.0}}) {
+		t.Errorf("got %v, wanted [[s 3 9]]", rows)
+	}
+}
+
+func TestSuppressSmall(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"s", "n", "s"})
+	dt.AddColumn("sales", []float64{1, 2, 3})
+	dt.SetKeys("region")
+
+	dt.Aggregate("total", SuppressSmall(Sum("sales"), 2))
+	if got := dt.cols[dt.colorder["total"]].f; !equivalentFloatSlices(got, []float64{math.NaN(), 4, 4}) {
+		t.Errorf("got %v, wanted [NaN 4 4]", got)
+	}
+}
@@ -0,0 +1,77 @@
+package datatable
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SplitColumn splits each value of the named string column around sep and
+// adds the parts as new string columns named by newNames. Values with fewer
+// parts than newNames are padded with empty strings while any parts beyond
+// the number of new columns are left unsplit in the last new column.
+func (dt *DataTable) SplitColumn(name, sep string, newNames []string) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	if len(newNames) == 0 {
+		return fmt.Errorf("no new column names specified")
+	}
+
+	cols := make([][]string, len(newNames))
+	for i := range cols {
+		cols[i] = make([]string, dt.Len())
+	}
+	for row, v := range dt.cols[c].s {
+		for i, part := range strings.SplitN(v, sep, len(newNames)) {
+			cols[i][row] = part
+		}
+	}
+
+	for i, newName := range newNames {
+		dt.AddStringColumn(newName, cols[i])
+	}
+	return nil
+}
+
+// Extract applies re to each value of the named string column and adds the
+// text of the regular expression's capture groups as new string columns named
+// by newNames, the first new column receiving the first capture group. Rows
+// that do not match, or groups that do not participate in a match, are
+// assigned an empty string. An error is returned if there are more new names
+// than capture groups.
+func (dt *DataTable) Extract(name string, re *regexp.Regexp, newNames []string) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	if len(newNames) > re.NumSubexp() {
+		return fmt.Errorf("regular expression has %d capture groups, %d column names specified", re.NumSubexp(), len(newNames))
+	}
+
+	cols := make([][]string, len(newNames))
+	for i := range cols {
+		cols[i] = make([]string, dt.Len())
+	}
+	for row, v := range dt.cols[c].s {
+		m := re.FindStringSubmatch(v)
+		if m == nil {
+			continue
+		}
+		for i := range cols {
+			cols[i][row] = m[i+1]
+		}
+	}
+
+	for i, newName := range newNames {
+		dt.AddStringColumn(newName, cols[i])
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package datatable
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSplitColumn(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("date", []string{"2023-04-01", "2023-05", "2023-06-02-x"})
+
+	if err := dt.SplitColumn("date", "-", []string{"y", "m", "d"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"date", "y", "m", "d"},
+		{"2023-04-01", "2023", "04", "01"},
+		{"2023-05", "2023", "05", ""},
+		{"2023-06-02-x", "2023", "06", "02-x"},
+	}
+	if rows := dt.RawRows(true); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	if err := dt.SplitColumn("missing", "-", []string{"a"}); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("line", []string{
+		"GET /index.html 200",
+		"POST /api 500",
+		"garbage",
+	})
+
+	re := regexp.MustCompile(`^(\w+) (\S+) (\d+)$`)
+	if err := dt.Extract("line", re, []string{"method", "path"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedRows := [][]interface{}{
+		{"GET /index.html 200", "GET", "/index.html"},
+		{"POST /api 500", "POST", "/api"},
+		{"garbage", "", ""},
+	}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expectedRows) {
+		t.Errorf("got %+v, wanted %+v", rows, expectedRows)
+	}
+
+	if err := dt.Extract("line", re, []string{"a", "b", "c", "d"}); err == nil {
+		t.Errorf("got no error for too many column names")
+	}
+}
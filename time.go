@@ -0,0 +1,223 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// A TimeUnit is a calendar unit that timestamps may be truncated to.
+type TimeUnit int
+
+const (
+	Second TimeUnit = iota
+	Minute
+	Hour
+	Day
+	Week // weeks start on Monday
+	Month
+	Year
+)
+
+// DefaultTimeLayout is the layout used to parse and format the values of a
+// time column that has no layouts set.
+const DefaultTimeLayout = time.RFC3339Nano
+
+// minTime and maxTime are the range of times that can be held in a time
+// column.
+var (
+	minTime = time.Unix(0, math.MinInt64)
+	maxTime = time.Unix(0, math.MaxInt64)
+)
+
+// AddTimeColumn adds a time column holding vals. Time columns are numeric
+// columns holding seconds since the Unix epoch, usable anywhere a numeric
+// column is and by calculators such as DaysBetween, but also keep the exact
+// times to the nanosecond for sorting, grouping and TimeValue. Values are
+// parsed by ParseRow and written by CSV using the column's layouts, set with
+// SetTimeLayouts, which default to DefaultTimeLayout. Times are returned in
+// UTC. A zero time is held as NaN. An error is returned if any time is
+// outside the years 1678 to 2262.
+func (dt *DataTable) AddTimeColumn(name string, vals []time.Time) error {
+	if len(dt.cols) != 0 && len(vals) != dt.Len() {
+		return ErrInvalidColumnLength
+	}
+	cv := colvals{
+		f:       make([]float64, len(vals)),
+		d:       make([]int64, len(vals)),
+		scale:   9,
+		layouts: []string{DefaultTimeLayout},
+	}
+	for i, t := range vals {
+		if t.IsZero() {
+			cv.f[i] = math.NaN()
+			continue
+		}
+		if t.Before(minTime) || t.After(maxTime) {
+			return fmt.Errorf("time out of range: %v", t)
+		}
+		cv.d[i] = t.UnixNano()
+		cv.f[i] = fromUnits(cv.d[i], 9)
+	}
+	dt.addColumn(name, cv)
+	dt.mutated()
+	return nil
+}
+
+// SetTimeLayouts sets the layouts, in the form used by the time package, used
+// to parse and format the values of the named time column. ParseRow accepts a
+// value written in any of the layouts, trying them in order, while the first
+// is used to format values. Values without a time zone are read as UTC.
+func (dt *DataTable) SetTimeLayouts(name string, layouts ...string) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if dt.cols[c].layouts == nil {
+		return ErrMismatchedColumnTypes
+	}
+	if len(layouts) == 0 {
+		return fmt.Errorf("no time layouts given")
+	}
+	dt.cols[c].layouts = append([]string(nil), layouts...)
+	return nil
+}
+
+// addParsedTimeColumn adds a time column holding values parsed using layouts.
+func (dt *DataTable) addParsedTimeColumn(name string, values []string, layouts []string) error {
+	if len(layouts) == 0 {
+		return fmt.Errorf("no time layouts given for column %s", name)
+	}
+	cv := colvals{
+		f:       make([]float64, len(values)),
+		d:       make([]int64, len(values)),
+		scale:   9,
+		layouts: append([]string(nil), layouts...),
+	}
+	for i, s := range values {
+		var err error
+		cv.d[i], cv.f[i], err = parseTime(s, layouts)
+		if err != nil {
+			return fmt.Errorf("%v (column %s)", err, name)
+		}
+	}
+	dt.addColumn(name, cv)
+	dt.mutated()
+	return nil
+}
+
+// timeAt returns the value of column c in row n as a time, or false if the
+// column is not a time column or the value is NaN.
+func (dt *DataTable) timeAt(c, n int) (time.Time, bool) {
+	cv := dt.cols[c]
+	if cv.layouts == nil || math.IsNaN(cv.f[n]) {
+		return time.Time{}, false
+	}
+	return time.Unix(0, cv.d[n]).UTC(), true
+}
+
+// parseTime parses s using the first of layouts that accepts it, returning
+// the time in nanoseconds and seconds since the Unix epoch. An empty string
+// is read as NaN.
+func parseTime(s string, layouts []string) (int64, float64, error) {
+	if s == "" {
+		return 0, math.NaN(), nil
+	}
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if t.Before(minTime) || t.After(maxTime) {
+			return 0, 0, fmt.Errorf("time out of range: %q", s)
+		}
+		return t.UnixNano(), fromUnits(t.UnixNano(), 9), nil
+	}
+	return 0, 0, fmt.Errorf("invalid time: %q", s)
+}
+
+// timeValue returns the value of the named column as a time. Timestamps are
+// stored in time columns or in numeric columns as seconds since the Unix epoch
+// and are interpreted in UTC.
+func timeValue(row RowRef, name string) (time.Time, bool) {
+	if t, ok := row.TimeValue(name); ok {
+		return t, true
+	}
+	v, exists := row.FloatValue(name)
+	if !exists || math.IsNaN(v) || math.IsInf(v, 0) {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+}
+
+// unixSeconds converts t to seconds since the Unix epoch.
+func unixSeconds(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
+
+// DaysBetween returns a Calculator that computes the number of days from the
+// timestamp in column a to the timestamp in column b. The result is fractional
+// and negative if b is earlier than a. Timestamps are numeric columns holding
+// seconds since the Unix epoch. NaN is returned if either value is missing.
+func DaysBetween(a, b string) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 {
+		ta, ok := timeValue(row, a)
+		if !ok {
+			return math.NaN()
+		}
+		tb, ok := timeValue(row, b)
+		if !ok {
+			return math.NaN()
+		}
+		return tb.Sub(ta).Hours() / 24
+	}), callExpr("DaysBetween", a, b), a, b)
+}
+
+// AddDuration returns a Calculator that adds d to the timestamp in the named
+// column, returning the result as seconds since the Unix epoch. NaN is returned
+// if the value is missing.
+func AddDuration(col string, d time.Duration) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 {
+		t, ok := timeValue(row, col)
+		if !ok {
+			return math.NaN()
+		}
+		return unixSeconds(t.Add(d))
+	}), callExpr("AddDuration", col, d), col)
+}
+
+// TruncateTo returns a Calculator that rounds the timestamp in the named column
+// down to the start of its unit, returning the result as seconds since the Unix
+// epoch. Calendar units are evaluated in UTC. NaN is returned if the value is
+// missing.
+func TruncateTo(col string, unit TimeUnit) Calculator {
+	return describeCalculator(CalculatorFunc(func(row RowRef) float64 {
+		t, ok := timeValue(row, col)
+		if !ok {
+			return math.NaN()
+		}
+		return unixSeconds(truncateTime(t, unit))
+	}), callExpr("TruncateTo", col, unit), col)
+}
+
+func truncateTime(t time.Time, unit TimeUnit) time.Time {
+	switch unit {
+	case Second:
+		return t.Truncate(time.Second)
+	case Minute:
+		return t.Truncate(time.Minute)
+	case Hour:
+		return t.Truncate(time.Hour)
+	case Day:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case Year:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
@@ -0,0 +1,158 @@
+package datatable
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateCalculators(t *testing.T) {
+	start := time.Date(2023, time.April, 12, 15, 30, 45, 0, time.UTC) // a Wednesday
+	end := time.Date(2023, time.April, 14, 3, 30, 45, 0, time.UTC)
+
+	dt := &DataTable{}
+	dt.AddColumn("start", []float64{unixSeconds(start), math.NaN()})
+	dt.AddColumn("end", []float64{unixSeconds(end), unixSeconds(end)})
+
+	testCases := []struct {
+		calc     Calculator
+		expected []float64
+	}{
+		{
+			calc:     DaysBetween("start", "end"),
+			expected: []float64{1.5, math.NaN()},
+		},
+		{
+			calc:     DaysBetween("end", "start"),
+			expected: []float64{-1.5, math.NaN()},
+		},
+		{
+			calc:     AddDuration("start", 36*time.Hour),
+			expected: []float64{unixSeconds(end), math.NaN()},
+		},
+		{
+			calc:     TruncateTo("start", Hour),
+			expected: []float64{unixSeconds(time.Date(2023, time.April, 12, 15, 0, 0, 0, time.UTC)), math.NaN()},
+		},
+		{
+			calc:     TruncateTo("start", Day),
+			expected: []float64{unixSeconds(time.Date(2023, time.April, 12, 0, 0, 0, 0, time.UTC)), math.NaN()},
+		},
+		{
+			calc:     TruncateTo("start", Week),
+			expected: []float64{unixSeconds(time.Date(2023, time.April, 10, 0, 0, 0, 0, time.UTC)), math.NaN()},
+		},
+		{
+			calc:     TruncateTo("start", Month),
+			expected: []float64{unixSeconds(time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)), math.NaN()},
+		},
+		{
+			calc:     TruncateTo("start", Year),
+			expected: []float64{unixSeconds(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)), math.NaN()},
+		},
+	}
+
+	for i, tc := range testCases {
+		col := fillNaN(dt.Len())
+		dt.CalcIndexFill(col, tc.calc, fillSeq(dt.Len()))
+		if !equivalentFloatSlices(col, tc.expected) {
+			t.Errorf("%d: got %v, wanted %v", i, col, tc.expected)
+		}
+	}
+}
+
+func TestTimeColumn(t *testing.T) {
+	t1 := time.Date(2023, time.April, 12, 15, 30, 45, 1, time.UTC)
+	t2 := time.Date(2023, time.April, 12, 15, 30, 45, 2, time.UTC) // same float seconds as t1
+
+	dt := &DataTable{}
+	dt.AddStringColumn("k", []string{"a", "b"})
+	if err := dt.AddTimeColumn("at", []time.Time{t2, t1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.SetTimeLayouts("at", "2006-01-02", time.RFC3339Nano); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.ParseRow("c", "2023-04-10"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := parseTime("yesterday", []string{"2006-01-02"}); err == nil {
+		t.Errorf("got no error for invalid time")
+	}
+
+	if typ, _ := dt.ColumnType("at"); typ != ColTime {
+		t.Errorf("got type %v, wanted %v", typ, ColTime)
+	}
+
+	dt.SetKeys("at")
+	var got []time.Time
+	for i := 0; i < dt.Len(); i++ {
+		rr, _ := dt.RowRef(i)
+		v, ok := rr.TimeValue("at")
+		if !ok {
+			t.Fatalf("row %d: got no time value", i)
+		}
+		got = append(got, v)
+	}
+	if expected := []time.Time{time.Date(2023, time.April, 10, 0, 0, 0, 0, time.UTC), t1, t2}; !timesEqual(got, expected) {
+		t.Errorf("got %v, wanted %v", got, expected)
+	}
+
+	buf := new(bytes.Buffer)
+	dt.CSV(buf)
+	if expected := "k,at\nc,2023-04-10\nb,2023-04-12\na,2023-04-12\n"; buf.String() != expected {
+		t.Errorf("got %q, wanted %q", buf.String(), expected)
+	}
+
+	rr, _ := dt.RowRef(0)
+	if v := DaysBetween("at", "at").Calculate(rr); v != 0 {
+		t.Errorf("got %v, wanted 0", v)
+	}
+
+	if err := dt.SetTimeLayouts("k", "2006"); !errors.Is(err, ErrMismatchedColumnTypes) {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+	if err := dt.AddTimeColumn("old", []time.Time{{}, {}, time.Date(1500, 1, 1, 0, 0, 0, 0, time.UTC)}); err == nil {
+		t.Errorf("got no error for time out of range")
+	}
+}
+
+func TestReadCSVTimeLayouts(t *testing.T) {
+	input := "id,when\n1,12/04/2023 15:30\n2,\n"
+	dt, err := ReadCSV(strings.NewReader(input), CSVOptions{
+		TimeLayouts: map[string][]string{"when": {"02/01/2006 15:04"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rr, _ := dt.RowRef(0)
+	if v, ok := rr.TimeValue("when"); !ok || !v.Equal(time.Date(2023, time.April, 12, 15, 30, 0, 0, time.UTC)) {
+		t.Errorf("got %v %v, wanted 2023-04-12 15:30", v, ok)
+	}
+	rr, _ = dt.RowRef(1)
+	if _, ok := rr.TimeValue("when"); ok {
+		t.Errorf("got time value for empty field")
+	}
+
+	_, err = ReadCSV(strings.NewReader("when\nsoon\n"), CSVOptions{
+		TimeLayouts: map[string][]string{"when": {"2006"}},
+	})
+	if err == nil {
+		t.Errorf("got no error for invalid time")
+	}
+}
+
+func timesEqual(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
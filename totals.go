@@ -0,0 +1,117 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+)
+
+// TotalsOptions controls the rows added by WithTotals.
+type TotalsOptions struct {
+	// Subtotals adds a subtotal row after the rows sharing each value of
+	// every key other than the last, such as a total for each region of a
+	// summary keyed by region and product.
+	Subtotals bool
+
+	// Label is written in the first string key column of each total row
+	// that does not hold a key value of the group it totals. The default
+	// is "Total".
+	Label string
+}
+
+// WithTotals returns a copy of the summary table, such as the result of
+// Crosstab or MergeSummaries, with a grand total row appended and, if
+// requested, subtotal rows for each higher level key, laid out as spreadsheet
+// reports are. Each total row holds the sum of the values of each numeric
+// column other than the keys, ignoring NaN and null values. Key columns hold
+// the values of the group totalled, the label and then nulls, and string
+// columns other than the keys are null. The table must have keys set. The
+// result is in report order and so has no keys set.
+func (dt *DataTable) WithTotals(opts TotalsOptions) (*DataTable, error) {
+	names := dt.KeyNames()
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no key columns set")
+	}
+	label := opts.Label
+	if label == "" {
+		label = "Total"
+	}
+	keys := make([]int, len(names))
+	for i, name := range names {
+		keys[i] = dt.colorder[name]
+	}
+
+	// Each row of the result is a row of the table or a total over rows
+	// start to end whose first level key values are copied from start.
+	type entry struct {
+		row, start, end, level int
+	}
+	var entries []entry
+	starts := make([]int, len(keys))
+	for n := 0; n <= dt.Len(); n++ {
+		if opts.Subtotals && n > 0 {
+			for level := len(keys) - 1; level > 0; level-- {
+				if n == dt.Len() || dt.compareRows(keys[:level], starts[level], n) != 0 {
+					entries = append(entries, entry{row: -1, start: starts[level], end: n, level: level})
+					starts[level] = n
+				}
+			}
+		}
+		if n < dt.Len() {
+			entries = append(entries, entry{row: n})
+		}
+	}
+	entries = append(entries, entry{row: -1, start: 0, end: dt.Len()})
+
+	ret := &DataTable{}
+	for c, name := range dt.colnames {
+		level := -1
+		for i, k := range keys {
+			if k == c {
+				level = i
+			}
+		}
+		indices := make([]int, len(entries))
+		for i, e := range entries {
+			indices[i] = e.row
+			if e.row == -1 && level >= 0 && level < e.level {
+				indices[i] = e.start
+			}
+		}
+		cv := dt.cols[c].pickPad(indices)
+		if level < 0 && cv.f != nil {
+			for i, e := range entries {
+				if e.row == -1 {
+					cv.setFloat(i, dt.sumRange(c, e.start, e.end))
+				}
+			}
+		}
+		ret.addColumn(name, cv)
+	}
+
+	for i, e := range entries {
+		if e.row != -1 {
+			continue
+		}
+		for _, c := range keys[e.level:] {
+			if !dt.isFloatCol(c) {
+				ret.cols[c].s[i] = label
+				ret.cols[c].setNull(i, false)
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+// sumRange returns the sum of the values of numeric column c in rows start to
+// end, ignoring NaN and null values.
+func (dt *DataTable) sumRange(c, start, end int) float64 {
+	cv := dt.cols[c]
+	total := 0.0
+	for n := start; n < end; n++ {
+		if !math.IsNaN(cv.f[n]) && !cv.isNull(n) {
+			total += cv.f[n]
+		}
+	}
+	return total
+}
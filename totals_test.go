@@ -0,0 +1,60 @@
+package datatable
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWithTotals(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("region", []string{"north", "south", "north", "south", "north"})
+	dt.AddStringColumn("product", []string{"a", "a", "b", "b", "b"})
+	dt.AddColumn("year", []float64{2020, 2020, 2020, 2021, 2021})
+	dt.AddColumn("sales", []float64{10, 20, 30, 5, 7})
+	dt.SetNull("sales", 4)
+	if err := dt.SetKeys("region", "product", "year"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := dt.WithTotals(TotalsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Len() != 6 {
+		t.Fatalf("got %d rows, wanted 6", got.Len())
+	}
+	if row, _ := got.RowRef(5); !equivalentRows([][]interface{}{{"Total", "", math.NaN(), 65.0}}, [][]interface{}{got.row(5)}) || !row.IsNull("product") || !row.IsNull("year") {
+		t.Errorf("got grand total %v", got.row(5))
+	}
+	if len(got.KeyNames()) != 0 {
+		t.Errorf("got keys %v, wanted none", got.KeyNames())
+	}
+
+	got, err = dt.WithTotals(TotalsOptions{Subtotals: true, Label: "All"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantRegions := []string{"north", "north", "north", "north", "north", "north", "south", "south", "south", "south", "south", "All"}
+	wantProducts := []string{"a", "a", "b", "b", "b", "All", "a", "a", "b", "b", "All", ""}
+	wantSales := []float64{10, 10, 30, 0, 30, 40, 20, 20, 5, 5, 25, 65}
+	if regions := got.cols[got.colorder["region"]].s; !reflect.DeepEqual(regions, wantRegions) {
+		t.Errorf("got regions %v, wanted %v", regions, wantRegions)
+	}
+	if products := got.cols[got.colorder["product"]].s; !reflect.DeepEqual(products, wantProducts) {
+		t.Errorf("got products %v, wanted %v", products, wantProducts)
+	}
+	if sales := got.cols[got.colorder["sales"]].f; !equivalentFloatSlices(sales[:3], wantSales[:3]) || !equivalentFloatSlices(sales[4:], wantSales[4:]) {
+		t.Errorf("got sales %v, wanted %v", sales, wantSales)
+	}
+	if !got.cols[got.colorder["sales"]].isNull(3) {
+		t.Errorf("got non-null sales in row 3, wanted the original null")
+	}
+	if row, _ := got.RowRef(1); !row.IsNull("year") {
+		t.Errorf("got year %v in subtotal, wanted null", got.row(1))
+	}
+
+	if _, err := (&DataTable{}).WithTotals(TotalsOptions{}); err == nil {
+		t.Errorf("got no error for table without keys")
+	}
+}
@@ -0,0 +1,198 @@
+package datatable
+
+import (
+	"math"
+	"sort"
+)
+
+// undoLog holds the inverse of recent destructive operations, most recent last.
+type undoLog struct {
+	depth int
+	ops   []func()
+}
+
+// EnableUndo starts recording destructive operations (RemoveRows and
+// RemoveColumn) so they can be reverted with Undo. At most depth operations
+// are retained, the oldest being discarded first. A depth of zero or less
+// disables undo and discards any recorded operations.
+func (dt *DataTable) EnableUndo(depth int) {
+	if depth <= 0 {
+		dt.undo = nil
+		return
+	}
+	if dt.undo == nil {
+		dt.undo = &undoLog{}
+	}
+	dt.undo.depth = depth
+	dt.undo.trim()
+}
+
+// Undo reverts the most recently recorded destructive operation and reports
+// whether there was an operation to revert. Removed rows are reinserted at the
+// positions they occupied, with their row identifiers if enabled, and the table
+// is re-sorted if it has keys. Removed columns are reinserted at their original
+// position and reinstated as keys if they were keys when removed.
+func (dt *DataTable) Undo() bool {
+	if dt.undo == nil || len(dt.undo.ops) == 0 {
+		return false
+	}
+	last := len(dt.undo.ops) - 1
+	op := dt.undo.ops[last]
+	dt.undo.ops[last] = nil
+	dt.undo.ops = dt.undo.ops[:last]
+	op()
+	dt.markAllDirty()
+	dt.mutated()
+	return true
+}
+
+func (u *undoLog) record(op func()) {
+	u.ops = append(u.ops, op)
+	u.trim()
+}
+
+func (u *undoLog) trim() {
+	if excess := len(u.ops) - u.depth; excess > 0 {
+		u.ops = append(u.ops[:0], u.ops[excess:]...)
+	}
+}
+
+// recordRemoveRows records the inverse of removing the rows in indices, which
+// must be in ascending order.
+func (dt *DataTable) recordRemoveRows(indices []int) {
+	if dt.undo == nil {
+		return
+	}
+	removed, _ := dt.SelectIndex(dt.Names(), indices)
+	positions := make([]int, len(indices))
+	copy(positions, indices)
+
+	dt.undo.record(func() {
+		dt.insertRows(positions, removed)
+		if len(dt.keys) > 0 {
+			sort.Stable(dt)
+		}
+	})
+}
+
+// insertRows inserts the rows of src so that they occupy the given positions,
+// which must be in ascending order. Columns missing from src are padded with
+// null values.
+func (dt *DataTable) insertRows(positions []int, src *DataTable) {
+	for c := range dt.cols {
+		c2, exists := src.colorder[dt.colnames[c]]
+		if exists && dt.isFloatCol(c) != src.isFloatCol(c2) {
+			exists = false
+		}
+
+		if cv := &dt.cols[c]; cv.null != nil || !exists || src.cols[c2].null != nil {
+			null := make([]bool, cv.Len())
+			copy(null, cv.null)
+			cv.null = insertAt(null, positions, func(p int) bool {
+				return !exists || src.cols[c2].isNull(p)
+			})
+		}
+
+		if dt.isFloatCol(c) {
+			dt.cols[c].f = insertAt(dt.cols[c].f, positions, func(p int) float64 {
+				if !exists {
+					return math.NaN()
+				}
+				return src.cols[c2].f[p]
+			})
+			if dt.cols[c].d != nil {
+				dt.cols[c].d = insertAt(dt.cols[c].d, positions, func(p int) int64 {
+					if !exists || src.cols[c2].d == nil {
+						return 0
+					}
+					return src.cols[c2].d[p]
+				})
+			}
+		} else {
+			dt.cols[c].s = insertAt(dt.cols[c].s, positions, func(p int) string {
+				if !exists {
+					return ""
+				}
+				return src.cols[c2].s[p]
+			})
+		}
+	}
+
+	if dt.ids != nil && src.ids != nil {
+		dt.ids = insertAt(dt.ids, positions, func(p int) int64 { return src.ids[p] })
+	}
+}
+
+// insertAt returns a new slice containing the values of old with additional
+// values inserted at positions, which must be in ascending order. The value
+// for the pth position is obtained by calling value(p). Positions beyond the
+// end of the slice are appended.
+func insertAt[T any](old []T, positions []int, value func(p int) T) []T {
+	n := len(old) + len(positions)
+	ret := make([]T, 0, n)
+	p := 0
+	for i := 0; i < n; i++ {
+		if p < len(positions) && (positions[p] == i || len(old) == 0) {
+			ret = append(ret, value(p))
+			p++
+			continue
+		}
+		ret = append(ret, old[0])
+		old = old[1:]
+	}
+	return ret
+}
+
+// recordRemoveColumn records the inverse of removing the column at position c.
+func (dt *DataTable) recordRemoveColumn(c int) {
+	if dt.undo == nil {
+		return
+	}
+	name := dt.colnames[c]
+	cv := dt.cols[c]
+	keys := dt.KeyNames()
+	wasKey := false
+	for _, k := range dt.keys {
+		if k == c {
+			wasKey = true
+		}
+	}
+
+	dt.undo.record(func() {
+		if _, exists := dt.colorder[name]; exists || cv.Len() != dt.Len() && dt.N() != 0 {
+			// The column has been replaced or the table has changed shape
+			return
+		}
+		if c > len(dt.cols) {
+			c = len(dt.cols)
+		}
+		dt.cols = append(dt.cols, colvals{})
+		copy(dt.cols[c+1:], dt.cols[c:])
+		dt.cols[c] = cv
+		dt.colnames = append(dt.colnames, "")
+		copy(dt.colnames[c+1:], dt.colnames[c:])
+		dt.colnames[c] = name
+		if dt.colorder == nil {
+			dt.colorder = map[string]int{}
+		}
+		for i := c; i < len(dt.colnames); i++ {
+			dt.colorder[dt.colnames[i]] = i
+		}
+		for i := range dt.keys {
+			if dt.keys[i] >= c {
+				dt.keys[i]++
+			}
+		}
+
+		if !wasKey {
+			return
+		}
+		dt.keys = dt.keys[:0]
+		for _, k := range keys {
+			if kc, exists := dt.colorder[k]; exists {
+				dt.keys = append(dt.keys, kc)
+			}
+		}
+		sort.Stable(dt)
+	})
+}
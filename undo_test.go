@@ -0,0 +1,88 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUndoRemoveRows(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{1, 2, 3, 4, 5})
+	dt.AddStringColumn("c1", []string{"a", "b", "c", "d", "e"})
+	dt.EnableRowIDs()
+	dt.EnableUndo(2)
+
+	expected := dt.RawRows(false)
+
+	dt.RemoveRows(GreaterThan("c0", 3))
+	dt.RemoveRows(LessThan("c0", 2))
+	if dt.Len() != 2 {
+		t.Fatalf("got %d rows, wanted 2", dt.Len())
+	}
+
+	if !dt.Undo() {
+		t.Fatalf("first undo: got false, wanted true")
+	}
+	if !dt.Undo() {
+		t.Fatalf("second undo: got false, wanted true")
+	}
+	if dt.Undo() {
+		t.Errorf("third undo: got true, wanted false")
+	}
+
+	rows := dt.RawRows(false)
+	if !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+	if got, expectedIDs := rowIDs(dt), []int64{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, expectedIDs) {
+		t.Errorf("got ids %v, wanted %v", got, expectedIDs)
+	}
+}
+
+func TestUndoDepth(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{1, 2, 3})
+	dt.EnableUndo(1)
+
+	dt.RemoveRows(IsZero("c0"))             // nothing removed, not recorded
+	dt.RemoveRows(CloselyEqual("c0", 1, 0)) // discarded when the next is recorded
+	dt.RemoveRows(CloselyEqual("c0", 2, 0))
+
+	if !dt.Undo() {
+		t.Fatalf("first undo: got false, wanted true")
+	}
+	if dt.Undo() {
+		t.Errorf("second undo: got true, wanted false")
+	}
+	expected := [][]interface{}{{2.0}, {3.0}}
+	if rows := dt.RawRows(false); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+}
+
+func TestUndoRemoveColumn(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("c0", []float64{2, 1, 2})
+	dt.AddColumn("c1", []float64{3, 2, 1})
+	dt.AddStringColumn("c2", []string{"a", "b", "c"})
+	dt.SetKeys("c0", "c1")
+	dt.EnableUndo(5)
+
+	expected := dt.RawRows(true)
+
+	dt.RemoveColumn("c0")
+	dt.RemoveColumn("c2")
+	if !reflect.DeepEqual(dt.Names(), []string{"c1"}) {
+		t.Fatalf("got %v, wanted [c1]", dt.Names())
+	}
+
+	dt.Undo()
+	dt.Undo()
+
+	if rows := dt.RawRows(true); !equivalentRows(rows, expected) {
+		t.Errorf("got %+v, wanted %+v", rows, expected)
+	}
+	if keys := dt.KeyNames(); !reflect.DeepEqual(keys, []string{"c0", "c1"}) {
+		t.Errorf("got keys %v, wanted [c0 c1]", keys)
+	}
+}
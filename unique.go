@@ -0,0 +1,96 @@
+package datatable
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// NUnique returns the exact number of distinct combinations of values of the
+// named columns, or of all columns if none are named, without building a copy
+// of the table.
+func (dt *DataTable) NUnique(names ...string) (int, error) {
+	cols, err := dt.uniqueColumns(names)
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]struct{})
+	for i := 0; i < dt.Len(); i++ {
+		seen[dt.rowKey(cols, i)] = struct{}{}
+	}
+	return len(seen), nil
+}
+
+// hllPrecision is the number of hash bits used to choose a register in
+// ApproxNUnique, giving 2^hllPrecision registers and a typical relative error
+// of about 0.8%.
+const hllPrecision = 14
+
+// ApproxNUnique estimates the number of distinct combinations of values of
+// the named columns, or of all columns if none are named, using the
+// HyperLogLog algorithm. It uses a small fixed amount of memory regardless of
+// the number of rows and typically errs by less than 1%.
+func (dt *DataTable) ApproxNUnique(names ...string) (int, error) {
+	cols, err := dt.uniqueColumns(names)
+	if err != nil {
+		return 0, err
+	}
+
+	const m = 1 << hllPrecision
+	var registers [m]uint8
+	h := fnv.New64a()
+	for i := 0; i < dt.Len(); i++ {
+		h.Reset()
+		h.Write([]byte(dt.rowKey(cols, i)))
+		x := mix64(h.Sum64())
+		idx := x >> (64 - hllPrecision)
+		rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+		if rank > registers[idx] {
+			registers[idx] = rank
+		}
+	}
+
+	sum, zeros := 0.0, 0
+	for _, r := range registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	est := alpha * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		// Use linear counting for small cardinalities
+		est = m * math.Log(float64(m)/float64(zeros))
+	}
+	return int(math.Round(est)), nil
+}
+
+// uniqueColumns looks up the positions of the named columns, or returns all
+// columns if none are named.
+func (dt *DataTable) uniqueColumns(names []string) ([]int, error) {
+	if len(names) == 0 {
+		return fillSeq(dt.N()), nil
+	}
+	cols := make([]int, len(names))
+	for i, name := range names {
+		c, exists := dt.colorder[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		cols[i] = c
+	}
+	return cols, nil
+}
+
+// mix64 scrambles the bits of x so that similar inputs produce unrelated
+// outputs.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
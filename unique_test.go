@@ -0,0 +1,58 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNUnique(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("a", []string{"x", "x", "y", "y", "x"})
+	dt.AddColumn("b", []float64{1, 2, 1, 1, 1})
+
+	testCases := []struct {
+		names    []string
+		expected int
+	}{
+		{nil, 3},
+		{[]string{"a"}, 2},
+		{[]string{"b"}, 2},
+		{[]string{"a", "b"}, 3},
+	}
+	for _, tc := range testCases {
+		got, err := dt.NUnique(tc.names...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.expected {
+			t.Errorf("NUnique(%v): got %d, wanted %d", tc.names, got, tc.expected)
+		}
+		approx, _ := dt.ApproxNUnique(tc.names...)
+		if approx != tc.expected {
+			t.Errorf("ApproxNUnique(%v): got %d, wanted %d", tc.names, approx, tc.expected)
+		}
+	}
+
+	if _, err := dt.NUnique("nope"); err == nil {
+		t.Errorf("got no error for unknown column")
+	}
+}
+
+func TestApproxNUniqueLarge(t *testing.T) {
+	const n = 100000
+	values := make([]string, n)
+	for i := range values {
+		values[i] = fmt.Sprintf("v%d", i%50000)
+	}
+	dt := &DataTable{}
+	dt.AddStringColumn("v", values)
+
+	got, err := dt.ApproxNUnique("v")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if relErr := math.Abs(float64(got)-50000) / 50000; relErr > 0.03 {
+		t.Errorf("got %d, wanted within 3%% of 50000", got)
+	}
+}
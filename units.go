@@ -0,0 +1,158 @@
+package datatable
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A unitConversion converts a value v in one unit to v*factor + offset in
+// another.
+type unitConversion struct {
+	factor, offset float64
+}
+
+var (
+	unitsMu     sync.RWMutex
+	conversions = map[string]map[string]unitConversion{}
+)
+
+func init() {
+	for _, c := range []struct {
+		from, to       string
+		factor, offset float64
+	}{
+		{"km", "m", 1000, 0},
+		{"cm", "m", 0.01, 0},
+		{"mm", "m", 0.001, 0},
+		{"mi", "m", 1609.344, 0},
+		{"yd", "m", 0.9144, 0},
+		{"ft", "m", 0.3048, 0},
+		{"in", "m", 0.0254, 0},
+		{"ns", "s", 1e-9, 0},
+		{"us", "s", 1e-6, 0},
+		{"ms", "s", 0.001, 0},
+		{"min", "s", 60, 0},
+		{"h", "s", 3600, 0},
+		{"d", "s", 86400, 0},
+		{"g", "kg", 0.001, 0},
+		{"t", "kg", 1000, 0},
+		{"lb", "kg", 0.45359237, 0},
+		{"oz", "kg", 0.028349523125, 0},
+		{"C", "K", 1, 273.15},
+		{"F", "C", 5.0 / 9, -160.0 / 9},
+	} {
+		RegisterUnitConversion(c.from, c.to, c.factor, c.offset)
+	}
+}
+
+// RegisterUnitConversion registers the conversion of values in the unit from
+// to the unit to, as v*factor + offset, and the inverse conversion, replacing
+// any existing conversions between the units. Conversions between common
+// units of length (m, km, cm, mm, mi, yd, ft, in), time (s, ns, us, ms, min,
+// h, d), mass (kg, g, t, lb, oz) and temperature (C, F, K) are registered by
+// default. Conversions may be chained, so registering a conversion to any of
+// those units makes the new unit convertible to the others of its kind.
+func RegisterUnitConversion(from, to string, factor, offset float64) error {
+	if factor == 0 {
+		return fmt.Errorf("invalid conversion factor: %v", factor)
+	}
+	unitsMu.Lock()
+	defer unitsMu.Unlock()
+	if conversions[from] == nil {
+		conversions[from] = map[string]unitConversion{}
+	}
+	if conversions[to] == nil {
+		conversions[to] = map[string]unitConversion{}
+	}
+	conversions[from][to] = unitConversion{factor: factor, offset: offset}
+	conversions[to][from] = unitConversion{factor: 1 / factor, offset: -offset / factor}
+	return nil
+}
+
+// findConversion finds a chain of registered conversions from one unit to
+// another, preferring the chain with fewest steps, and combines them.
+func findConversion(from, to string) (unitConversion, bool) {
+	unitsMu.RLock()
+	defer unitsMu.RUnlock()
+	found := map[string]unitConversion{from: {factor: 1}}
+	queue := []string{from}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if u == to {
+			return found[u], true
+		}
+		for next, c := range conversions[u] {
+			if _, seen := found[next]; seen {
+				continue
+			}
+			prev := found[u]
+			found[next] = unitConversion{factor: prev.factor * c.factor, offset: prev.offset*c.factor + c.offset}
+			queue = append(queue, next)
+		}
+	}
+	return unitConversion{}, false
+}
+
+// SetColumnUnit records the unit of measurement of the values of the named
+// numeric column, such as "m" or "ms", without changing them. Use ConvertUnits
+// to change the unit and the values together.
+func (dt *DataTable) SetColumnUnit(name, unit string) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if !dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	if dt.colunits == nil {
+		dt.colunits = map[string]string{}
+	}
+	dt.colunits[name] = unit
+	return nil
+}
+
+// ColumnUnit returns the unit of measurement of the named column, or false if
+// none has been set.
+func (dt *DataTable) ColumnUnit(name string) (string, bool) {
+	unit, exists := dt.colunits[name]
+	return unit, exists
+}
+
+// ConvertUnits converts the values of the named numeric column from the unit
+// set with SetColumnUnit to toUnit using the registered conversions, and
+// records the new unit, so data from sources that use different units can be
+// brought to a common unit before being combined. NaN and null values are
+// unchanged. Integer, decimal and time columns become plain numeric columns,
+// since converted values are rarely whole numbers of their units. An error is
+// returned if the column has no unit or there is no conversion between the
+// units, in which case the column is not changed.
+func (dt *DataTable) ConvertUnits(name, toUnit string) error {
+	c, exists := dt.colorder[name]
+	if !exists {
+		return fmt.Errorf("unknown column: %s", name)
+	}
+	if !dt.isFloatCol(c) {
+		return ErrMismatchedColumnTypes
+	}
+	from, exists := dt.colunits[name]
+	if !exists {
+		return fmt.Errorf("no unit set for column: %s", name)
+	}
+	if from == toUnit {
+		return nil
+	}
+	conv, ok := findConversion(from, toUnit)
+	if !ok {
+		return fmt.Errorf("no conversion from %s to %s", from, toUnit)
+	}
+	cv := &dt.cols[c]
+	cv.d, cv.scale, cv.integer, cv.layouts = nil, 0, false, nil
+	if err := dt.MapColumns([]string{name}, func(v float64) float64 {
+		return v*conv.factor + conv.offset
+	}); err != nil {
+		return err
+	}
+	dt.colunits[name] = toUnit
+	return nil
+}
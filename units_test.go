@@ -0,0 +1,97 @@
+package datatable
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvertUnits(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddColumn("dist", []float64{1, 2.5, math.NaN()})
+	dt.AddColumn("temp", []float64{32, 212, 0})
+	dt.AddStringColumn("name", []string{"a", "b", "c"})
+	dt.SetNull("temp", 2)
+
+	if err := dt.ConvertUnits("dist", "m"); err == nil {
+		t.Errorf("got no error for column without a unit")
+	}
+	if err := dt.SetColumnUnit("name", "m"); err != ErrMismatchedColumnTypes {
+		t.Errorf("got %v, wanted %v", err, ErrMismatchedColumnTypes)
+	}
+
+	dt.SetColumnUnit("dist", "km")
+	dt.SetColumnUnit("temp", "F")
+
+	testCases := []struct {
+		name, unit string
+		want       []float64
+	}{
+		{"dist", "m", []float64{1000, 2500, math.NaN()}},
+		{"dist", "ft", []float64{1000 / 0.3048, 2500 / 0.3048, math.NaN()}},
+		{"dist", "km", []float64{1, 2.5, math.NaN()}},
+		{"temp", "C", []float64{0, 100, math.NaN()}},
+		{"temp", "K", []float64{273.15, 373.15, math.NaN()}},
+	}
+	for _, tc := range testCases {
+		if err := dt.ConvertUnits(tc.name, tc.unit); err != nil {
+			t.Fatalf("%s to %s: unexpected error: %v", tc.name, tc.unit, err)
+		}
+		got := dt.cols[dt.colorder[tc.name]].f
+		for i := range got {
+			if !(math.IsNaN(got[i]) && math.IsNaN(tc.want[i])) && math.Abs(got[i]-tc.want[i]) > 1e-9 {
+				t.Errorf("%s to %s: got %v, wanted %v", tc.name, tc.unit, got, tc.want)
+				break
+			}
+		}
+		if unit, _ := dt.ColumnUnit(tc.name); unit != tc.unit {
+			t.Errorf("%s to %s: got unit %q", tc.name, tc.unit, unit)
+		}
+	}
+	if !dt.cols[dt.colorder["temp"]].isNull(2) {
+		t.Errorf("conversion cleared a null value")
+	}
+
+	if err := dt.ConvertUnits("dist", "kg"); err == nil {
+		t.Errorf("got no error for incompatible units")
+	}
+
+	if err := RegisterUnitConversion("furlong", "yd", 220, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dt.ConvertUnits("dist", "furlong"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := dt.cols[dt.colorder["dist"]].f[0]; math.Abs(got-1000/(0.9144*220)) > 1e-9 {
+		t.Errorf("got %v furlongs", got)
+	}
+
+	dt.RemoveColumn("dist")
+	if _, ok := dt.ColumnUnit("dist"); ok {
+		t.Errorf("got unit for removed column")
+	}
+}
+
+func TestConvertUnitsExactColumns(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddIntColumn("elapsed", []int64{1500, 2500, 999})
+	dt.AddDecimalColumn("length", []int64{125, 5, 10}, 1)
+	dt.SetColumnUnit("elapsed", "ms")
+	dt.SetColumnUnit("length", "cm")
+
+	if err := dt.ConvertUnits("elapsed", "s"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := dt.cols[dt.colorder["elapsed"]].f; !equivalentFloatSlices(got, []float64{1.5, 2.5, 0.999}) {
+		t.Errorf("got %v, wanted [1.5 2.5 0.999]", got)
+	}
+	if typ, _ := dt.ColumnType("elapsed"); typ != ColNumeric {
+		t.Errorf("got column type %v, wanted %v", typ, ColNumeric)
+	}
+
+	if err := dt.ConvertUnits("length", "m"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := dt.cols[dt.colorder["length"]].f; !equivalentFloatSlices(got, []float64{0.125, 0.005, 0.01}) {
+		t.Errorf("got %v, wanted [0.125 0.005 0.01]", got)
+	}
+}
@@ -0,0 +1,242 @@
+package datatable
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// A TableView presents the data of a base table with columns renamed, hidden,
+// reordered or computed, without copying any data. The view reads the base
+// table each time it is used so it reflects later changes to the base table's
+// data and row order. Columns removed from the base table are omitted from the
+// view. Several views may share the same base table.
+type TableView struct {
+	base *DataTable
+	cols []viewCol
+}
+
+// viewCol is a column of a TableView.
+type viewCol struct {
+	name string
+	src  string     // name of the column in the base table, empty if computed
+	calc Calculator // calculation of a computed column
+}
+
+// NewView returns a view of dt that initially presents all of its columns
+// under their own names in the order they were added to the table.
+func NewView(dt *DataTable) *TableView {
+	v := &TableView{base: dt}
+	for _, name := range dt.colnames {
+		v.cols = append(v.cols, viewCol{name: name, src: name})
+	}
+	return v
+}
+
+// Rename changes the name under which the view presents a column.
+func (v *TableView) Rename(oldName, newName string) error {
+	i := v.index(oldName)
+	if i < 0 {
+		return fmt.Errorf("unknown column: %s", oldName)
+	}
+	if j := v.index(newName); j >= 0 && j != i {
+		return fmt.Errorf("duplicate column: %s", newName)
+	}
+	v.cols[i].name = newName
+	return nil
+}
+
+// Hide removes the named columns from the view. The base table is not
+// modified.
+func (v *TableView) Hide(names ...string) error {
+	hidden := make(map[int]bool, len(names))
+	for _, name := range names {
+		i := v.index(name)
+		if i < 0 {
+			return fmt.Errorf("unknown column: %s", name)
+		}
+		if hidden[i] {
+			return fmt.Errorf("duplicate column: %s", name)
+		}
+		hidden[i] = true
+	}
+	cols := make([]viewCol, 0, len(v.cols)-len(hidden))
+	for i, col := range v.cols {
+		if !hidden[i] {
+			cols = append(cols, col)
+		}
+	}
+	v.cols = cols
+	return nil
+}
+
+// Reorder moves the named columns to the start of the view in the order
+// given. The remaining columns follow in their existing order.
+func (v *TableView) Reorder(names ...string) error {
+	cols := make([]viewCol, 0, len(v.cols))
+	moved := make(map[int]bool, len(names))
+	for _, name := range names {
+		i := v.index(name)
+		if i < 0 {
+			return fmt.Errorf("unknown column: %s", name)
+		}
+		if moved[i] {
+			return fmt.Errorf("duplicate column: %s", name)
+		}
+		moved[i] = true
+		cols = append(cols, v.cols[i])
+	}
+	for i, col := range v.cols {
+		if !moved[i] {
+			cols = append(cols, col)
+		}
+	}
+	v.cols = cols
+	return nil
+}
+
+// Compute adds a numeric column to the end of the view whose values are
+// calculated by executing c against each row of the base table when they are
+// read. The calculator refers to columns by their names in the base table.
+func (v *TableView) Compute(name string, c Calculator) error {
+	if v.index(name) >= 0 {
+		return fmt.Errorf("duplicate column: %s", name)
+	}
+	v.cols = append(v.cols, viewCol{name: name, calc: c})
+	return nil
+}
+
+// Names returns the names of the columns presented by the view, in order.
+func (v *TableView) Names() []string {
+	var names []string
+	for _, col := range v.visible() {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+// Len returns the number of rows in the view, which is the number of rows in
+// the base table.
+func (v *TableView) Len() int {
+	return v.base.Len()
+}
+
+// Row returns the values of the row at position n of the base table, in the
+// order of the view's columns, or false if n is out of range.
+func (v *TableView) Row(n int) ([]interface{}, bool) {
+	if n < 0 || n >= v.base.Len() {
+		return nil, false
+	}
+	cols := v.visible()
+	row := make([]interface{}, len(cols))
+	for i, col := range cols {
+		row[i] = v.value(col, n)
+	}
+	return row, true
+}
+
+// RawRows returns a slice of rows containing the values of the view. If
+// headers is true then the first row will be the view's column names.
+func (v *TableView) RawRows(headers bool) [][]interface{} {
+	var ret [][]interface{}
+	if headers {
+		names := []interface{}{}
+		for _, name := range v.Names() {
+			names = append(names, name)
+		}
+		ret = append(ret, names)
+	}
+	for n := 0; n < v.base.Len(); n++ {
+		row, _ := v.Row(n)
+		ret = append(ret, row)
+	}
+	return ret
+}
+
+// CSV writes the view as CSV data to w, including a header row. Numeric
+// values of columns read from the base table are formatted according to the
+// base table's float formats.
+func (v *TableView) CSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(v.Names()); err != nil {
+		return fmt.Errorf("writing csv row: %v", err)
+	}
+	cols := v.visible()
+	for n := 0; n < v.base.Len(); n++ {
+		sw := make([]string, len(cols))
+		for i, col := range cols {
+			val := v.value(col, n)
+			f, ok := val.(float64)
+			switch {
+			case ok && col.calc == nil:
+				sw[i] = v.base.formatFloat(v.base.colorder[col.src], n, f)
+			case ok && v.base.floatFormat != nil:
+				sw[i] = v.base.floatFormat.Format(f)
+			default:
+				sw[i] = fmt.Sprintf("%v", val)
+			}
+		}
+		if err := cw.Write(sw); err != nil {
+			return fmt.Errorf("writing csv row: %v", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writing csv row: %v", err)
+	}
+	return nil
+}
+
+// Materialize returns a new data table holding copies of the view's columns,
+// with computed columns calculated, under the view's names and in the view's
+// order. The returned data table will have no keys set.
+func (v *TableView) Materialize() *DataTable {
+	dt := &DataTable{}
+	for _, col := range v.visible() {
+		if col.calc != nil {
+			vals := fillNaN(v.base.Len())
+			v.base.CalcIndexFill(vals, col.calc, fillSeq(v.base.Len()))
+			dt.addColumn(col.name, colvals{f: vals})
+			continue
+		}
+		dt.addColumn(col.name, v.base.cols[v.base.colorder[col.src]].clone())
+	}
+	return dt
+}
+
+// index returns the position of the named column in the view or -1.
+func (v *TableView) index(name string) int {
+	for i, col := range v.cols {
+		if col.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// visible returns the view's columns, omitting any whose base column has
+// been removed.
+func (v *TableView) visible() []viewCol {
+	cols := make([]viewCol, 0, len(v.cols))
+	for _, col := range v.cols {
+		if col.calc == nil {
+			if _, exists := v.base.colorder[col.src]; !exists {
+				continue
+			}
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// value returns the value of a column of the view at row n of the base table.
+func (v *TableView) value(col viewCol, n int) interface{} {
+	if col.calc != nil {
+		return col.calc.Calculate(RowRef{dt: v.base, index: n})
+	}
+	cv := v.base.cols[v.base.colorder[col.src]]
+	if cv.f != nil {
+		return cv.f[n]
+	}
+	return cv.s[n]
+}
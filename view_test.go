@@ -0,0 +1,78 @@
+package datatable
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTableView(t *testing.T) {
+	dt := &DataTable{}
+	dt.AddStringColumn("sku", []string{"a", "b"})
+	dt.AddColumn("price", []float64{2.5, 4})
+	dt.AddColumn("qty", []float64{2, 3})
+	dt.AddColumn("cost", []float64{1, 1})
+
+	v := NewView(dt)
+	if err := v.Hide("cost"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := v.Rename("sku", "Product"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := v.Compute("Revenue", Mul(Col("price"), Col("qty"))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := v.Reorder("Revenue", "Product"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]interface{}{
+		{"Revenue", "Product", "price", "qty"},
+		{5.0, "a", 2.5, 2.0},
+		{12.0, "b", 4.0, 3.0},
+	}
+	if got := v.RawRows(true); !equivalentRows(got, expected) {
+		t.Errorf("got %v, wanted %v", got, expected)
+	}
+
+	// The view reflects changes to the base table
+	dt.SetFloatValue("qty", 0, 10)
+	if row, _ := v.Row(0); !reflect.DeepEqual(row, []interface{}{25.0, "a", 2.5, 10.0}) {
+		t.Errorf("got row %v after modifying base table", row)
+	}
+	dt.RemoveColumn("price")
+	if names := v.Names(); !reflect.DeepEqual(names, []string{"Revenue", "Product", "qty"}) {
+		t.Errorf("got names %v after removing base column", names)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := v.CSV(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Revenue,Product,qty\nNaN,a,10\nNaN,b,3\n"; buf.String() != want {
+		t.Errorf("got csv %q, wanted %q", buf.String(), want)
+	}
+
+	m := v.Materialize()
+	if !equivalentRows(m.RawRows(true), v.RawRows(true)) {
+		t.Errorf("got materialized %v, wanted %v", m.RawRows(true), v.RawRows(true))
+	}
+
+	if err := v.Rename("Product", "qty"); err == nil {
+		t.Errorf("expected error renaming to an existing name")
+	}
+	if err := v.Hide("missing"); err == nil {
+		t.Errorf("expected error hiding unknown column")
+	}
+	names := v.Names()
+	if err := v.Hide("qty", "qty"); err == nil {
+		t.Errorf("expected error hiding a column twice")
+	}
+	if got := v.Names(); !reflect.DeepEqual(got, names) {
+		t.Errorf("got columns %v after failed hide, wanted %v", got, names)
+	}
+	if err := v.Compute("Product", Lit(1)); err == nil {
+		t.Errorf("expected error computing a duplicate column")
+	}
+}